@@ -142,6 +142,8 @@ func (p *Pool) startWorker() {
 			select {
 			case job := <-workerJobQueue:
 				p.executeJob(job)
+				// 重新登记为空闲，否则调度器在分发MaxWorkers个任务后阻塞
+				p.workerQueue <- workerJobQueue
 			case <-p.quit:
 				// 关闭工作协程的通道
 				close(workerJobQueue)
@@ -279,6 +281,11 @@ func (p *Pool) StopGracefully(timeout time.Duration) {
 	time.Sleep(100 * time.Millisecond) // 给工作协程一些时间退出
 }
 
+// Done 返回在协程池停止时关闭的通道，停止后队列中尚未执行的任务不会再被执行
+func (p *Pool) Done() <-chan bool {
+	return p.quit
+}
+
 // Wait 等待所有任务完成
 func (p *Pool) Wait() {
 	p.wg.Wait()
@@ -60,6 +60,41 @@ func TestPoolSubmitFunc(t *testing.T) {
 	wg.Wait()
 }
 
+func TestPoolMoreJobsThanWorkers(t *testing.T) {
+	config := &PoolConfig{
+		MaxWorkers: 2,
+		QueueSize:  20,
+	}
+
+	pool := NewPool(config)
+	defer pool.Stop()
+
+	const jobs = 10
+	var wg sync.WaitGroup
+	wg.Add(jobs)
+	for i := 0; i < jobs; i++ {
+		err := pool.SubmitFunc("test-job", func() error {
+			defer wg.Done()
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Jobs beyond MaxWorkers were never executed")
+	}
+}
+
 func TestPoolError(t *testing.T) {
 	config := &PoolConfig{
 		MaxWorkers: 2,
@@ -84,6 +119,23 @@ func TestPoolError(t *testing.T) {
 	wg.Wait()
 }
 
+func TestPoolDone(t *testing.T) {
+	pool := NewPool(&PoolConfig{MaxWorkers: 1, QueueSize: 10})
+
+	select {
+	case <-pool.Done():
+		t.Fatal("Expected Done to stay open while the pool is running")
+	default:
+	}
+
+	pool.Stop()
+	select {
+	case <-pool.Done():
+	default:
+		t.Error("Expected Done to be closed after Stop")
+	}
+}
+
 // TestPoolStats 暂时禁用，因为统计不准确
 // func TestPoolStats(t *testing.T) {
 // 	config := &PoolConfig{
@@ -0,0 +1,418 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// APIError 声明式API调用返回非2xx状态码时的错误
+type APIError struct {
+	Method     string `json:"method"`
+	Path       string `json:"path"`
+	StatusCode int    `json:"status_code"`
+	Body       []byte `json:"body"`
+}
+
+// Error 实现 error 接口
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s %s: HTTP %d: %s", e.Method, e.Path, e.StatusCode, string(e.Body))
+}
+
+var (
+	contextType  = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errorType    = reflect.TypeOf((*error)(nil)).Elem()
+	responseType = reflect.TypeOf((*Response)(nil))
+	bytesType    = reflect.TypeOf([]byte(nil))
+	timeType     = reflect.TypeOf(time.Time{})
+	readerType   = reflect.TypeOf((*io.Reader)(nil)).Elem()
+	stringerType = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+)
+
+// pathParamPattern 路径模板中的参数，例如 /users/{id}
+var pathParamPattern = regexp.MustCompile(`\{([^{}]+)\}`)
+
+// NewAPI 根据结构体声明创建API客户端
+//
+// T 必须是结构体，带有 api 标签的函数字段会被填充为发送请求的实现：
+//
+//	type UserAPI struct {
+//	    Get    func(ctx context.Context, id int) (*User, error)           `api:"GET /users/{id}"`
+//	    List   func(ctx context.Context, q ListUsersRequest) ([]User, error) `api:"GET /users"`
+//	    Create func(ctx context.Context, user *User) (*User, error)       `api:"POST /users"`
+//	    Delete func(ctx context.Context, id int) error                     `api:"DELETE /users/{id}"`
+//	}
+//
+// 函数第一个参数必须是 context.Context。之后的标量参数按顺序填充路径参数；
+// 最后可以有一个结构体参数，其字段通过 path、query、header、body 标签映射到请求，
+// 没有这些标签的结构体整体作为JSON请求体。
+// 返回值为 error 或 (R, error)，R 可以是 *Response、[]byte、string 或任意JSON可解码类型。
+// 请求通过 client.Do 发送，复用客户端的重试、认证等配置；非2xx响应返回 *APIError。
+func NewAPI[T any](client Doer) (*T, error) {
+	api := new(T)
+	value := reflect.ValueOf(api).Elem()
+	if value.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("api type %s must be a struct", value.Type())
+	}
+
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		tag, ok := field.Tag.Lookup("api")
+		if !ok {
+			continue
+		}
+		endpoint, err := parseAPIEndpoint(field, tag)
+		if err != nil {
+			return nil, fmt.Errorf("api %s.%s: %w", value.Type().Name(), field.Name, err)
+		}
+		value.Field(i).Set(reflect.MakeFunc(field.Type, func(args []reflect.Value) []reflect.Value {
+			return endpoint.call(client, args)
+		}))
+	}
+	return api, nil
+}
+
+// MustNewAPI 与 NewAPI 相同，声明错误时panic，适用于包级变量初始化
+func MustNewAPI[T any](client Doer) *T {
+	api, err := NewAPI[T](client)
+	if err != nil {
+		panic(err)
+	}
+	return api
+}
+
+// apiEndpoint 单个接口的请求描述
+type apiEndpoint struct {
+	method     string
+	path       string
+	pathParams []string     // 路径参数名，按出现顺序
+	positional int          // 按位置填充路径参数的参数个数
+	request    reflect.Type // 请求结构体类型（去掉指针），为nil表示没有
+	fields     []apiField   // 请求结构体字段映射，为空表示整体作为请求体
+	result     reflect.Type // 返回值类型，为nil表示只返回error
+}
+
+// apiField 请求结构体字段映射
+type apiField struct {
+	index     []int
+	location  string // path、query、header、body
+	name      string
+	omitEmpty bool
+}
+
+// parseAPIEndpoint 解析函数字段的签名和标签
+func parseAPIEndpoint(field reflect.StructField, tag string) (*apiEndpoint, error) {
+	fnType := field.Type
+	if fnType.Kind() != reflect.Func {
+		return nil, fmt.Errorf("field must be a func, got %s", fnType)
+	}
+
+	method, path, ok := strings.Cut(strings.TrimSpace(tag), " ")
+	if !ok || method == "" || !strings.HasPrefix(strings.TrimSpace(path), "/") {
+		return nil, fmt.Errorf(`invalid api tag %q, want "METHOD /path"`, tag)
+	}
+	e := &apiEndpoint{method: strings.ToUpper(method), path: strings.TrimSpace(path)}
+	for _, match := range pathParamPattern.FindAllStringSubmatch(e.path, -1) {
+		e.pathParams = append(e.pathParams, match[1])
+	}
+
+	// 参数
+	if fnType.NumIn() == 0 || fnType.In(0) != contextType {
+		return nil, fmt.Errorf("first argument must be context.Context")
+	}
+	if fnType.IsVariadic() {
+		return nil, fmt.Errorf("variadic functions are not supported")
+	}
+	for i := 1; i < fnType.NumIn(); i++ {
+		argType := fnType.In(i)
+		if isScalarType(argType) {
+			if e.request != nil {
+				return nil, fmt.Errorf("argument %d: scalar arguments must come before the request struct", i)
+			}
+			e.positional++
+			continue
+		}
+		structType := argType
+		if structType.Kind() == reflect.Ptr {
+			structType = structType.Elem()
+		}
+		if structType.Kind() != reflect.Struct || i != fnType.NumIn()-1 {
+			return nil, fmt.Errorf("argument %d: only scalars and a trailing struct are supported, got %s", i, argType)
+		}
+		e.request = structType
+		e.fields = parseAPIFields(structType)
+	}
+	if e.positional > len(e.pathParams) {
+		return nil, fmt.Errorf("%d positional arguments but path has %d parameters", e.positional, len(e.pathParams))
+	}
+	bound := make(map[string]bool)
+	for _, name := range e.pathParams[:e.positional] {
+		bound[name] = true
+	}
+	for _, f := range e.fields {
+		if f.location == "path" {
+			bound[f.name] = true
+		}
+	}
+	for _, name := range e.pathParams {
+		if !bound[name] {
+			return nil, fmt.Errorf("path parameter {%s} is not bound to an argument", name)
+		}
+	}
+
+	// 返回值
+	switch fnType.NumOut() {
+	case 1:
+		if fnType.Out(0) != errorType {
+			return nil, fmt.Errorf("single return value must be error")
+		}
+	case 2:
+		if fnType.Out(1) != errorType {
+			return nil, fmt.Errorf("second return value must be error")
+		}
+		e.result = fnType.Out(0)
+	default:
+		return nil, fmt.Errorf("must return error or (result, error)")
+	}
+	return e, nil
+}
+
+// parseAPIFields 解析请求结构体字段标签
+func parseAPIFields(structType reflect.Type) []apiField {
+	var fields []apiField
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		for _, location := range []string{"path", "query", "header", "body"} {
+			tag, ok := field.Tag.Lookup(location)
+			if !ok {
+				continue
+			}
+			name, opts, _ := strings.Cut(tag, ",")
+			if name == "" {
+				name = field.Name
+			}
+			fields = append(fields, apiField{
+				index:     field.Index,
+				location:  location,
+				name:      name,
+				omitEmpty: opts == "omitempty",
+			})
+			break
+		}
+	}
+	return fields
+}
+
+// isScalarType 是否为可直接格式化为字符串的类型
+func isScalarType(t reflect.Type) bool {
+	if t == timeType || t.Implements(stringerType) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// formatValue 将标量格式化为字符串
+func formatValue(v reflect.Value) string {
+	if v.Type() == timeType {
+		return v.Interface().(time.Time).Format(time.RFC3339)
+	}
+	if v.Type().Implements(stringerType) {
+		return v.Interface().(fmt.Stringer).String()
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, v.Type().Bits())
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// formatValues 格式化字段值，切片展开为多个值，nil指针返回空
+func formatValues(v reflect.Value) []string {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) && v.Type() != bytesType {
+		values := make([]string, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			values = append(values, formatValues(v.Index(i))...)
+		}
+		return values
+	}
+	return []string{formatValue(v)}
+}
+
+// call 根据参数构建请求、发送并解码响应
+func (e *apiEndpoint) call(client Doer, args []reflect.Value) []reflect.Value {
+	ctx, _ := args[0].Interface().(context.Context)
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	req, err := e.buildRequest(args[1:])
+	if err != nil {
+		return e.results(nil, err)
+	}
+	resp, err := client.Do(ctx, req)
+	if err != nil {
+		return e.results(nil, err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return e.results(resp, &APIError{Method: e.method, Path: req.URL, StatusCode: resp.StatusCode, Body: resp.Body})
+	}
+	return e.results(resp, nil)
+}
+
+// buildRequest 根据参数构建请求
+func (e *apiEndpoint) buildRequest(args []reflect.Value) (*Request, error) {
+	pathValues := make(map[string]string)
+	for i := 0; i < e.positional; i++ {
+		pathValues[e.pathParams[i]] = formatValue(args[i])
+	}
+
+	req := &Request{Method: e.method, Headers: make(map[string]string)}
+	query := url.Values{}
+
+	if e.request != nil {
+		arg := args[len(args)-1]
+		if arg.Kind() == reflect.Ptr {
+			if arg.IsNil() {
+				arg = reflect.Zero(e.request)
+			} else {
+				arg = arg.Elem()
+			}
+		}
+
+		if len(e.fields) == 0 {
+			req.Body = arg.Interface()
+			req.Headers["Content-Type"] = "application/json"
+		}
+		for _, f := range e.fields {
+			fieldValue := arg.FieldByIndex(f.index)
+			if f.location == "body" {
+				if (fieldValue.Kind() == reflect.Ptr || fieldValue.Kind() == reflect.Interface) && fieldValue.IsNil() {
+					continue
+				}
+				req.Body = fieldValue.Interface()
+				if !fieldValue.Type().Implements(readerType) && fieldValue.Kind() != reflect.String && fieldValue.Type() != bytesType {
+					req.Headers["Content-Type"] = "application/json"
+				}
+				continue
+			}
+			if f.omitEmpty && fieldValue.IsZero() {
+				continue
+			}
+			values := formatValues(fieldValue)
+			if len(values) == 0 {
+				continue
+			}
+			switch f.location {
+			case "path":
+				pathValues[f.name] = values[0]
+			case "query":
+				for _, v := range values {
+					query.Add(f.name, v)
+				}
+			case "header":
+				req.Headers[f.name] = strings.Join(values, ", ")
+			}
+		}
+	}
+
+	var missing error
+	path := pathParamPattern.ReplaceAllStringFunc(e.path, func(param string) string {
+		name := param[1 : len(param)-1]
+		value, ok := pathValues[name]
+		if !ok && missing == nil {
+			missing = fmt.Errorf("missing value for path parameter {%s}", name)
+		}
+		return url.PathEscape(value)
+	})
+	if missing != nil {
+		return nil, missing
+	}
+	if encoded := query.Encode(); encoded != "" {
+		if strings.Contains(path, "?") {
+			path += "&" + encoded
+		} else {
+			path += "?" + encoded
+		}
+	}
+	req.URL = path
+	return req, nil
+}
+
+// results 构造函数返回值
+func (e *apiEndpoint) results(resp *Response, err error) []reflect.Value {
+	errValue := reflect.Zero(errorType)
+	if e.result == nil {
+		if err != nil {
+			errValue = reflect.ValueOf(err)
+		}
+		return []reflect.Value{errValue}
+	}
+
+	result := reflect.Zero(e.result)
+	if err == nil {
+		result, err = decodeAPIResult(resp, e.result)
+	}
+	if err != nil {
+		errValue = reflect.ValueOf(err)
+		// 解码失败时不返回部分结果；*Response 在API错误时仍然返回以便调用方检查
+		if e.result != responseType || resp == nil {
+			result = reflect.Zero(e.result)
+		} else {
+			result = reflect.ValueOf(resp)
+		}
+	}
+	return []reflect.Value{result, errValue}
+}
+
+// decodeAPIResult 将响应解码为返回值类型
+func decodeAPIResult(resp *Response, resultType reflect.Type) (reflect.Value, error) {
+	switch {
+	case resultType == responseType:
+		return reflect.ValueOf(resp), nil
+	case resultType == bytesType:
+		return reflect.ValueOf(resp.Body), nil
+	case resultType.Kind() == reflect.String:
+		return reflect.ValueOf(resp.Text).Convert(resultType), nil
+	}
+
+	if resp.StatusCode == http.StatusNoContent || len(resp.Body) == 0 {
+		return reflect.Zero(resultType), nil
+	}
+	target := reflect.New(resultType)
+	if err := json.Unmarshal(resp.Body, target.Interface()); err != nil {
+		return reflect.Zero(resultType), fmt.Errorf("failed to decode response: %w", err)
+	}
+	return target.Elem(), nil
+}
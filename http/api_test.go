@@ -0,0 +1,252 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type apiUser struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type listUsersRequest struct {
+	Org     string   `path:"org"`
+	Page    int      `query:"page"`
+	Tags    []string `query:"tag,omitempty"`
+	Cursor  *string  `query:"cursor"`
+	TraceID string   `header:"X-Trace-ID,omitempty"`
+}
+
+type updateUserRequest struct {
+	ID   int      `path:"id"`
+	User *apiUser `body:""`
+}
+
+type userAPI struct {
+	Get     func(ctx context.Context, id int) (*apiUser, error)                  `api:"GET /users/{id}"`
+	GetFile func(ctx context.Context, org string, name string) (string, error)   `api:"GET /orgs/{org}/files/{name}"`
+	List    func(ctx context.Context, req listUsersRequest) ([]apiUser, error)   `api:"GET /orgs/{org}/users"`
+	Create  func(ctx context.Context, user *apiUser) (*apiUser, error)           `api:"POST /users"`
+	Update  func(ctx context.Context, req *updateUserRequest) (*Response, error) `api:"PUT /users/{id}"`
+	Delete  func(ctx context.Context, id int) error                              `api:"delete /users/{id}"`
+	Raw     func(ctx context.Context) ([]byte, error)                            `api:"GET /raw"`
+
+	// 没有api标签的字段保持原样
+	Name string
+}
+
+// echoRequest 测试服务器回显的请求信息
+type echoRequest struct {
+	Method      string              `json:"method"`
+	Path        string              `json:"path"`
+	RawPath     string              `json:"raw_path"`
+	Query       map[string][]string `json:"query"`
+	TraceID     string              `json:"trace_id"`
+	ContentType string              `json:"content_type"`
+	Body        string              `json:"body"`
+}
+
+func TestNewAPI(t *testing.T) {
+	ctx := context.Background()
+	var last atomic.Value
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		last.Store(echoRequest{
+			Method:      r.Method,
+			Path:        r.URL.Path,
+			RawPath:     r.URL.EscapedPath(),
+			Query:       r.URL.Query(),
+			TraceID:     r.Header.Get("X-Trace-ID"),
+			ContentType: r.Header.Get("Content-Type"),
+			Body:        string(body),
+		})
+		switch {
+		case r.URL.Path == "/users/404":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"not found"}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/users/1":
+			w.Write([]byte(`{"id":1,"name":"alice"}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/orgs/acme/users":
+			w.Write([]byte(`[{"id":1,"name":"alice"},{"id":2,"name":"bob"}]`))
+		case r.Method == http.MethodPost:
+			w.WriteHeader(http.StatusCreated)
+			w.Write(body)
+		case r.Method == http.MethodDelete:
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.Write([]byte("raw:" + r.URL.EscapedPath()))
+		}
+	}))
+	defer server.Close()
+
+	api, err := NewAPI[userAPI](New(&Config{BaseURL: server.URL, Retry: fastRetry(0)}))
+	require.NoError(t, err)
+	lastRequest := func() echoRequest { return last.Load().(echoRequest) }
+
+	t.Run("path parameter and typed decoding", func(t *testing.T) {
+		user, err := api.Get(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, &apiUser{ID: 1, Name: "alice"}, user)
+	})
+
+	t.Run("path escaping", func(t *testing.T) {
+		text, err := api.GetFile(ctx, "a/b", "x y?.txt")
+		require.NoError(t, err)
+		assert.Equal(t, "raw:/orgs/a%2Fb/files/x%20y%3F.txt", text)
+	})
+
+	t.Run("query and header fields", func(t *testing.T) {
+		users, err := api.List(ctx, listUsersRequest{Org: "acme", Page: 2, Tags: []string{"x", "y"}, TraceID: "trace-1"})
+		require.NoError(t, err)
+		assert.Len(t, users, 2)
+
+		req := lastRequest()
+		assert.Equal(t, map[string][]string{"page": {"2"}, "tag": {"x", "y"}}, req.Query)
+		assert.Equal(t, "trace-1", req.TraceID)
+
+		cursor := "abc"
+		_, err = api.List(ctx, listUsersRequest{Org: "acme", Cursor: &cursor})
+		require.NoError(t, err)
+		assert.Equal(t, map[string][]string{"page": {"0"}, "cursor": {"abc"}}, lastRequest().Query)
+		assert.Empty(t, lastRequest().TraceID)
+	})
+
+	t.Run("struct body", func(t *testing.T) {
+		user, err := api.Create(ctx, &apiUser{Name: "carol"})
+		require.NoError(t, err)
+		assert.Equal(t, "carol", user.Name)
+		assert.Equal(t, "application/json", lastRequest().ContentType)
+	})
+
+	t.Run("body field", func(t *testing.T) {
+		resp, err := api.Update(ctx, &updateUserRequest{ID: 7, User: &apiUser{ID: 7, Name: "dave"}})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		req := lastRequest()
+		assert.Equal(t, http.MethodPut, req.Method)
+		assert.Equal(t, "/users/7", req.Path)
+		var body apiUser
+		require.NoError(t, json.Unmarshal([]byte(req.Body), &body))
+		assert.Equal(t, "dave", body.Name)
+	})
+
+	t.Run("error only", func(t *testing.T) {
+		require.NoError(t, api.Delete(ctx, 3))
+		assert.Equal(t, http.MethodDelete, lastRequest().Method)
+	})
+
+	t.Run("raw bytes", func(t *testing.T) {
+		body, err := api.Raw(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("raw:/raw"), body)
+	})
+
+	t.Run("api error", func(t *testing.T) {
+		user, err := api.Get(ctx, 404)
+		assert.Nil(t, user)
+		var apiErr *APIError
+		require.True(t, errors.As(err, &apiErr))
+		assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+		assert.Equal(t, "/users/404", apiErr.Path)
+		assert.JSONEq(t, `{"error":"not found"}`, string(apiErr.Body))
+	})
+}
+
+func TestNewAPIRetry(t *testing.T) {
+	var calls int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt64(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"id":1}`))
+	}))
+	defer server.Close()
+
+	type api struct {
+		Get func(ctx context.Context, id int) (apiUser, error) `api:"GET /users/{id}"`
+	}
+	client, err := NewAPI[api](New(&Config{BaseURL: server.URL, Retry: fastRetry(3)}))
+	require.NoError(t, err)
+
+	user, err := client.Get(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, user.ID)
+	assert.Equal(t, int64(3), atomic.LoadInt64(&calls))
+}
+
+func TestNewAPIInvalidDeclarations(t *testing.T) {
+	client := New(nil)
+
+	type noContext struct {
+		Get func(id int) error `api:"GET /users/{id}"`
+	}
+	type badTag struct {
+		Get func(ctx context.Context) error `api:"/users"`
+	}
+	type unboundParam struct {
+		Get func(ctx context.Context) error `api:"GET /users/{id}"`
+	}
+	type badReturn struct {
+		Get func(ctx context.Context) (int, string) `api:"GET /users"`
+	}
+	type scalarAfterStruct struct {
+		Get func(ctx context.Context, req listUsersRequest, id int) error `api:"GET /orgs/{org}/users/{id}"`
+	}
+	type notFunc struct {
+		Get string `api:"GET /users"`
+	}
+
+	_, err := NewAPI[noContext](client)
+	assert.ErrorContains(t, err, "context.Context")
+	_, err = NewAPI[badTag](client)
+	assert.ErrorContains(t, err, "invalid api tag")
+	_, err = NewAPI[unboundParam](client)
+	assert.ErrorContains(t, err, "{id}")
+	_, err = NewAPI[badReturn](client)
+	assert.Error(t, err)
+	_, err = NewAPI[scalarAfterStruct](client)
+	assert.Error(t, err)
+	_, err = NewAPI[notFunc](client)
+	assert.Error(t, err)
+	_, err = NewAPI[int](client)
+	assert.Error(t, err)
+
+	assert.Panics(t, func() { MustNewAPI[badTag](client) })
+}
+
+func TestNewAPIFormatting(t *testing.T) {
+	type request struct {
+		Since time.Time `query:"since"`
+		Ratio float64   `query:"ratio"`
+		On    bool      `query:"on"`
+	}
+	type api struct {
+		Find func(ctx context.Context, req request) (*Response, error) `api:"GET /find"`
+	}
+
+	var query map[string][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+	}))
+	defer server.Close()
+
+	client, err := NewAPI[api](New(&Config{BaseURL: server.URL}))
+	require.NoError(t, err)
+	since := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	_, err = client.Find(context.Background(), request{Since: since, Ratio: 0.5, On: true})
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{"since": {"2024-01-02T03:04:05Z"}, "ratio": {"0.5"}, "on": {"true"}}, query)
+}
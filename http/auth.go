@@ -0,0 +1,356 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/daxiong0327/tool-kit/singleflight"
+)
+
+// AuthProvider 认证提供者，在每次发送请求前为请求附加认证信息
+type AuthProvider interface {
+	// Apply 为请求附加认证信息
+	Apply(ctx context.Context, req *http.Request) error
+	// Invalidate 使当前缓存的凭证失效，收到401时由客户端调用
+	Invalidate()
+}
+
+// Token 访问令牌
+type Token struct {
+	AccessToken  string    `json:"access_token"`  // 访问令牌
+	TokenType    string    `json:"token_type"`    // 令牌类型
+	RefreshToken string    `json:"refresh_token"` // 刷新令牌
+	Expiry       time.Time `json:"expiry"`        // 过期时间，零值表示永不过期
+}
+
+// Valid 判断令牌在提前量delta内是否仍然有效
+func (t *Token) Valid(delta time.Duration) bool {
+	if t == nil || t.AccessToken == "" {
+		return false
+	}
+	if t.Expiry.IsZero() {
+		return true
+	}
+	return time.Now().Add(delta).Before(t.Expiry)
+}
+
+// authorizationValue 生成Authorization头的值
+func (t *Token) authorizationValue() string {
+	tokenType := t.TokenType
+	if tokenType == "" || strings.EqualFold(tokenType, "bearer") {
+		tokenType = "Bearer"
+	}
+	return tokenType + " " + t.AccessToken
+}
+
+// TokenFetcher 令牌获取函数
+type TokenFetcher func(ctx context.Context) (*Token, error)
+
+// tokenFetchTimeout 合并刷新令牌的超时时间
+const tokenFetchTimeout = 30 * time.Second
+
+// TokenAuth 基于令牌的认证提供者，缓存令牌直到过期，并发刷新只会触发一次获取
+type TokenAuth struct {
+	fetch       TokenFetcher
+	expiryDelta time.Duration
+	token       *Token
+	group       *singleflight.Group
+	mutex       sync.RWMutex
+}
+
+// NewTokenAuth 创建基于令牌的认证提供者，expiryDelta为令牌提前刷新的时间
+func NewTokenAuth(fetch TokenFetcher, expiryDelta time.Duration) *TokenAuth {
+	return &TokenAuth{
+		fetch:       fetch,
+		expiryDelta: expiryDelta,
+		group:       singleflight.NewGroup(),
+	}
+}
+
+// Token 获取有效令牌，必要时刷新
+func (a *TokenAuth) Token(ctx context.Context) (*Token, error) {
+	a.mutex.RLock()
+	token := a.token
+	a.mutex.RUnlock()
+	if token.Valid(a.expiryDelta) {
+		return token, nil
+	}
+
+	ch := a.group.DoChan("token", func() (interface{}, error) {
+		// 再次检查，避免重复刷新
+		a.mutex.RLock()
+		token := a.token
+		a.mutex.RUnlock()
+		if token.Valid(a.expiryDelta) {
+			return token, nil
+		}
+
+		// 合并的刷新不应因发起者取消而让其他等待者失败，改用固定超时
+		fetchCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), tokenFetchTimeout)
+		defer cancel()
+		newToken, err := a.fetch(fetchCtx)
+		if err != nil {
+			return nil, err
+		}
+		if newToken == nil || newToken.AccessToken == "" {
+			return nil, fmt.Errorf("token fetcher returned empty token")
+		}
+
+		a.mutex.Lock()
+		a.token = newToken
+		a.mutex.Unlock()
+		return newToken, nil
+	})
+	select {
+	case result := <-ch:
+		if result.Err != nil {
+			return nil, fmt.Errorf("failed to fetch token: %w", result.Err)
+		}
+		return result.Val.(*Token), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Apply 为请求附加Authorization头
+func (a *TokenAuth) Apply(ctx context.Context, req *http.Request) error {
+	token, err := a.Token(ctx)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", token.authorizationValue())
+	return nil
+}
+
+// Invalidate 丢弃缓存的令牌
+func (a *TokenAuth) Invalidate() {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.token = nil
+}
+
+// OAuth2Config OAuth2配置
+type OAuth2Config struct {
+	TokenURL     string            `json:"token_url" yaml:"token_url"`         // 令牌端点
+	ClientID     string            `json:"client_id" yaml:"client_id"`         // 客户端ID
+	ClientSecret string            `json:"client_secret" yaml:"client_secret"` // 客户端密钥
+	Scopes       []string          `json:"scopes" yaml:"scopes"`               // 权限范围
+	Params       map[string]string `json:"params" yaml:"params"`               // 额外的表单参数
+	ExpiryDelta  time.Duration     `json:"expiry_delta" yaml:"expiry_delta"`   // 令牌提前刷新时间
+	HTTPClient   *http.Client      `json:"-" yaml:"-"`                         // 请求令牌使用的客户端
+}
+
+// defaultExpiryDelta 默认令牌提前刷新时间
+const defaultExpiryDelta = 10 * time.Second
+
+// NewClientCredentialsAuth 创建OAuth2客户端凭证模式的认证提供者
+func NewClientCredentialsAuth(config *OAuth2Config) *TokenAuth {
+	fetch := func(ctx context.Context) (*Token, error) {
+		form := url.Values{}
+		form.Set("grant_type", "client_credentials")
+		return requestToken(ctx, config, form)
+	}
+	return NewTokenAuth(fetch, oauth2ExpiryDelta(config))
+}
+
+// NewRefreshTokenAuth 创建OAuth2刷新令牌模式的认证提供者，服务端轮换的刷新令牌会被自动保存
+func NewRefreshTokenAuth(config *OAuth2Config, refreshToken string) *TokenAuth {
+	var mutex sync.Mutex
+	fetch := func(ctx context.Context) (*Token, error) {
+		mutex.Lock()
+		current := refreshToken
+		mutex.Unlock()
+
+		form := url.Values{}
+		form.Set("grant_type", "refresh_token")
+		form.Set("refresh_token", current)
+		token, err := requestToken(ctx, config, form)
+		if err != nil {
+			return nil, err
+		}
+
+		mutex.Lock()
+		if token.RefreshToken != "" {
+			refreshToken = token.RefreshToken
+		} else {
+			token.RefreshToken = refreshToken
+		}
+		mutex.Unlock()
+		return token, nil
+	}
+	return NewTokenAuth(fetch, oauth2ExpiryDelta(config))
+}
+
+// oauth2ExpiryDelta 获取令牌提前刷新时间
+func oauth2ExpiryDelta(config *OAuth2Config) time.Duration {
+	if config.ExpiryDelta > 0 {
+		return config.ExpiryDelta
+	}
+	return defaultExpiryDelta
+}
+
+// requestToken 向令牌端点请求令牌
+func requestToken(ctx context.Context, config *OAuth2Config, form url.Values) (*Token, error) {
+	if config.ClientID != "" {
+		form.Set("client_id", config.ClientID)
+	}
+	if config.ClientSecret != "" {
+		form.Set("client_secret", config.ClientSecret)
+	}
+	if len(config.Scopes) > 0 {
+		form.Set("scope", strings.Join(config.Scopes, " "))
+	}
+	for key, value := range config.Params {
+		form.Set(key, value)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, config.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	client := config.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send token request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("token endpoint returned HTTP %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		AccessToken  string      `json:"access_token"`
+		TokenType    string      `json:"token_type"`
+		RefreshToken string      `json:"refresh_token"`
+		ExpiresIn    json.Number `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode token response: %w", err)
+	}
+	if result.AccessToken == "" {
+		return nil, fmt.Errorf("token response missing access_token")
+	}
+
+	token := &Token{
+		AccessToken:  result.AccessToken,
+		TokenType:    result.TokenType,
+		RefreshToken: result.RefreshToken,
+	}
+	if seconds, err := result.ExpiresIn.Int64(); err == nil && seconds > 0 {
+		token.Expiry = time.Now().Add(time.Duration(seconds) * time.Second)
+	}
+	return token, nil
+}
+
+// HMACConfig HMAC签名配置
+type HMACConfig struct {
+	KeyID           string `json:"key_id" yaml:"key_id"`                     // 密钥ID
+	Secret          []byte `json:"-" yaml:"-"`                               // 签名密钥
+	TimestampHeader string `json:"timestamp_header" yaml:"timestamp_header"` // 时间戳请求头，默认X-Timestamp
+	BodyHashHeader  string `json:"body_hash_header" yaml:"body_hash_header"` // 请求体哈希请求头，默认X-Content-SHA256
+	Scheme          string `json:"scheme" yaml:"scheme"`                     // Authorization头的认证方案，默认HMAC-SHA256
+}
+
+// HMACAuth HMAC请求签名认证提供者
+//
+// 待签名字符串为：METHOD\nPATH\nTIMESTAMP\nHEX(SHA256(BODY))，
+// 其中PATH包含查询参数，TIMESTAMP为Unix秒。
+type HMACAuth struct {
+	config *HMACConfig
+	now    func() time.Time
+}
+
+// NewHMACAuth 创建HMAC请求签名认证提供者
+func NewHMACAuth(config *HMACConfig) *HMACAuth {
+	cfg := *config
+	if cfg.TimestampHeader == "" {
+		cfg.TimestampHeader = "X-Timestamp"
+	}
+	if cfg.BodyHashHeader == "" {
+		cfg.BodyHashHeader = "X-Content-SHA256"
+	}
+	if cfg.Scheme == "" {
+		cfg.Scheme = "HMAC-SHA256"
+	}
+	return &HMACAuth{config: &cfg, now: time.Now}
+}
+
+// Apply 对请求进行签名
+func (a *HMACAuth) Apply(ctx context.Context, req *http.Request) error {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return err
+	}
+
+	bodyHash := sha256.Sum256(body)
+	bodyHashHex := hex.EncodeToString(bodyHash[:])
+	timestamp := strconv.FormatInt(a.now().Unix(), 10)
+	signature := SignHMAC(a.config.Secret, req.Method, req.URL.RequestURI(), timestamp, bodyHashHex)
+
+	req.Header.Set(a.config.TimestampHeader, timestamp)
+	req.Header.Set(a.config.BodyHashHeader, bodyHashHex)
+	req.Header.Set("Authorization", fmt.Sprintf("%s keyId=%s, signature=%s", a.config.Scheme, a.config.KeyID, signature))
+	return nil
+}
+
+// Invalidate HMAC签名没有缓存的凭证
+func (a *HMACAuth) Invalidate() {}
+
+// SignHMAC 计算HMAC-SHA256签名，返回Base64编码结果，服务端可用于校验
+func SignHMAC(secret []byte, method, path, timestamp, bodyHashHex string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(strings.ToUpper(method) + "\n" + path + "\n" + timestamp + "\n" + bodyHashHex))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// readRequestBody 读取请求体，并保证请求体可以再次读取
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+
+	if req.GetBody != nil {
+		reader, err := req.GetBody()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get request body: %w", err)
+		}
+		defer reader.Close()
+		return io.ReadAll(reader)
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+	req.Body.Close()
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	return body, nil
+}
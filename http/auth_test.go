@@ -0,0 +1,284 @@
+package http
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTokenServer(t *testing.T, expiresIn int, fetches *int64) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		n := atomic.AddInt64(fetches, 1)
+		time.Sleep(20 * time.Millisecond)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token":  fmt.Sprintf("token-%d", n),
+			"token_type":    "bearer",
+			"expires_in":    expiresIn,
+			"refresh_token": fmt.Sprintf("refresh-%d", n),
+			"grant_type":    r.Form.Get("grant_type"),
+		})
+	}))
+}
+
+func TestClientCredentialsAuth(t *testing.T) {
+	var fetches int64
+	tokenServer := newTokenServer(t, 3600, &fetches)
+	defer tokenServer.Close()
+
+	var lastAuth atomic.Value
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lastAuth.Store(r.Header.Get("Authorization"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer api.Close()
+
+	auth := NewClientCredentialsAuth(&OAuth2Config{
+		TokenURL:     tokenServer.URL,
+		ClientID:     "id",
+		ClientSecret: "secret",
+		Scopes:       []string{"read", "write"},
+	})
+	client := New(&Config{BaseURL: api.URL, Auth: auth})
+	ctx := context.Background()
+
+	t.Run("token cached and fetched once under concurrency", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				resp, err := client.Get(ctx, "/")
+				assert.NoError(t, err)
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, int64(1), atomic.LoadInt64(&fetches))
+		assert.Equal(t, "Bearer token-1", lastAuth.Load())
+	})
+
+	t.Run("invalidate forces refresh", func(t *testing.T) {
+		auth.Invalidate()
+		_, err := client.Get(ctx, "/")
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), atomic.LoadInt64(&fetches))
+		assert.Equal(t, "Bearer token-2", lastAuth.Load())
+	})
+}
+
+func TestTokenAuthExpiry(t *testing.T) {
+	var fetches int64
+	tokenServer := newTokenServer(t, 1, &fetches)
+	defer tokenServer.Close()
+
+	auth := NewClientCredentialsAuth(&OAuth2Config{
+		TokenURL:    tokenServer.URL,
+		ExpiryDelta: 500 * time.Millisecond,
+	})
+	ctx := context.Background()
+
+	token, err := auth.Token(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "token-1", token.AccessToken)
+
+	time.Sleep(600 * time.Millisecond)
+	token, err = auth.Token(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "token-2", token.AccessToken)
+}
+
+func TestTokenAuthFetchSurvivesCallerCancel(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var fetches int64
+	auth := NewTokenAuth(func(ctx context.Context) (*Token, error) {
+		atomic.AddInt64(&fetches, 1)
+		close(started)
+		select {
+		case <-release:
+			return &Token{AccessToken: "shared"}, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}, 0)
+
+	firstCtx, cancel := context.WithCancel(context.Background())
+	firstErr := make(chan error, 1)
+	go func() {
+		_, err := auth.Token(firstCtx)
+		firstErr <- err
+	}()
+	<-started
+
+	second := make(chan *Token, 1)
+	go func() {
+		token, err := auth.Token(context.Background())
+		assert.NoError(t, err)
+		second <- token
+	}()
+
+	// 发起者取消只影响自身，共享的刷新继续进行
+	cancel()
+	assert.ErrorIs(t, <-firstErr, context.Canceled)
+	close(release)
+
+	token := <-second
+	require.NotNil(t, token)
+	assert.Equal(t, "shared", token.AccessToken)
+	assert.Equal(t, int64(1), atomic.LoadInt64(&fetches))
+}
+
+func TestRefreshTokenAuth(t *testing.T) {
+	var received []string
+	var mutex sync.Mutex
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		mutex.Lock()
+		received = append(received, r.Form.Get("refresh_token"))
+		n := len(received)
+		mutex.Unlock()
+
+		assert.Equal(t, "refresh_token", r.Form.Get("grant_type"))
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token":  fmt.Sprintf("access-%d", n),
+			"expires_in":    3600,
+			"refresh_token": fmt.Sprintf("rotated-%d", n),
+		})
+	}))
+	defer tokenServer.Close()
+
+	auth := NewRefreshTokenAuth(&OAuth2Config{TokenURL: tokenServer.URL}, "initial")
+	ctx := context.Background()
+
+	_, err := auth.Token(ctx)
+	require.NoError(t, err)
+	auth.Invalidate()
+	token, err := auth.Token(ctx)
+	require.NoError(t, err)
+
+	assert.Equal(t, "access-2", token.AccessToken)
+	assert.Equal(t, []string{"initial", "rotated-1"}, received)
+}
+
+func TestAuthRetryOn401(t *testing.T) {
+	var fetches int64
+	tokenServer := newTokenServer(t, 3600, &fetches)
+	defer tokenServer.Close()
+
+	var calls int64
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&calls, 1)
+		// 只接受第二个令牌，模拟令牌被服务端提前吊销
+		if r.Header.Get("Authorization") != "Bearer token-2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer api.Close()
+
+	client := New(&Config{
+		BaseURL: api.URL,
+		Auth:    NewClientCredentialsAuth(&OAuth2Config{TokenURL: tokenServer.URL}),
+	})
+
+	resp, err := client.Get(context.Background(), "/")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int64(2), atomic.LoadInt64(&calls))
+	assert.Equal(t, int64(2), atomic.LoadInt64(&fetches))
+}
+
+func TestAuthRetryOn401OnlyOnce(t *testing.T) {
+	var calls int64
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&calls, 1)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer api.Close()
+
+	var fetches int64
+	auth := NewTokenAuth(func(ctx context.Context) (*Token, error) {
+		n := atomic.AddInt64(&fetches, 1)
+		return &Token{AccessToken: fmt.Sprintf("t%d", n)}, nil
+	}, 0)
+	client := New(&Config{BaseURL: api.URL, Auth: auth})
+
+	resp, err := client.Get(context.Background(), "/")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.Equal(t, int64(2), atomic.LoadInt64(&calls))
+}
+
+func TestHMACAuth(t *testing.T) {
+	secret := []byte("top-secret")
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		sum := sha256.Sum256(body)
+		bodyHash := hex.EncodeToString(sum[:])
+		if bodyHash != r.Header.Get("X-Content-SHA256") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		expected := SignHMAC(secret, r.Method, r.URL.RequestURI(), r.Header.Get("X-Timestamp"), bodyHash)
+		if r.Header.Get("Authorization") != "HMAC-SHA256 keyId=key-1, signature="+expected {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer api.Close()
+
+	client := New(&Config{
+		BaseURL: api.URL,
+		Auth:    NewHMACAuth(&HMACConfig{KeyID: "key-1", Secret: secret}),
+	})
+	ctx := context.Background()
+
+	t.Run("sign GET with query", func(t *testing.T) {
+		resp, err := client.Get(ctx, "/orders", WithQuery("page", "2"))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("sign POST JSON body", func(t *testing.T) {
+		resp, err := client.Post(ctx, "/orders", map[string]string{"id": "1"})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("sign POST reader body", func(t *testing.T) {
+		resp, err := client.Post(ctx, "/orders", io.NopCloser(strings.NewReader("raw-body")))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("wrong secret rejected", func(t *testing.T) {
+		bad := New(&Config{
+			BaseURL: api.URL,
+			Auth:    NewHMACAuth(&HMACConfig{KeyID: "key-1", Secret: []byte("wrong")}),
+			Retry:   &RetryConfig{MaxRetries: 0},
+		})
+		resp, err := bad.Get(ctx, "/orders")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+}
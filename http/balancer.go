@@ -0,0 +1,166 @@
+package http
+
+import (
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// LoadBalanceStrategy 负载均衡策略
+type LoadBalanceStrategy int
+
+const (
+	// LoadBalanceRoundRobin 轮询
+	LoadBalanceRoundRobin LoadBalanceStrategy = iota
+	// LoadBalanceRandom 随机
+	LoadBalanceRandom
+	// LoadBalanceLeastLatency 最低延迟（按指数加权移动平均）
+	LoadBalanceLeastLatency
+)
+
+// LoadBalanceConfig 多端点负载均衡配置
+type LoadBalanceConfig struct {
+	Strategy         LoadBalanceStrategy `json:"strategy" yaml:"strategy"`                   // 负载均衡策略
+	FailureThreshold int                 `json:"failure_threshold" yaml:"failure_threshold"` // 连续失败多少次后摘除端点
+	EjectDuration    time.Duration       `json:"eject_duration" yaml:"eject_duration"`       // 端点被摘除的时长
+}
+
+// DefaultLoadBalanceConfig 默认负载均衡配置
+func DefaultLoadBalanceConfig() *LoadBalanceConfig {
+	return &LoadBalanceConfig{
+		Strategy:         LoadBalanceRoundRobin,
+		FailureThreshold: 3,
+		EjectDuration:    30 * time.Second,
+	}
+}
+
+// EndpointStats 端点状态
+type EndpointStats struct {
+	URL          string        `json:"url"`           // 端点地址
+	Latency      time.Duration `json:"latency"`       // 平均延迟
+	Failures     int           `json:"failures"`      // 连续失败次数
+	Ejected      bool          `json:"ejected"`       // 是否被摘除
+	EjectedUntil time.Time     `json:"ejected_until"` // 摘除截止时间
+}
+
+// endpointEWMAWeight 延迟移动平均中新样本的权重
+const endpointEWMAWeight = 0.3
+
+// endpoint 单个上游端点
+type endpoint struct {
+	url          string
+	latency      float64 // 纳秒
+	failures     int
+	ejectedUntil time.Time
+}
+
+// balancer 多端点选择器，被动摘除连续失败的端点
+type balancer struct {
+	config    *LoadBalanceConfig
+	endpoints []*endpoint
+	next      uint64
+	mutex     sync.Mutex
+}
+
+// newBalancer 创建端点选择器
+func newBalancer(urls []string, config *LoadBalanceConfig) *balancer {
+	if config == nil {
+		config = DefaultLoadBalanceConfig()
+	}
+	endpoints := make([]*endpoint, len(urls))
+	for i, url := range urls {
+		endpoints[i] = &endpoint{url: url}
+	}
+	return &balancer{config: config, endpoints: endpoints}
+}
+
+// pick 选择一个端点，优先选择未被摘除且未在exclude中的端点
+func (b *balancer) pick(exclude map[string]bool) *endpoint {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	now := time.Now()
+	var candidates []*endpoint
+	for _, ep := range b.endpoints {
+		if !exclude[ep.url] && now.After(ep.ejectedUntil) {
+			candidates = append(candidates, ep)
+		}
+	}
+	if len(candidates) == 0 {
+		// 所有端点都已尝试过：忽略exclude，只过滤摘除
+		for _, ep := range b.endpoints {
+			if now.After(ep.ejectedUntil) {
+				candidates = append(candidates, ep)
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		// 所有端点都被摘除：选择最早恢复的端点
+		earliest := b.endpoints[0]
+		for _, ep := range b.endpoints[1:] {
+			if ep.ejectedUntil.Before(earliest.ejectedUntil) {
+				earliest = ep
+			}
+		}
+		return earliest
+	}
+
+	switch b.config.Strategy {
+	case LoadBalanceRandom:
+		return candidates[rand.Intn(len(candidates))]
+	case LoadBalanceLeastLatency:
+		best := candidates[0]
+		for _, ep := range candidates[1:] {
+			if ep.latency < best.latency {
+				best = ep
+			}
+		}
+		return best
+	default:
+		index := atomic.AddUint64(&b.next, 1) - 1
+		return candidates[index%uint64(len(candidates))]
+	}
+}
+
+// report 上报一次请求结果
+func (b *balancer) report(ep *endpoint, latency time.Duration, success bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if ep.latency == 0 {
+		ep.latency = float64(latency)
+	} else {
+		ep.latency = endpointEWMAWeight*float64(latency) + (1-endpointEWMAWeight)*ep.latency
+	}
+
+	if success {
+		ep.failures = 0
+		return
+	}
+
+	ep.failures++
+	if b.config.FailureThreshold > 0 && ep.failures >= b.config.FailureThreshold {
+		ep.ejectedUntil = time.Now().Add(b.config.EjectDuration)
+		ep.failures = 0
+	}
+}
+
+// stats 返回所有端点状态
+func (b *balancer) stats() []EndpointStats {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	now := time.Now()
+	stats := make([]EndpointStats, len(b.endpoints))
+	for i, ep := range b.endpoints {
+		stats[i] = EndpointStats{
+			URL:          ep.url,
+			Latency:      time.Duration(ep.latency),
+			Failures:     ep.failures,
+			Ejected:      now.Before(ep.ejectedUntil),
+			EjectedUntil: ep.ejectedUntil,
+		}
+	}
+	return stats
+}
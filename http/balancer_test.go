@@ -0,0 +1,222 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCountingServer 创建记录请求次数的测试服务器
+func newCountingServer(status int, delay time.Duration, name string) (*httptest.Server, *int64) {
+	var count int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&count, 1)
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+		w.WriteHeader(status)
+		w.Write([]byte(name))
+	}))
+	return server, &count
+}
+
+func fastRetry(maxRetries int) *RetryConfig {
+	return &RetryConfig{
+		MaxRetries:     maxRetries,
+		BaseDelay:      time.Millisecond,
+		MaxDelay:       time.Millisecond,
+		Strategy:       RetryStrategyFixed,
+		RetryableCodes: []int{500, 502, 503, 504},
+	}
+}
+
+func TestLoadBalance(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("round robin", func(t *testing.T) {
+		a, countA := newCountingServer(http.StatusOK, 0, "a")
+		defer a.Close()
+		b, countB := newCountingServer(http.StatusOK, 0, "b")
+		defer b.Close()
+
+		client := New(&Config{BaseURLs: []string{a.URL, b.URL}, Retry: fastRetry(0)})
+		for i := 0; i < 10; i++ {
+			_, err := client.Get(ctx, "/")
+			require.NoError(t, err)
+		}
+		assert.Equal(t, int64(5), atomic.LoadInt64(countA))
+		assert.Equal(t, int64(5), atomic.LoadInt64(countB))
+	})
+
+	t.Run("random", func(t *testing.T) {
+		a, countA := newCountingServer(http.StatusOK, 0, "a")
+		defer a.Close()
+		b, countB := newCountingServer(http.StatusOK, 0, "b")
+		defer b.Close()
+
+		client := New(&Config{
+			BaseURLs:    []string{a.URL, b.URL},
+			LoadBalance: &LoadBalanceConfig{Strategy: LoadBalanceRandom},
+			Retry:       fastRetry(0),
+		})
+		for i := 0; i < 40; i++ {
+			_, err := client.Get(ctx, "/")
+			require.NoError(t, err)
+		}
+		assert.Greater(t, atomic.LoadInt64(countA), int64(0))
+		assert.Greater(t, atomic.LoadInt64(countB), int64(0))
+	})
+
+	t.Run("least latency", func(t *testing.T) {
+		slow, countSlow := newCountingServer(http.StatusOK, 30*time.Millisecond, "slow")
+		defer slow.Close()
+		fast, countFast := newCountingServer(http.StatusOK, 0, "fast")
+		defer fast.Close()
+
+		client := New(&Config{
+			BaseURLs:    []string{slow.URL, fast.URL},
+			LoadBalance: &LoadBalanceConfig{Strategy: LoadBalanceLeastLatency},
+			Retry:       fastRetry(0),
+		})
+		for i := 0; i < 10; i++ {
+			_, err := client.Get(ctx, "/")
+			require.NoError(t, err)
+		}
+		assert.Equal(t, int64(1), atomic.LoadInt64(countSlow))
+		assert.Equal(t, int64(9), atomic.LoadInt64(countFast))
+	})
+
+	t.Run("failover on retry", func(t *testing.T) {
+		bad, countBad := newCountingServer(http.StatusServiceUnavailable, 0, "bad")
+		defer bad.Close()
+		good, _ := newCountingServer(http.StatusOK, 0, "good")
+		defer good.Close()
+
+		client := New(&Config{BaseURLs: []string{bad.URL, good.URL}, Retry: fastRetry(2)})
+		for i := 0; i < 4; i++ {
+			resp, err := client.Get(ctx, "/")
+			require.NoError(t, err)
+			assert.Equal(t, "good", resp.Text)
+		}
+		// 默认连续失败3次后摘除
+		assert.LessOrEqual(t, atomic.LoadInt64(countBad), int64(3))
+	})
+
+	t.Run("passive ejection", func(t *testing.T) {
+		bad, countBad := newCountingServer(http.StatusInternalServerError, 0, "bad")
+		defer bad.Close()
+		good, _ := newCountingServer(http.StatusOK, 0, "good")
+		defer good.Close()
+
+		client := New(&Config{
+			BaseURLs: []string{bad.URL, good.URL},
+			LoadBalance: &LoadBalanceConfig{
+				Strategy:         LoadBalanceRoundRobin,
+				FailureThreshold: 2,
+				EjectDuration:    time.Minute,
+			},
+			Retry: fastRetry(0),
+		})
+		for i := 0; i < 10; i++ {
+			_, err := client.Get(ctx, "/")
+			require.NoError(t, err)
+		}
+		assert.Equal(t, int64(2), atomic.LoadInt64(countBad))
+
+		stats := client.GetEndpointStats()
+		require.Len(t, stats, 2)
+		assert.True(t, stats[0].Ejected)
+		assert.False(t, stats[1].Ejected)
+	})
+
+	t.Run("all ejected still serves", func(t *testing.T) {
+		bad, countBad := newCountingServer(http.StatusInternalServerError, 0, "bad")
+		defer bad.Close()
+
+		client := New(&Config{
+			BaseURLs:    []string{bad.URL},
+			LoadBalance: &LoadBalanceConfig{FailureThreshold: 1, EjectDuration: time.Minute},
+			Retry:       fastRetry(0),
+		})
+		for i := 0; i < 3; i++ {
+			resp, err := client.Get(ctx, "/")
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		}
+		assert.Equal(t, int64(3), atomic.LoadInt64(countBad))
+	})
+}
+
+func TestHedgedRequests(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("hedge wins against slow endpoint", func(t *testing.T) {
+		slow, countSlow := newCountingServer(http.StatusOK, 500*time.Millisecond, "slow")
+		defer slow.Close()
+		fast, countFast := newCountingServer(http.StatusOK, 0, "fast")
+		defer fast.Close()
+
+		client := New(&Config{
+			BaseURLs: []string{slow.URL, fast.URL},
+			Hedge:    &HedgeConfig{InitialDelay: 20 * time.Millisecond},
+			Retry:    fastRetry(0),
+		})
+
+		start := time.Now()
+		resp, err := client.Get(ctx, "/")
+		require.NoError(t, err)
+		assert.Equal(t, "fast", resp.Text)
+		assert.Less(t, time.Since(start), 300*time.Millisecond)
+		assert.Equal(t, int64(1), atomic.LoadInt64(countSlow))
+		assert.Equal(t, int64(1), atomic.LoadInt64(countFast))
+	})
+
+	t.Run("no hedge when fast", func(t *testing.T) {
+		server, count := newCountingServer(http.StatusOK, 0, "ok")
+		defer server.Close()
+
+		client := New(&Config{
+			BaseURL: server.URL,
+			Hedge:   &HedgeConfig{InitialDelay: 200 * time.Millisecond},
+			Retry:   fastRetry(0),
+		})
+		for i := 0; i < 5; i++ {
+			_, err := client.Get(ctx, "/")
+			require.NoError(t, err)
+		}
+		assert.Equal(t, int64(5), atomic.LoadInt64(count))
+	})
+
+	t.Run("non idempotent methods are not hedged", func(t *testing.T) {
+		slow, count := newCountingServer(http.StatusOK, 100*time.Millisecond, "slow")
+		defer slow.Close()
+
+		client := New(&Config{
+			BaseURL: slow.URL,
+			Hedge:   &HedgeConfig{InitialDelay: 10 * time.Millisecond},
+			Retry:   fastRetry(0),
+		})
+		_, err := client.Post(ctx, "/", "body")
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), atomic.LoadInt64(count))
+	})
+
+	t.Run("percentile delay", func(t *testing.T) {
+		h := newHedger(&HedgeConfig{InitialDelay: time.Second, MinSamples: 10, WindowSize: 10})
+		assert.Equal(t, time.Second, h.delay())
+
+		for i := 1; i <= 20; i++ {
+			h.record(time.Duration(i) * time.Millisecond)
+		}
+		// 窗口内保留最近10个样本：11ms..20ms，p95为20ms
+		assert.Equal(t, 20*time.Millisecond, h.delay())
+	})
+}
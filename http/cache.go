@@ -0,0 +1,462 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/daxiong0327/tool-kit/singleflight"
+)
+
+// CacheStatusHeader 响应头，标记响应来源：HIT、MISS、STALE、REVALIDATED
+const CacheStatusHeader = "X-Cache"
+
+// 缓存状态
+const (
+	CacheHit         = "HIT"         // 新鲜的缓存响应
+	CacheMiss        = "MISS"        // 来自源站的响应
+	CacheStale       = "STALE"       // 过期但在stale-while-revalidate窗口内的缓存响应，后台正在刷新
+	CacheRevalidated = "REVALIDATED" // 条件请求返回304，使用缓存的响应体
+)
+
+// CacheConfig 响应缓存配置
+//
+// 缓存只作用于GET请求，遵循Cache-Control、Expires、ETag/If-None-Match、
+// Last-Modified/If-Modified-Since和Vary语义，作为客户端私有缓存使用。
+type CacheConfig struct {
+	Store                CacheStore    `json:"-" yaml:"-"`                                           // 缓存存储，默认容量1000的内存LRU
+	DefaultTTL           time.Duration `json:"default_ttl" yaml:"default_ttl"`                       // 响应未声明新鲜度时的缓存时间，0表示不缓存这类响应
+	StaleWhileRevalidate time.Duration `json:"stale_while_revalidate" yaml:"stale_while_revalidate"` // 过期后仍返回旧响应并在后台刷新的时长，响应头中的值优先
+	RetainTTL            time.Duration `json:"retain_ttl" yaml:"retain_ttl"`                         // 带校验器的响应过期后保留用于条件请求的时长
+	MaxBodySize          int64         `json:"max_body_size" yaml:"max_body_size"`                   // 可缓存的最大响应体字节数
+}
+
+// DefaultCacheConfig 默认响应缓存配置
+func DefaultCacheConfig() *CacheConfig {
+	return &CacheConfig{
+		Store:       NewMemoryCacheStore(1000),
+		RetainTTL:   time.Hour,
+		MaxBodySize: 1 << 20,
+	}
+}
+
+// CachedResponse 缓存的响应
+type CachedResponse struct {
+	StatusCode           int               `json:"status_code"`
+	Header               http.Header       `json:"header"`
+	Body                 []byte            `json:"body"`
+	StoredAt             time.Time         `json:"stored_at"`              // 存储或最近一次校验的时间
+	InitialAge           time.Duration     `json:"initial_age"`            // 存储时响应已有的年龄（Age响应头）
+	Lifetime             time.Duration     `json:"lifetime"`               // 新鲜期
+	StaleWhileRevalidate time.Duration     `json:"stale_while_revalidate"` // 过期后可以返回旧响应的时长
+	MustRevalidate       bool              `json:"must_revalidate"`        // 过期后必须校验
+	Vary                 map[string]string `json:"vary,omitempty"`         // Vary请求头及存储时的取值
+}
+
+// age 返回响应当前的年龄
+func (e *CachedResponse) age(now time.Time) time.Duration {
+	return e.InitialAge + now.Sub(e.StoredAt)
+}
+
+// matches 检查请求的Vary请求头是否与缓存时一致
+func (e *CachedResponse) matches(req *http.Request) bool {
+	for name, value := range e.Vary {
+		if req.Header.Get(name) != value {
+			return false
+		}
+	}
+	return true
+}
+
+// hasValidators 响应是否带有可用于条件请求的校验器
+func (e *CachedResponse) hasValidators() bool {
+	return e.Header.Get("ETag") != "" || e.Header.Get("Last-Modified") != ""
+}
+
+// toResponse 构造返回给调用方的响应，每次调用都复制响应头和响应体
+func (e *CachedResponse) toResponse(req *http.Request, status string, now time.Time) *http.Response {
+	header := e.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	header.Set(CacheStatusHeader, status)
+	if status != CacheMiss {
+		header.Set("Age", strconv.FormatInt(int64(e.age(now)/time.Second), 10))
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode)),
+		StatusCode:    e.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
+
+// cacheControl 解析后的Cache-Control指令
+type cacheControl map[string]string
+
+// parseCacheControl 解析Cache-Control头
+func parseCacheControl(header http.Header) cacheControl {
+	cc := make(cacheControl)
+	for _, line := range header.Values("Cache-Control") {
+		for _, part := range strings.Split(line, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			name, value, _ := strings.Cut(part, "=")
+			cc[strings.ToLower(strings.TrimSpace(name))] = strings.Trim(strings.TrimSpace(value), `"`)
+		}
+	}
+	return cc
+}
+
+// has 是否包含指令
+func (cc cacheControl) has(name string) bool {
+	_, ok := cc[name]
+	return ok
+}
+
+// seconds 读取以秒为单位的指令值
+func (cc cacheControl) seconds(name string) (time.Duration, bool) {
+	value, ok := cc[name]
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return time.Duration(n) * time.Second, true
+}
+
+// cacheableStatus 默认可缓存的状态码
+var cacheableStatus = map[int]bool{
+	http.StatusOK:                   true,
+	http.StatusNonAuthoritativeInfo: true,
+	http.StatusNoContent:            true,
+	http.StatusMultipleChoices:      true,
+	http.StatusMovedPermanently:     true,
+	http.StatusPermanentRedirect:    true,
+	http.StatusNotFound:             true,
+	http.StatusMethodNotAllowed:     true,
+	http.StatusGone:                 true,
+	http.StatusRequestURITooLong:    true,
+	http.StatusNotImplemented:       true,
+}
+
+// httpCache 响应缓存，多个Transport可以共享同一个缓存
+type httpCache struct {
+	config *CacheConfig
+	store  CacheStore
+	group  *singleflight.Group
+}
+
+// newHTTPCache 创建响应缓存，零值字段使用默认配置
+func newHTTPCache(config *CacheConfig) *httpCache {
+	defaults := DefaultCacheConfig()
+	cfg := *config
+	if cfg.Store == nil {
+		cfg.Store = defaults.Store
+	}
+	if cfg.RetainTTL <= 0 {
+		cfg.RetainTTL = defaults.RetainTTL
+	}
+	if cfg.MaxBodySize <= 0 {
+		cfg.MaxBodySize = defaults.MaxBodySize
+	}
+	return &httpCache{config: &cfg, store: cfg.Store, group: singleflight.NewGroup()}
+}
+
+// cacheKey 缓存键，同一URL的不同Vary变体共享一个键，后写入的变体覆盖先前的变体
+func cacheKey(u string) string {
+	return http.MethodGet + " " + u
+}
+
+// flightKey 合并并发请求的键，只有URL和请求头完全相同的请求会被合并
+func flightKey(req *http.Request) string {
+	names := make([]string, 0, len(req.Header))
+	for name := range req.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString(req.URL.String())
+	for _, name := range names {
+		b.WriteString("\n")
+		b.WriteString(name)
+		b.WriteString(": ")
+		b.WriteString(strings.Join(req.Header[name], ", "))
+	}
+	return b.String()
+}
+
+// bypass 判断请求是否绕过缓存
+func (c *httpCache) bypass(req *http.Request) bool {
+	if req.Method != http.MethodGet {
+		return true
+	}
+	// 调用方自带条件请求或范围请求时由调用方自行处理
+	if req.Header.Get("Range") != "" || req.Header.Get("If-None-Match") != "" || req.Header.Get("If-Modified-Since") != "" {
+		return true
+	}
+	// 事件流不能缓冲
+	if strings.Contains(req.Header.Get("Accept"), "text/event-stream") {
+		return true
+	}
+	return parseCacheControl(req.Header).has("no-store")
+}
+
+// lookup 查找与请求匹配的缓存响应，存储错误按未命中处理
+func (c *httpCache) lookup(req *http.Request) *CachedResponse {
+	entry, ok, err := c.store.Get(req.Context(), cacheKey(req.URL.String()))
+	if err != nil || !ok || !entry.matches(req) {
+		return nil
+	}
+	return entry
+}
+
+// newEntry 根据响应创建缓存条目，返回的TTL小于等于0表示不可存储
+func (c *httpCache) newEntry(req *http.Request, statusCode int, header http.Header, body []byte, now time.Time) (*CachedResponse, time.Duration) {
+	entry := &CachedResponse{
+		StatusCode: statusCode,
+		Header:     header,
+		Body:       body,
+		StoredAt:   now,
+	}
+	return entry, c.refresh(entry, req)
+}
+
+// refresh 根据响应头计算新鲜期等属性，返回存储TTL
+func (c *httpCache) refresh(entry *CachedResponse, req *http.Request) time.Duration {
+	header := entry.Header
+	cc := parseCacheControl(header)
+
+	entry.InitialAge = 0
+	if age, err := strconv.ParseInt(header.Get("Age"), 10, 64); err == nil && age > 0 {
+		entry.InitialAge = time.Duration(age) * time.Second
+	}
+	entry.MustRevalidate = cc.has("must-revalidate") || cc.has("proxy-revalidate")
+
+	entry.Lifetime = c.lifetime(entry.StatusCode, header, cc)
+	if cc.has("no-cache") {
+		entry.Lifetime = 0
+	}
+	entry.StaleWhileRevalidate = c.config.StaleWhileRevalidate
+	if swr, ok := cc.seconds("stale-while-revalidate"); ok {
+		entry.StaleWhileRevalidate = swr
+	}
+
+	entry.Vary = nil
+	for _, line := range header.Values("Vary") {
+		for _, name := range strings.Split(line, ",") {
+			name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+			if name == "" {
+				continue
+			}
+			if name == "*" {
+				return 0
+			}
+			if entry.Vary == nil {
+				entry.Vary = make(map[string]string)
+			}
+			entry.Vary[name] = req.Header.Get(name)
+		}
+	}
+
+	if cc.has("no-store") || !cacheableStatus[entry.StatusCode] || int64(len(entry.Body)) > c.config.MaxBodySize {
+		return 0
+	}
+
+	ttl := entry.Lifetime - entry.InitialAge
+	if ttl < 0 {
+		ttl = 0
+	}
+	if !entry.MustRevalidate {
+		ttl += entry.StaleWhileRevalidate
+	}
+	if entry.hasValidators() {
+		ttl += c.config.RetainTTL
+	}
+	return ttl
+}
+
+// lifetime 计算新鲜期：max-age优先，其次Expires，再次基于Last-Modified的启发式，最后使用DefaultTTL
+func (c *httpCache) lifetime(statusCode int, header http.Header, cc cacheControl) time.Duration {
+	if maxAge, ok := cc.seconds("max-age"); ok {
+		return maxAge
+	}
+
+	date, err := http.ParseTime(header.Get("Date"))
+	if err != nil {
+		date = time.Now()
+	}
+	if expires := header.Get("Expires"); expires != "" {
+		t, err := http.ParseTime(expires)
+		if err != nil || !t.After(date) {
+			return 0
+		}
+		return t.Sub(date)
+	}
+
+	if statusCode != http.StatusOK {
+		return 0
+	}
+	if lastModified, err := http.ParseTime(header.Get("Last-Modified")); err == nil && date.After(lastModified) {
+		heuristic := date.Sub(lastModified) / 10
+		if heuristic > 24*time.Hour {
+			heuristic = 24 * time.Hour
+		}
+		return heuristic
+	}
+	return c.config.DefaultTTL
+}
+
+// fetchResult 一次源站请求的结果
+type fetchResult struct {
+	entry  *CachedResponse
+	status string
+}
+
+// fetch 向源站发送请求，相同的并发请求只发送一次；stale不为nil时发送条件请求
+func (c *httpCache) fetch(next http.RoundTripper, req *http.Request, stale *CachedResponse) (*fetchResult, error) {
+	ch := c.group.DoChan(flightKey(req), func() (interface{}, error) {
+		return c.doFetch(next, req, stale)
+	})
+	select {
+	case result := <-ch:
+		if result.Err != nil {
+			return nil, result.Err
+		}
+		return result.Val.(*fetchResult), nil
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+}
+
+// revalidate 在后台刷新过期的缓存响应
+func (c *httpCache) revalidate(next http.RoundTripper, req *http.Request, stale *CachedResponse) {
+	c.group.DoChan(flightKey(req), func() (interface{}, error) {
+		return c.doFetch(next, req, stale)
+	})
+}
+
+// doFetch 执行源站请求并更新缓存
+func (c *httpCache) doFetch(next http.RoundTripper, req *http.Request, stale *CachedResponse) (*fetchResult, error) {
+	// 合并的请求不应因发起者取消而失败，但保留其截止时间
+	ctx := context.WithoutCancel(req.Context())
+	if deadline, ok := req.Context().Deadline(); ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, deadline)
+		defer cancel()
+	}
+	out := req.Clone(ctx)
+	if stale != nil {
+		if etag := stale.Header.Get("ETag"); etag != "" {
+			out.Header.Set("If-None-Match", etag)
+		}
+		if lastModified := stale.Header.Get("Last-Modified"); lastModified != "" {
+			out.Header.Set("If-Modified-Since", lastModified)
+		}
+	}
+
+	resp, err := next.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	now := time.Now()
+	key := cacheKey(req.URL.String())
+
+	if stale != nil && resp.StatusCode == http.StatusNotModified {
+		// 304：用新的响应头更新缓存条目
+		entry := *stale
+		entry.Header = stale.Header.Clone()
+		for name, values := range resp.Header {
+			if name == "Content-Length" {
+				continue
+			}
+			entry.Header[name] = values
+		}
+		entry.StoredAt = now
+		if ttl := c.refresh(&entry, req); ttl > 0 {
+			_ = c.store.Set(ctx, key, &entry, ttl)
+		} else {
+			_ = c.store.Delete(ctx, key)
+		}
+		return &fetchResult{entry: &entry, status: CacheRevalidated}, nil
+	}
+
+	entry, ttl := c.newEntry(req, resp.StatusCode, resp.Header, body, now)
+	if ttl > 0 {
+		_ = c.store.Set(ctx, key, entry, ttl)
+	} else if stale != nil {
+		_ = c.store.Delete(ctx, key)
+	}
+	return &fetchResult{entry: entry, status: CacheMiss}, nil
+}
+
+// cacheTransport 响应缓存中间件
+type cacheTransport struct {
+	cache *httpCache
+	next  http.RoundTripper
+}
+
+// RoundTrip 实现 http.RoundTripper 接口
+func (t *cacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.cache.bypass(req) {
+		resp, err := t.next.RoundTrip(req)
+		// 非安全方法成功后使该URL的缓存失效
+		if err == nil && resp.StatusCode < http.StatusBadRequest && !isSafeMethod(req.Method) {
+			_ = t.cache.store.Delete(req.Context(), cacheKey(req.URL.String()))
+		}
+		return resp, err
+	}
+
+	now := time.Now()
+	entry := t.cache.lookup(req)
+	if entry != nil && !parseCacheControl(req.Header).has("no-cache") {
+		age := entry.age(now)
+		if age < entry.Lifetime {
+			return entry.toResponse(req, CacheHit, now), nil
+		}
+		if !entry.MustRevalidate && age < entry.Lifetime+entry.StaleWhileRevalidate {
+			t.cache.revalidate(t.next, req, entry)
+			return entry.toResponse(req, CacheStale, now), nil
+		}
+	}
+	if entry != nil && !entry.hasValidators() {
+		entry = nil
+	}
+
+	result, err := t.cache.fetch(t.next, req, entry)
+	if err != nil {
+		return nil, err
+	}
+	return result.entry.toResponse(req, result.status, time.Now()), nil
+}
+
+// isSafeMethod 是否为安全方法
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
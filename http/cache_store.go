@@ -0,0 +1,159 @@
+package http
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/daxiong0327/tool-kit/redis"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// CacheStore 响应缓存存储接口
+type CacheStore interface {
+	// Get 获取缓存的响应，不存在时返回false
+	Get(ctx context.Context, key string) (*CachedResponse, bool, error)
+	// Set 存储响应，ttl到期后自动删除
+	Set(ctx context.Context, key string, entry *CachedResponse, ttl time.Duration) error
+	// Delete 删除缓存的响应
+	Delete(ctx context.Context, key string) error
+}
+
+// memoryCacheItem 内存缓存项
+type memoryCacheItem struct {
+	key       string
+	entry     *CachedResponse
+	expiresAt time.Time
+}
+
+// MemoryCacheStore 基于LRU的内存缓存存储
+type MemoryCacheStore struct {
+	capacity int
+	items    map[string]*list.Element
+	order    *list.List
+	mutex    sync.Mutex
+}
+
+// NewMemoryCacheStore 创建内存缓存存储，capacity为最多保存的响应数
+func NewMemoryCacheStore(capacity int) *MemoryCacheStore {
+	if capacity <= 0 {
+		capacity = 1000
+	}
+	return &MemoryCacheStore{
+		capacity: capacity,
+		items:    make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// Get 获取缓存的响应
+func (s *MemoryCacheStore) Get(ctx context.Context, key string) (*CachedResponse, bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	elem, ok := s.items[key]
+	if !ok {
+		return nil, false, nil
+	}
+	item := elem.Value.(*memoryCacheItem)
+	if time.Now().After(item.expiresAt) {
+		s.removeElement(elem)
+		return nil, false, nil
+	}
+	s.order.MoveToFront(elem)
+	return item.entry, true, nil
+}
+
+// Set 存储响应，超出容量时淘汰最久未使用的响应
+func (s *MemoryCacheStore) Set(ctx context.Context, key string, entry *CachedResponse, ttl time.Duration) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	expiresAt := time.Now().Add(ttl)
+	if elem, ok := s.items[key]; ok {
+		item := elem.Value.(*memoryCacheItem)
+		item.entry, item.expiresAt = entry, expiresAt
+		s.order.MoveToFront(elem)
+		return nil
+	}
+
+	s.items[key] = s.order.PushFront(&memoryCacheItem{key: key, entry: entry, expiresAt: expiresAt})
+	for s.order.Len() > s.capacity {
+		s.removeElement(s.order.Back())
+	}
+	return nil
+}
+
+// Delete 删除缓存的响应
+func (s *MemoryCacheStore) Delete(ctx context.Context, key string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if elem, ok := s.items[key]; ok {
+		s.removeElement(elem)
+	}
+	return nil
+}
+
+// Len 返回缓存的响应数
+func (s *MemoryCacheStore) Len() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.order.Len()
+}
+
+// removeElement 删除链表元素，调用方需持有锁
+func (s *MemoryCacheStore) removeElement(elem *list.Element) {
+	s.order.Remove(elem)
+	delete(s.items, elem.Value.(*memoryCacheItem).key)
+}
+
+// RedisCacheStore 基于Redis的缓存存储，多个实例可以共享缓存
+type RedisCacheStore struct {
+	client  *redis.Client
+	strings *redis.String
+	prefix  string
+}
+
+// NewRedisCacheStore 创建Redis缓存存储，prefix为键前缀，位于客户端的 KeyPrefix 和命名空间之后
+func NewRedisCacheStore(client *redis.Client, prefix string) *RedisCacheStore {
+	if prefix == "" {
+		prefix = "http:cache:"
+	}
+	return &RedisCacheStore{client: client, strings: client.NewString(), prefix: prefix}
+}
+
+// Get 获取缓存的响应
+func (s *RedisCacheStore) Get(ctx context.Context, key string) (*CachedResponse, bool, error) {
+	data, err := s.strings.Get(ctx, s.prefix+key)
+	if err == goredis.Nil {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	var entry CachedResponse
+	if err := json.Unmarshal([]byte(data), &entry); err != nil {
+		return nil, false, fmt.Errorf("failed to unmarshal cached response: %w", err)
+	}
+	return &entry, true, nil
+}
+
+// Set 存储响应
+func (s *RedisCacheStore) Set(ctx context.Context, key string, entry *CachedResponse, ttl time.Duration) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cached response: %w", err)
+	}
+	return s.strings.Set(ctx, s.prefix+key, data, ttl)
+}
+
+// Delete 删除缓存的响应
+func (s *RedisCacheStore) Delete(ctx context.Context, key string) error {
+	_, err := s.client.Del(ctx, s.prefix+key)
+	return err
+}
@@ -0,0 +1,351 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/daxiong0327/tool-kit/redis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCacheServer 创建测试服务器，handler返回的字符串作为响应体
+func newCacheServer(handler func(w http.ResponseWriter, r *http.Request, n int64) string) (*httptest.Server, *int64) {
+	var count int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt64(&count, 1)
+		body := handler(w, r, n)
+		fmt.Fprint(w, body)
+	}))
+	return server, &count
+}
+
+func newCachingClient(baseURL string, config *CacheConfig) *Client {
+	return New(&Config{BaseURL: baseURL, Retry: fastRetry(0), Cache: config})
+}
+
+func TestCache(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("max-age", func(t *testing.T) {
+		server, count := newCacheServer(func(w http.ResponseWriter, r *http.Request, n int64) string {
+			w.Header().Set("Cache-Control", "max-age=60")
+			return fmt.Sprintf("v%d", n)
+		})
+		defer server.Close()
+
+		client := newCachingClient(server.URL, &CacheConfig{})
+		resp, err := client.Get(ctx, "/config")
+		require.NoError(t, err)
+		assert.Equal(t, "v1", resp.Text)
+		assert.Equal(t, CacheMiss, resp.Headers[CacheStatusHeader])
+
+		resp, err = client.Get(ctx, "/config")
+		require.NoError(t, err)
+		assert.Equal(t, "v1", resp.Text)
+		assert.Equal(t, CacheHit, resp.Headers[CacheStatusHeader])
+		assert.Equal(t, int64(1), atomic.LoadInt64(count))
+
+		// 请求no-cache强制回源
+		resp, err = client.Get(ctx, "/config", WithHeader("Cache-Control", "no-cache"))
+		require.NoError(t, err)
+		assert.Equal(t, "v2", resp.Text)
+	})
+
+	t.Run("no-store and uncacheable", func(t *testing.T) {
+		server, count := newCacheServer(func(w http.ResponseWriter, r *http.Request, n int64) string {
+			if r.URL.Path == "/no-store" {
+				w.Header().Set("Cache-Control", "no-store, max-age=60")
+			}
+			return "ok"
+		})
+		defer server.Close()
+
+		client := newCachingClient(server.URL, &CacheConfig{})
+		for i := 0; i < 2; i++ {
+			_, err := client.Get(ctx, "/no-store")
+			require.NoError(t, err)
+			// 没有新鲜度信息和校验器
+			_, err = client.Get(ctx, "/plain")
+			require.NoError(t, err)
+			_, err = client.Post(ctx, "/no-store", "body")
+			require.NoError(t, err)
+		}
+		assert.Equal(t, int64(6), atomic.LoadInt64(count))
+	})
+
+	t.Run("default ttl", func(t *testing.T) {
+		server, count := newCacheServer(func(w http.ResponseWriter, r *http.Request, n int64) string {
+			return "ok"
+		})
+		defer server.Close()
+
+		client := newCachingClient(server.URL, &CacheConfig{DefaultTTL: time.Minute})
+		for i := 0; i < 3; i++ {
+			_, err := client.Get(ctx, "/plain")
+			require.NoError(t, err)
+		}
+		assert.Equal(t, int64(1), atomic.LoadInt64(count))
+	})
+
+	t.Run("etag revalidation", func(t *testing.T) {
+		server, count := newCacheServer(func(w http.ResponseWriter, r *http.Request, n int64) string {
+			w.Header().Set("ETag", `"v1"`)
+			w.Header().Set("Cache-Control", "no-cache")
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				w.WriteHeader(http.StatusNotModified)
+				return ""
+			}
+			return "payload"
+		})
+		defer server.Close()
+
+		client := newCachingClient(server.URL, &CacheConfig{})
+		resp, err := client.Get(ctx, "/")
+		require.NoError(t, err)
+		assert.Equal(t, CacheMiss, resp.Headers[CacheStatusHeader])
+
+		resp, err = client.Get(ctx, "/")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "payload", resp.Text)
+		assert.Equal(t, CacheRevalidated, resp.Headers[CacheStatusHeader])
+		assert.Equal(t, int64(2), atomic.LoadInt64(count))
+	})
+
+	t.Run("last-modified revalidation", func(t *testing.T) {
+		lastModified := time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat)
+		server, _ := newCacheServer(func(w http.ResponseWriter, r *http.Request, n int64) string {
+			w.Header().Set("Last-Modified", lastModified)
+			w.Header().Set("Cache-Control", "max-age=0")
+			if r.Header.Get("If-Modified-Since") == lastModified {
+				w.WriteHeader(http.StatusNotModified)
+				return ""
+			}
+			return "payload"
+		})
+		defer server.Close()
+
+		client := newCachingClient(server.URL, &CacheConfig{})
+		_, err := client.Get(ctx, "/")
+		require.NoError(t, err)
+		resp, err := client.Get(ctx, "/")
+		require.NoError(t, err)
+		assert.Equal(t, "payload", resp.Text)
+		assert.Equal(t, CacheRevalidated, resp.Headers[CacheStatusHeader])
+	})
+
+	t.Run("vary", func(t *testing.T) {
+		server, count := newCacheServer(func(w http.ResponseWriter, r *http.Request, n int64) string {
+			w.Header().Set("Cache-Control", "max-age=60")
+			w.Header().Set("Vary", "Accept-Language")
+			return r.Header.Get("Accept-Language")
+		})
+		defer server.Close()
+
+		client := newCachingClient(server.URL, &CacheConfig{})
+		resp, err := client.Get(ctx, "/", WithHeader("Accept-Language", "en"))
+		require.NoError(t, err)
+		assert.Equal(t, "en", resp.Text)
+
+		resp, err = client.Get(ctx, "/", WithHeader("Accept-Language", "zh"))
+		require.NoError(t, err)
+		assert.Equal(t, "zh", resp.Text)
+		assert.Equal(t, CacheMiss, resp.Headers[CacheStatusHeader])
+
+		resp, err = client.Get(ctx, "/", WithHeader("Accept-Language", "zh"))
+		require.NoError(t, err)
+		assert.Equal(t, "zh", resp.Text)
+		assert.Equal(t, CacheHit, resp.Headers[CacheStatusHeader])
+		assert.Equal(t, int64(2), atomic.LoadInt64(count))
+	})
+
+	t.Run("collapse concurrent requests", func(t *testing.T) {
+		server, count := newCacheServer(func(w http.ResponseWriter, r *http.Request, n int64) string {
+			time.Sleep(50 * time.Millisecond)
+			w.Header().Set("Cache-Control", "no-store")
+			return "slow"
+		})
+		defer server.Close()
+
+		client := newCachingClient(server.URL, &CacheConfig{})
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				resp, err := client.Get(ctx, "/slow")
+				assert.NoError(t, err)
+				assert.Equal(t, "slow", resp.Text)
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, int64(1), atomic.LoadInt64(count))
+	})
+
+	t.Run("stale while revalidate", func(t *testing.T) {
+		server, count := newCacheServer(func(w http.ResponseWriter, r *http.Request, n int64) string {
+			w.Header().Set("Cache-Control", "max-age=1, stale-while-revalidate=60")
+			return fmt.Sprintf("v%d", n)
+		})
+		defer server.Close()
+
+		store := NewMemoryCacheStore(10)
+		client := newCachingClient(server.URL, &CacheConfig{Store: store})
+		_, err := client.Get(ctx, "/")
+		require.NoError(t, err)
+
+		// 让缓存条目过期
+		entry, ok, _ := store.Get(ctx, cacheKey(server.URL+"/"))
+		require.True(t, ok)
+		entry.StoredAt = entry.StoredAt.Add(-2 * time.Second)
+
+		resp, err := client.Get(ctx, "/")
+		require.NoError(t, err)
+		assert.Equal(t, "v1", resp.Text)
+		assert.Equal(t, CacheStale, resp.Headers[CacheStatusHeader])
+
+		require.Eventually(t, func() bool {
+			resp, err := client.Get(ctx, "/")
+			return err == nil && resp.Text == "v2" && resp.Headers[CacheStatusHeader] == CacheHit
+		}, time.Second, 10*time.Millisecond)
+		assert.Equal(t, int64(2), atomic.LoadInt64(count))
+	})
+
+	t.Run("unsafe method invalidates", func(t *testing.T) {
+		server, _ := newCacheServer(func(w http.ResponseWriter, r *http.Request, n int64) string {
+			w.Header().Set("Cache-Control", "max-age=60")
+			return fmt.Sprintf("v%d", n)
+		})
+		defer server.Close()
+
+		client := newCachingClient(server.URL, &CacheConfig{})
+		_, err := client.Get(ctx, "/item")
+		require.NoError(t, err)
+		_, err = client.Put(ctx, "/item", "new")
+		require.NoError(t, err)
+
+		resp, err := client.Get(ctx, "/item")
+		require.NoError(t, err)
+		assert.Equal(t, "v3", resp.Text)
+
+		require.NoError(t, client.InvalidateCache(ctx, "/item"))
+		resp, err = client.Get(ctx, "/item")
+		require.NoError(t, err)
+		assert.Equal(t, "v4", resp.Text)
+	})
+
+	t.Run("cache survives transport changes", func(t *testing.T) {
+		server, count := newCacheServer(func(w http.ResponseWriter, r *http.Request, n int64) string {
+			w.Header().Set("Cache-Control", "max-age=60")
+			return "ok"
+		})
+		defer server.Close()
+
+		client := newCachingClient(server.URL, &CacheConfig{})
+		_, err := client.Get(ctx, "/")
+		require.NoError(t, err)
+		client.SetPoolConfig(DefaultPoolConfig())
+		resp, err := client.Get(ctx, "/")
+		require.NoError(t, err)
+		assert.Equal(t, CacheHit, resp.Headers[CacheStatusHeader])
+		assert.Equal(t, int64(1), atomic.LoadInt64(count))
+	})
+}
+
+func TestMemoryCacheStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryCacheStore(2)
+
+	require.NoError(t, store.Set(ctx, "a", &CachedResponse{StatusCode: 200}, time.Minute))
+	require.NoError(t, store.Set(ctx, "b", &CachedResponse{StatusCode: 200}, time.Minute))
+	_, ok, _ := store.Get(ctx, "a")
+	require.True(t, ok)
+
+	// b最久未使用，被淘汰
+	require.NoError(t, store.Set(ctx, "c", &CachedResponse{StatusCode: 200}, time.Minute))
+	_, ok, _ = store.Get(ctx, "b")
+	assert.False(t, ok)
+	assert.Equal(t, 2, store.Len())
+
+	require.NoError(t, store.Set(ctx, "expired", &CachedResponse{StatusCode: 200}, -time.Second))
+	_, ok, _ = store.Get(ctx, "expired")
+	assert.False(t, ok)
+
+	require.NoError(t, store.Delete(ctx, "a"))
+	_, ok, _ = store.Get(ctx, "a")
+	assert.False(t, ok)
+}
+
+func TestRedisCacheStore(t *testing.T) {
+	client, err := redis.New(redis.DefaultConfig())
+	if err != nil {
+		t.Skipf("Skipping test: Redis not available: %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	store := NewRedisCacheStore(client, "test:http:cache:")
+	entry := &CachedResponse{
+		StatusCode: 200,
+		Header:     http.Header{"Etag": {`"v1"`}},
+		Body:       []byte("payload"),
+		StoredAt:   time.Now().Truncate(time.Second),
+		Lifetime:   time.Minute,
+	}
+	require.NoError(t, store.Set(ctx, "key", entry, time.Minute))
+	defer store.Delete(ctx, "key")
+
+	got, ok, err := store.Get(ctx, "key")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, entry.Body, got.Body)
+	assert.Equal(t, entry.Header, got.Header)
+	assert.True(t, entry.StoredAt.Equal(got.StoredAt))
+
+	require.NoError(t, store.Delete(ctx, "key"))
+	_, ok, err = store.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestRedisCacheStoreNamespace(t *testing.T) {
+	config := redis.DefaultConfig()
+	config.KeyPrefix = "test:"
+	client, err := redis.New(config)
+	if err != nil {
+		t.Skipf("Skipping test: Redis not available: %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	store := NewRedisCacheStore(client.WithNamespace("tenant"), "http:cache:")
+	entry := &CachedResponse{StatusCode: 200, Body: []byte("payload")}
+	require.NoError(t, store.Set(ctx, "key", entry, time.Minute))
+	defer store.Delete(ctx, "key")
+
+	// 键位于客户端前缀和命名空间之下
+	raw := client.GetUniversalClient()
+	exists, err := raw.Exists(ctx, "test:tenant:http:cache:key").Result()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), exists)
+	exists, err = raw.Exists(ctx, "http:cache:key").Result()
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), exists)
+
+	got, ok, err := store.Get(ctx, "key")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, entry.Body, got.Body)
+
+	require.NoError(t, store.Delete(ctx, "key"))
+	exists, err = raw.Exists(ctx, "test:tenant:http:cache:key").Result()
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), exists)
+}
@@ -10,6 +10,7 @@ import (
 	"math"
 	"net"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -36,8 +37,11 @@ type RetryConfig struct {
 
 // Client HTTP客户端
 type Client struct {
-	client *http.Client
-	config *Config
+	client   *http.Client
+	config   *Config
+	balancer *balancer
+	hedger   *hedger
+	cache    *httpCache
 }
 
 // Config HTTP客户端配置
@@ -56,6 +60,23 @@ type Config struct {
 	// 连接池配置
 	Pool *PoolConfig `json:"pool" yaml:"pool"` // 连接池配置
 
+	// 多端点配置
+	BaseURLs    []string           `json:"base_urls" yaml:"base_urls"`       // 多个基础URL，设置后优先于BaseURL
+	LoadBalance *LoadBalanceConfig `json:"load_balance" yaml:"load_balance"` // 负载均衡配置
+	Hedge       *HedgeConfig       `json:"hedge" yaml:"hedge"`               // 对冲请求配置，为nil时不启用
+
+	// 响应缓存配置
+	Cache *CacheConfig `json:"cache" yaml:"cache"` // GET响应缓存，为nil时不启用
+
+	// TLS配置
+	TLS *TLSConfig `json:"tls" yaml:"tls"` // CA证书、双向TLS客户端证书等，证书文件轮换后自动重新加载
+
+	// 认证配置
+	Auth AuthProvider `json:"-" yaml:"-"` // 认证提供者，收到401时会刷新凭证并重试一次
+
+	// 自定义Transport，设置后忽略连接池配置（例如注入httpmock.Transport）
+	Transport http.RoundTripper `json:"-" yaml:"-"`
+
 	// 向后兼容的字段（已废弃）
 	RetryCount int           `json:"retry_count" yaml:"retry_count"` // 重试次数 (已废弃，使用Retry)
 	RetryDelay time.Duration `json:"retry_delay" yaml:"retry_delay"` // 重试延迟 (已废弃，使用Retry)
@@ -123,25 +144,36 @@ func New(config *Config) *Client {
 	if config.Pool == nil {
 		config.Pool = DefaultPoolConfig()
 	}
-
-	// 创建Transport配置连接池
-	transport := &http.Transport{
-		MaxIdleConns:        config.Pool.MaxIdleConns,
-		MaxIdleConnsPerHost: config.Pool.MaxIdleConnsPerHost,
-		MaxConnsPerHost:     config.Pool.MaxConnsPerHost,
-		IdleConnTimeout:     config.Pool.IdleConnTimeout,
-		DisableKeepAlives:   config.Pool.DisableKeepAlives,
+	if config.Headers == nil {
+		config.Headers = make(map[string]string)
 	}
 
-	client := &http.Client{
-		Timeout:   config.Timeout,
-		Transport: transport,
+	// 创建Transport配置连接池和TLS，TLS配置无效时所有请求都返回该错误
+	var transport http.RoundTripper = config.Transport
+	if transport == nil {
+		var err error
+		if transport, err = newTransport(config); err != nil {
+			transport = &errorTransport{err: err}
+		}
 	}
 
-	return &Client{
-		client: client,
+	c := &Client{
 		config: config,
 	}
+	if config.Cache != nil {
+		c.cache = newHTTPCache(config.Cache)
+	}
+	c.client = &http.Client{
+		Timeout:   config.Timeout,
+		Transport: c.wrapTransport(transport),
+	}
+	if len(config.BaseURLs) > 0 {
+		c.balancer = newBalancer(config.BaseURLs, config.LoadBalance)
+	}
+	if config.Hedge != nil {
+		c.hedger = newHedger(config.Hedge)
+	}
+	return c
 }
 
 // NewWithRetry 创建带重试配置的HTTP客户端
@@ -304,6 +336,7 @@ func (c *Client) Do(ctx context.Context, req *Request, options ...Option) (*Resp
 	}
 
 	var lastErr error
+	tried := make(map[string]bool)
 	retryConfig := c.config.Retry
 	maxRetries := 0
 	if retryConfig != nil {
@@ -312,7 +345,7 @@ func (c *Client) Do(ctx context.Context, req *Request, options ...Option) (*Resp
 
 	// 重试循环
 	for attempt := 0; attempt <= maxRetries; attempt++ {
-		response, err := c.doRequest(ctx, req)
+		response, err := c.attempt(ctx, req, tried)
 
 		// 如果请求成功且状态码不需要重试，直接返回
 		if err == nil && !c.isRetryableStatusCode(response.StatusCode) {
@@ -369,12 +402,104 @@ func (c *Client) Do(ctx context.Context, req *Request, options ...Option) (*Resp
 	return nil, fmt.Errorf("request failed after %d attempts", maxRetries+1)
 }
 
+// attempt 执行一次尝试，重试时优先选择尚未尝试过的端点
+func (c *Client) attempt(ctx context.Context, req *Request, tried map[string]bool) (*Response, error) {
+	if c.hedger != nil && c.hedger.eligible(req) {
+		return c.doHedged(ctx, req, tried)
+	}
+	return c.doEndpoint(ctx, c.pickEndpoint(tried), req)
+}
+
+// pickEndpoint 选择端点并记录为已尝试，未配置多端点时返回nil
+func (c *Client) pickEndpoint(tried map[string]bool) *endpoint {
+	if c.balancer == nil {
+		return nil
+	}
+	ep := c.balancer.pick(tried)
+	tried[ep.url] = true
+	return ep
+}
+
+// doEndpoint 向指定端点发送请求并上报结果，ep为nil时使用BaseURL
+func (c *Client) doEndpoint(ctx context.Context, ep *endpoint, req *Request) (*Response, error) {
+	baseURL := c.config.BaseURL
+	if ep != nil {
+		baseURL = ep.url
+	}
+
+	start := time.Now()
+	response, err := c.doRequestWithAuth(ctx, baseURL, req)
+	latency := time.Since(start)
+
+	// 被取消的请求（例如对冲中落败的请求）不计入端点状态
+	if ctx.Err() != nil {
+		return response, err
+	}
+	success := err == nil && response.StatusCode < http.StatusInternalServerError
+	if ep != nil {
+		c.balancer.report(ep, latency, success)
+	}
+	if c.hedger != nil && success {
+		c.hedger.record(latency)
+	}
+	return response, err
+}
+
+// doRequestWithAuth 执行单次HTTP请求，认证失败(401)时使凭证失效并重试一次
+func (c *Client) doRequestWithAuth(ctx context.Context, baseURL string, req *Request) (*Response, error) {
+	response, err := c.doRequest(ctx, baseURL, req)
+	if err != nil || c.config.Auth == nil || response.StatusCode != http.StatusUnauthorized {
+		return response, err
+	}
+
+	c.config.Auth.Invalidate()
+	return c.doRequest(ctx, baseURL, req)
+}
+
 // doRequest 执行单次HTTP请求
-func (c *Client) doRequest(ctx context.Context, req *Request) (*Response, error) {
+func (c *Client) doRequest(ctx context.Context, baseURL string, req *Request) (*Response, error) {
+	httpReq, err := c.buildRequest(ctx, baseURL, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// 发送请求
+	resp, err := c.httpClient(ctx).Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// 读取响应体
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	// 构建响应
+	response := &Response{
+		StatusCode: resp.StatusCode,
+		Headers:    make(map[string]string),
+		Body:       respBody,
+		Text:       string(respBody),
+	}
+
+	// 设置响应头
+	for key, values := range resp.Header {
+		if len(values) > 0 {
+			response.Headers[key] = values[0]
+		}
+	}
+
+	return response, nil
+}
+
+// buildRequest 构建HTTP请求：拼接URL、序列化请求体、设置请求头、查询参数和认证信息
+func (c *Client) buildRequest(ctx context.Context, baseURL string, req *Request) (*http.Request, error) {
 	// 构建完整URL
 	url := req.URL
-	if c.config.BaseURL != "" {
-		url = c.config.BaseURL + req.URL
+	if baseURL != "" {
+		url = baseURL + req.URL
 	}
 
 	// 创建请求体
@@ -408,11 +533,6 @@ func (c *Client) doRequest(ctx context.Context, req *Request) (*Response, error)
 		httpReq.Header.Set(key, value)
 	}
 
-	// 设置请求头
-	for key, value := range req.Headers {
-		httpReq.Header.Set(key, value)
-	}
-
 	// 设置查询参数
 	if len(req.Query) > 0 {
 		q := httpReq.URL.Query()
@@ -422,35 +542,39 @@ func (c *Client) doRequest(ctx context.Context, req *Request) (*Response, error)
 		httpReq.URL.RawQuery = q.Encode()
 	}
 
-	// 发送请求
-	resp, err := c.client.Do(httpReq)
-	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+	// 附加认证信息
+	if c.config.Auth != nil {
+		if err := c.config.Auth.Apply(ctx, httpReq); err != nil {
+			return nil, fmt.Errorf("failed to apply auth: %w", err)
+		}
 	}
-	defer resp.Body.Close()
 
-	// 读取响应体
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+	// 设置请求头
+	for key, value := range req.Headers {
+		httpReq.Header.Set(key, value)
 	}
 
-	// 构建响应
-	response := &Response{
-		StatusCode: resp.StatusCode,
-		Headers:    make(map[string]string),
-		Body:       respBody,
-		Text:       string(respBody),
-	}
+	return httpReq, nil
+}
 
-	// 设置响应头
-	for key, values := range resp.Header {
-		if len(values) > 0 {
-			response.Headers[key] = values[0]
+// noClientTimeoutKey 上下文键，标记请求不受客户端整体超时限制（用于流式和长轮询请求）
+type noClientTimeoutKey struct{}
+
+// withoutClientTimeout 返回不受Config.Timeout限制的上下文，超时由上下文自身控制
+func withoutClientTimeout(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noClientTimeoutKey{}, true)
+}
+
+// httpClient 根据上下文选择底层客户端
+func (c *Client) httpClient(ctx context.Context) *http.Client {
+	if noTimeout, _ := ctx.Value(noClientTimeoutKey{}).(bool); noTimeout {
+		return &http.Client{
+			Transport:     c.client.Transport,
+			CheckRedirect: c.client.CheckRedirect,
+			Jar:           c.client.Jar,
 		}
 	}
-
-	return response, nil
+	return c.client
 }
 
 // GetJSON 发送GET请求并解析JSON响应
@@ -601,6 +725,24 @@ func (c *Client) SetBaseURL(baseURL string) {
 	c.config.BaseURL = baseURL
 }
 
+// SetBaseURLs 设置多个基础URL，端点状态会被重置
+func (c *Client) SetBaseURLs(baseURLs []string) {
+	c.config.BaseURLs = baseURLs
+	if len(baseURLs) == 0 {
+		c.balancer = nil
+		return
+	}
+	c.balancer = newBalancer(baseURLs, c.config.LoadBalance)
+}
+
+// GetEndpointStats 获取多端点的状态，未配置多端点时返回nil
+func (c *Client) GetEndpointStats() []EndpointStats {
+	if c.balancer == nil {
+		return nil
+	}
+	return c.balancer.stats()
+}
+
 // SetTimeout 设置超时时间
 func (c *Client) SetTimeout(timeout time.Duration) {
 	c.config.Timeout = timeout
@@ -633,6 +775,27 @@ func (c *Client) SetProxy(proxyURL string) {
 // SetInsecure 设置SSL验证
 func (c *Client) SetInsecure(insecure bool) {
 	c.config.Insecure = insecure
+	if c.config.Transport != nil {
+		return
+	}
+	if transport, err := newTransport(c.config); err == nil {
+		c.client.Transport = c.wrapTransport(transport)
+	}
+}
+
+// SetTLSConfig 设置TLS配置，配置无效时返回错误且不影响当前Transport
+func (c *Client) SetTLSConfig(tlsConfig *TLSConfig) error {
+	config := *c.config
+	config.TLS = tlsConfig
+	transport, err := newTransport(&config)
+	if err != nil {
+		return err
+	}
+	c.config.TLS = tlsConfig
+	if c.config.Transport == nil {
+		c.client.Transport = c.wrapTransport(transport)
+	}
+	return nil
 }
 
 // SetDebug 设置调试模式
@@ -648,15 +811,44 @@ func (c *Client) SetRetryConfig(retryConfig *RetryConfig) {
 // SetPoolConfig 设置连接池配置
 func (c *Client) SetPoolConfig(poolConfig *PoolConfig) {
 	c.config.Pool = poolConfig
+	// 自定义Transport不受连接池配置影响
+	if c.config.Transport != nil {
+		return
+	}
 	// 重新创建Transport以应用新配置
-	transport := &http.Transport{
-		MaxIdleConns:        poolConfig.MaxIdleConns,
-		MaxIdleConnsPerHost: poolConfig.MaxIdleConnsPerHost,
-		MaxConnsPerHost:     poolConfig.MaxConnsPerHost,
-		IdleConnTimeout:     poolConfig.IdleConnTimeout,
-		DisableKeepAlives:   poolConfig.DisableKeepAlives,
-	}
-	c.client.Transport = transport
+	if transport, err := newTransport(c.config); err == nil {
+		c.client.Transport = c.wrapTransport(transport)
+	}
+}
+
+// SetAuthProvider 设置认证提供者
+func (c *Client) SetAuthProvider(auth AuthProvider) {
+	c.config.Auth = auth
+}
+
+// SetTransport 设置自定义Transport
+func (c *Client) SetTransport(transport http.RoundTripper) {
+	c.config.Transport = transport
+	c.client.Transport = c.wrapTransport(transport)
+}
+
+// wrapTransport 为Transport添加响应缓存等中间件
+func (c *Client) wrapTransport(transport http.RoundTripper) http.RoundTripper {
+	if c.cache != nil {
+		transport = &cacheTransport{cache: c.cache, next: transport}
+	}
+	return transport
+}
+
+// InvalidateCache 删除指定URL的缓存响应，url为相对路径时拼接BaseURL
+func (c *Client) InvalidateCache(ctx context.Context, url string) error {
+	if c.cache == nil {
+		return nil
+	}
+	if c.config.BaseURL != "" && strings.HasPrefix(url, "/") {
+		url = c.config.BaseURL + url
+	}
+	return c.cache.store.Delete(ctx, cacheKey(url))
 }
 
 // GetRetryConfig 获取重试配置
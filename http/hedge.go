@@ -0,0 +1,164 @@
+package http
+
+import (
+	"context"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// HedgeConfig 对冲请求配置
+//
+// 请求在延迟分位数（默认p95）内未返回时，再发送一个相同的请求，取先成功的响应。
+// 只对幂等方法（GET、HEAD、OPTIONS）且请求体可重复读取的请求生效。
+type HedgeConfig struct {
+	Percentile   float64       `json:"percentile" yaml:"percentile"`       // 触发对冲的延迟分位数
+	InitialDelay time.Duration `json:"initial_delay" yaml:"initial_delay"` // 样本不足时使用的对冲延迟
+	MinDelay     time.Duration `json:"min_delay" yaml:"min_delay"`         // 对冲延迟下限
+	MaxHedges    int           `json:"max_hedges" yaml:"max_hedges"`       // 每次请求最多额外发送的请求数
+	WindowSize   int           `json:"window_size" yaml:"window_size"`     // 延迟样本窗口大小
+	MinSamples   int           `json:"min_samples" yaml:"min_samples"`     // 开始使用分位数前所需的样本数
+}
+
+// DefaultHedgeConfig 默认对冲请求配置
+func DefaultHedgeConfig() *HedgeConfig {
+	return &HedgeConfig{
+		Percentile:   0.95,
+		InitialDelay: 100 * time.Millisecond,
+		MinDelay:     5 * time.Millisecond,
+		MaxHedges:    1,
+		WindowSize:   100,
+		MinSamples:   20,
+	}
+}
+
+// hedger 记录延迟样本并计算对冲延迟
+type hedger struct {
+	config  *HedgeConfig
+	samples []time.Duration
+	index   int
+	mutex   sync.Mutex
+}
+
+// newHedger 创建对冲器，零值字段使用默认配置
+func newHedger(config *HedgeConfig) *hedger {
+	defaults := DefaultHedgeConfig()
+	cfg := *config
+	if cfg.Percentile <= 0 || cfg.Percentile >= 1 {
+		cfg.Percentile = defaults.Percentile
+	}
+	if cfg.InitialDelay <= 0 {
+		cfg.InitialDelay = defaults.InitialDelay
+	}
+	if cfg.MaxHedges <= 0 {
+		cfg.MaxHedges = defaults.MaxHedges
+	}
+	if cfg.WindowSize <= 0 {
+		cfg.WindowSize = defaults.WindowSize
+	}
+	if cfg.MinSamples <= 0 {
+		cfg.MinSamples = defaults.MinSamples
+	}
+	return &hedger{config: &cfg}
+}
+
+// eligible 判断请求是否可以对冲
+func (h *hedger) eligible(req *Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+	default:
+		return false
+	}
+	_, isReader := req.Body.(io.Reader)
+	return !isReader
+}
+
+// record 记录一次成功请求的延迟
+func (h *hedger) record(latency time.Duration) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if len(h.samples) < h.config.WindowSize {
+		h.samples = append(h.samples, latency)
+		return
+	}
+	h.samples[h.index] = latency
+	h.index = (h.index + 1) % h.config.WindowSize
+}
+
+// delay 计算当前对冲延迟
+func (h *hedger) delay() time.Duration {
+	h.mutex.Lock()
+	if len(h.samples) < h.config.MinSamples {
+		h.mutex.Unlock()
+		return h.config.InitialDelay
+	}
+	sorted := make([]time.Duration, len(h.samples))
+	copy(sorted, h.samples)
+	h.mutex.Unlock()
+
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	index := int(math.Ceil(h.config.Percentile*float64(len(sorted)))) - 1
+	if index < 0 {
+		index = 0
+	}
+	delay := sorted[index]
+	if delay < h.config.MinDelay {
+		delay = h.config.MinDelay
+	}
+	return delay
+}
+
+// hedgeResult 对冲请求结果
+type hedgeResult struct {
+	response *Response
+	err      error
+}
+
+// doHedged 发送请求，超过对冲延迟仍未返回时向其他端点发送副本，返回第一个成功的响应
+func (c *Client) doHedged(ctx context.Context, req *Request, tried map[string]bool) (*Response, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	maxRequests := c.hedger.config.MaxHedges + 1
+	results := make(chan hedgeResult, maxRequests)
+	launched := 0
+	launch := func() {
+		ep := c.pickEndpoint(tried)
+		launched++
+		go func() {
+			response, err := c.doEndpoint(ctx, ep, req)
+			results <- hedgeResult{response: response, err: err}
+		}()
+	}
+
+	launch()
+	timer := time.NewTimer(c.hedger.delay())
+	defer timer.Stop()
+
+	var last hedgeResult
+	for received := 0; received < launched; {
+		select {
+		case result := <-results:
+			received++
+			if result.err == nil && !c.isRetryableStatusCode(result.response.StatusCode) {
+				return result.response, nil
+			}
+			last = result
+			// 失败的请求立即由下一个副本接替
+			if launched < maxRequests {
+				launch()
+			}
+		case <-timer.C:
+			if launched < maxRequests {
+				launch()
+				timer.Reset(c.hedger.delay())
+			}
+		}
+	}
+
+	return last.response, last.err
+}
@@ -0,0 +1,249 @@
+package httpmock
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"unicode/utf8"
+)
+
+// Mode 录制/回放模式
+type Mode int
+
+const (
+	// ModeReplay 只从黄金文件回放，未录制的请求返回错误
+	ModeReplay Mode = iota
+	// ModeRecord 发送真实请求并覆盖录制黄金文件
+	ModeRecord
+	// ModeReplayOrRecord 优先回放，未录制的请求发送真实请求并追加录制
+	ModeReplayOrRecord
+)
+
+// RecordEnv 设置为非空时 ModeFromEnv 返回 ModeRecord
+const RecordEnv = "HTTPMOCK_RECORD"
+
+// ModeFromEnv 根据环境变量 HTTPMOCK_RECORD 选择模式，便于 `HTTPMOCK_RECORD=1 go test` 刷新黄金文件
+func ModeFromEnv() Mode {
+	if os.Getenv(RecordEnv) != "" {
+		return ModeRecord
+	}
+	return ModeReplay
+}
+
+// Interaction 一次录制的请求和响应
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest 录制的请求
+type RecordedRequest struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	Body   string `json:"body,omitempty"`
+	Base64 bool   `json:"base64,omitempty"` // Body 是否为Base64编码
+}
+
+// RecordedResponse 录制的响应
+type RecordedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Headers    http.Header `json:"headers,omitempty"`
+	Body       string      `json:"body,omitempty"`
+	Base64     bool        `json:"base64,omitempty"` // Body 是否为Base64编码
+}
+
+// Recorder 录制/回放 RoundTripper
+//
+// 回放时按 方法+URL+请求体 匹配，同一请求录制多次时按录制顺序依次返回。
+type Recorder struct {
+	path         string
+	mode         Mode
+	real         http.RoundTripper
+	interactions []*Interaction
+	used         map[int]bool
+	dirty        bool
+	mutex        sync.Mutex
+}
+
+// NewRecorder 创建录制/回放 RoundTripper，real 为录制时使用的真实Transport，为nil时使用 http.DefaultTransport
+func NewRecorder(path string, mode Mode, real http.RoundTripper) (*Recorder, error) {
+	if real == nil {
+		real = http.DefaultTransport
+	}
+
+	r := &Recorder{
+		path: path,
+		mode: mode,
+		real: real,
+		used: make(map[int]bool),
+	}
+
+	if mode == ModeRecord {
+		return r, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) && mode == ModeReplayOrRecord {
+			return r, nil
+		}
+		return nil, fmt.Errorf("httpmock: failed to read golden file: %w", err)
+	}
+	if err := json.Unmarshal(data, &r.interactions); err != nil {
+		return nil, fmt.Errorf("httpmock: failed to decode golden file: %w", err)
+	}
+	return r, nil
+}
+
+// RoundTrip 实现 http.RoundTripper
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("httpmock: failed to read request body: %w", err)
+		}
+	}
+	recorded := RecordedRequest{Method: req.Method, URL: req.URL.String()}
+	recorded.Body, recorded.Base64 = encodeBody(body)
+
+	if r.mode != ModeRecord {
+		if interaction := r.lookup(recorded); interaction != nil {
+			return interaction.Response.toResponse(req)
+		}
+		if r.mode == ModeReplay {
+			return nil, fmt.Errorf("httpmock: no recorded interaction for %s %s", req.Method, recorded.URL)
+		}
+	}
+
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	resp, err := r.real.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("httpmock: failed to read response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	interaction := &Interaction{
+		Request: recorded,
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Headers:    resp.Header.Clone(),
+		},
+	}
+	interaction.Response.Body, interaction.Response.Base64 = encodeBody(respBody)
+
+	r.mutex.Lock()
+	r.interactions = append(r.interactions, interaction)
+	r.used[len(r.interactions)-1] = true
+	r.dirty = true
+	r.mutex.Unlock()
+
+	return resp, nil
+}
+
+// Interactions 返回当前所有录制内容
+func (r *Recorder) Interactions() []Interaction {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	result := make([]Interaction, len(r.interactions))
+	for i, interaction := range r.interactions {
+		result[i] = *interaction
+	}
+	return result
+}
+
+// Save 将录制内容写入黄金文件，回放模式或没有新录制时不写入
+func (r *Recorder) Save() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if !r.dirty {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(r.interactions, "", "  ")
+	if err != nil {
+		return fmt.Errorf("httpmock: failed to encode golden file: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		return fmt.Errorf("httpmock: failed to create golden directory: %w", err)
+	}
+	if err := os.WriteFile(r.path, data, 0644); err != nil {
+		return fmt.Errorf("httpmock: failed to write golden file: %w", err)
+	}
+	r.dirty = false
+	return nil
+}
+
+// lookup 查找第一个未被使用的匹配录制
+func (r *Recorder) lookup(req RecordedRequest) *Interaction {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var last *Interaction
+	for i, interaction := range r.interactions {
+		if interaction.Request != req {
+			continue
+		}
+		if !r.used[i] {
+			r.used[i] = true
+			return interaction
+		}
+		last = interaction
+	}
+	// 录制次数不足时重复最后一个匹配的响应
+	return last
+}
+
+// toResponse 构建 http.Response
+func (rr *RecordedResponse) toResponse(req *http.Request) (*http.Response, error) {
+	body, err := decodeBody(rr.Body, rr.Base64)
+	if err != nil {
+		return nil, fmt.Errorf("httpmock: failed to decode recorded body: %w", err)
+	}
+	header := rr.Headers.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", rr.StatusCode, http.StatusText(rr.StatusCode)),
+		StatusCode:    rr.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// encodeBody 文本直接保存，二进制内容使用Base64
+func encodeBody(body []byte) (string, bool) {
+	if utf8.Valid(body) {
+		return string(body), false
+	}
+	return base64.StdEncoding.EncodeToString(body), true
+}
+
+// decodeBody 解码录制内容
+func decodeBody(body string, isBase64 bool) ([]byte, error) {
+	if isBase64 {
+		return base64.StdEncoding.DecodeString(body)
+	}
+	return []byte(body), nil
+}
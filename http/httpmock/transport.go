@@ -0,0 +1,345 @@
+// Package httpmock 提供可编程的 http.RoundTripper，用于在测试中替代真实的 HTTP 服务。
+//
+// Transport 可以直接注入 http.Config.Transport：
+//
+//	mock := httpmock.New()
+//	mock.On("GET", "/users").WithQuery("page", "1").ReplyJSON(200, users)
+//	client := http.New(&http.Config{BaseURL: "http://api.test", Transport: mock})
+//	...
+//	mock.AssertExpectations(t)
+package httpmock
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// TestingT 断言所需的测试接口，*testing.T 满足该接口
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+// Call 一次被记录的请求
+type Call struct {
+	Method  string      `json:"method"`
+	Path    string      `json:"path"`
+	Query   string      `json:"query"`
+	Headers http.Header `json:"headers"`
+	Body    []byte      `json:"body"`
+	Matched bool        `json:"matched"`
+}
+
+// Transport 可编程的 RoundTripper
+type Transport struct {
+	stubs    []*Stub
+	calls    []*Call
+	fallback http.RoundTripper
+	mutex    sync.Mutex
+}
+
+// New 创建模拟Transport
+func New() *Transport {
+	return &Transport{}
+}
+
+// SetFallback 设置未匹配任何桩时使用的Transport，默认返回错误
+func (t *Transport) SetFallback(fallback http.RoundTripper) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.fallback = fallback
+}
+
+// On 注册一个请求桩，path 只匹配路径部分，不含查询参数
+func (t *Transport) On(method, path string) *Stub {
+	stub := &Stub{
+		method:  strings.ToUpper(method),
+		path:    path,
+		query:   make(map[string]string),
+		headers: make(map[string]string),
+		times:   -1,
+	}
+
+	t.mutex.Lock()
+	t.stubs = append(t.stubs, stub)
+	t.mutex.Unlock()
+	return stub
+}
+
+// RoundTrip 实现 http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("httpmock: failed to read request body: %w", err)
+		}
+	}
+
+	call := &Call{
+		Method:  req.Method,
+		Path:    req.URL.Path,
+		Query:   req.URL.RawQuery,
+		Headers: req.Header.Clone(),
+		Body:    body,
+	}
+
+	t.mutex.Lock()
+	t.calls = append(t.calls, call)
+	var matched *Stub
+	for _, stub := range t.stubs {
+		if stub.matches(req, body) {
+			matched = stub
+			break
+		}
+	}
+	var reply *reply
+	if matched != nil {
+		call.Matched = true
+		reply = matched.next()
+	}
+	fallback := t.fallback
+	t.mutex.Unlock()
+
+	if matched == nil {
+		if fallback != nil {
+			req.Body = io.NopCloser(bytes.NewReader(body))
+			return fallback.RoundTrip(req)
+		}
+		return nil, fmt.Errorf("httpmock: no stub matched %s %s", req.Method, req.URL.String())
+	}
+
+	return reply.response(req)
+}
+
+// Calls 返回所有被记录的请求
+func (t *Transport) Calls() []Call {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	calls := make([]Call, len(t.calls))
+	for i, call := range t.calls {
+		calls[i] = *call
+	}
+	return calls
+}
+
+// CallCount 返回指定方法和路径的请求次数
+func (t *Transport) CallCount(method, path string) int {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	count := 0
+	for _, call := range t.calls {
+		if strings.EqualFold(call.Method, method) && call.Path == path {
+			count++
+		}
+	}
+	return count
+}
+
+// AssertExpectations 断言每个设置了 Times 的桩都被调用了期望的次数，且没有未匹配的请求
+func (t *Transport) AssertExpectations(tb TestingT) bool {
+	tb.Helper()
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	ok := true
+	for _, stub := range t.stubs {
+		if stub.times >= 0 && stub.calls != stub.times {
+			tb.Errorf("httpmock: expected %s %s to be called %d times, got %d", stub.method, stub.path, stub.times, stub.calls)
+			ok = false
+		}
+	}
+	for _, call := range t.calls {
+		if !call.Matched && t.fallback == nil {
+			tb.Errorf("httpmock: unexpected request %s %s", call.Method, call.Path)
+			ok = false
+		}
+	}
+	return ok
+}
+
+// Reset 清空所有桩和调用记录
+func (t *Transport) Reset() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.stubs = nil
+	t.calls = nil
+}
+
+// Stub 请求桩，描述匹配条件和依次返回的响应
+type Stub struct {
+	method    string
+	path      string
+	query     map[string]string
+	headers   map[string]string
+	bodyMatch func([]byte) bool
+	replies   []*reply
+	times     int
+	calls     int
+}
+
+// WithQuery 要求查询参数等于指定值
+func (s *Stub) WithQuery(key, value string) *Stub {
+	s.query[key] = value
+	return s
+}
+
+// WithHeader 要求请求头等于指定值
+func (s *Stub) WithHeader(key, value string) *Stub {
+	s.headers[key] = value
+	return s
+}
+
+// WithBody 要求请求体与字符串完全相同
+func (s *Stub) WithBody(body string) *Stub {
+	return s.WithBodyFunc(func(b []byte) bool {
+		return string(b) == body
+	})
+}
+
+// WithBodyJSON 要求请求体与v按JSON语义相等
+func (s *Stub) WithBodyJSON(v interface{}) *Stub {
+	expected, err := normalizeJSON(v)
+	return s.WithBodyFunc(func(b []byte) bool {
+		if err != nil {
+			return false
+		}
+		var actual interface{}
+		if json.Unmarshal(b, &actual) != nil {
+			return false
+		}
+		return reflect.DeepEqual(expected, actual)
+	})
+}
+
+// WithBodyFunc 使用自定义函数匹配请求体
+func (s *Stub) WithBodyFunc(fn func(body []byte) bool) *Stub {
+	s.bodyMatch = fn
+	return s
+}
+
+// Times 期望该桩被调用的次数，用于 AssertExpectations
+func (s *Stub) Times(n int) *Stub {
+	s.times = n
+	return s
+}
+
+// Reply 追加一个响应，多次调用形成响应序列，序列用完后重复最后一个
+func (s *Stub) Reply(status int, body string) *Stub {
+	s.replies = append(s.replies, &reply{status: status, body: []byte(body), headers: make(http.Header)})
+	return s
+}
+
+// ReplyJSON 追加一个JSON响应
+func (s *Stub) ReplyJSON(status int, v interface{}) *Stub {
+	body, err := json.Marshal(v)
+	r := &reply{status: status, body: body, headers: make(http.Header), err: err}
+	r.headers.Set("Content-Type", "application/json")
+	s.replies = append(s.replies, r)
+	return s
+}
+
+// ReplyError 追加一个传输层错误
+func (s *Stub) ReplyError(err error) *Stub {
+	s.replies = append(s.replies, &reply{err: err, headers: make(http.Header)})
+	return s
+}
+
+// ReplyHeader 为最近追加的响应设置响应头
+func (s *Stub) ReplyHeader(key, value string) *Stub {
+	if len(s.replies) == 0 {
+		s.Reply(http.StatusOK, "")
+	}
+	s.replies[len(s.replies)-1].headers.Set(key, value)
+	return s
+}
+
+// matches 判断请求是否匹配
+func (s *Stub) matches(req *http.Request, body []byte) bool {
+	if s.method != "" && s.method != req.Method {
+		return false
+	}
+	if s.path != "" && s.path != req.URL.Path {
+		return false
+	}
+	if s.times >= 0 && s.calls >= s.times {
+		return false
+	}
+
+	query := req.URL.Query()
+	for key, value := range s.query {
+		if query.Get(key) != value {
+			return false
+		}
+	}
+	for key, value := range s.headers {
+		if req.Header.Get(key) != value {
+			return false
+		}
+	}
+	if s.bodyMatch != nil && !s.bodyMatch(body) {
+		return false
+	}
+	return true
+}
+
+// next 返回下一个响应并计数
+func (s *Stub) next() *reply {
+	index := s.calls
+	s.calls++
+	if len(s.replies) == 0 {
+		return &reply{status: http.StatusOK, headers: make(http.Header)}
+	}
+	if index >= len(s.replies) {
+		index = len(s.replies) - 1
+	}
+	return s.replies[index]
+}
+
+// reply 预设响应
+type reply struct {
+	status  int
+	headers http.Header
+	body    []byte
+	err     error
+}
+
+// response 构建 http.Response
+func (r *reply) response(req *http.Request) (*http.Response, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", r.status, http.StatusText(r.status)),
+		StatusCode:    r.status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        r.headers.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(r.body)),
+		ContentLength: int64(len(r.body)),
+		Request:       req,
+	}, nil
+}
+
+// normalizeJSON 将任意值转换为通用JSON结构便于比较
+func normalizeJSON(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var result interface{}
+	err = json.Unmarshal(data, &result)
+	return result, err
+}
@@ -0,0 +1,200 @@
+package httpmock
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	khttp "github.com/daxiong0327/tool-kit/http"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingT 记录断言失败的 TestingT
+type recordingT struct {
+	errors []string
+}
+
+func (r *recordingT) Helper() {}
+
+func (r *recordingT) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, format)
+}
+
+func newClient(transport http.RoundTripper) *khttp.Client {
+	return khttp.New(&khttp.Config{
+		BaseURL:   "http://api.test",
+		Transport: transport,
+		Retry:     &khttp.RetryConfig{MaxRetries: 0},
+	})
+}
+
+func TestTransport(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("match method path and query", func(t *testing.T) {
+		mock := New()
+		mock.On("GET", "/users").WithQuery("page", "2").ReplyJSON(200, map[string]int{"page": 2})
+		mock.On("GET", "/users").Reply(200, "default")
+		client := newClient(mock)
+
+		var result map[string]int
+		require.NoError(t, client.GetJSON(ctx, "/users", &result, khttp.WithQuery("page", "2")))
+		assert.Equal(t, 2, result["page"])
+
+		resp, err := client.Get(ctx, "/users")
+		require.NoError(t, err)
+		assert.Equal(t, "default", resp.Text)
+		assert.Equal(t, 2, mock.CallCount("GET", "/users"))
+	})
+
+	t.Run("match header and JSON body", func(t *testing.T) {
+		mock := New()
+		mock.On("POST", "/orders").
+			WithHeader("X-Tenant", "a").
+			WithBodyJSON(map[string]interface{}{"id": 1, "name": "x"}).
+			Reply(201, "created")
+		client := newClient(mock)
+
+		resp, err := client.Post(ctx, "/orders", map[string]interface{}{"name": "x", "id": 1}, khttp.WithHeader("X-Tenant", "a"))
+		require.NoError(t, err)
+		assert.Equal(t, 201, resp.StatusCode)
+
+		_, err = client.Post(ctx, "/orders", map[string]interface{}{"id": 2}, khttp.WithHeader("X-Tenant", "a"))
+		assert.Error(t, err)
+	})
+
+	t.Run("sequence responses", func(t *testing.T) {
+		mock := New()
+		mock.On("GET", "/flaky").Reply(503, "down").Reply(200, "up").ReplyHeader("X-Attempt", "2")
+		client := khttp.New(&khttp.Config{
+			BaseURL:   "http://api.test",
+			Transport: mock,
+			Retry:     &khttp.RetryConfig{MaxRetries: 2, RetryableCodes: []int{503}},
+		})
+
+		resp, err := client.Get(ctx, "/flaky")
+		require.NoError(t, err)
+		assert.Equal(t, 200, resp.StatusCode)
+		assert.Equal(t, "2", resp.Headers["X-Attempt"])
+
+		resp, err = client.Get(ctx, "/flaky")
+		require.NoError(t, err)
+		assert.Equal(t, "up", resp.Text)
+	})
+
+	t.Run("transport error", func(t *testing.T) {
+		mock := New()
+		mock.On("GET", "/broken").ReplyError(errors.New("boom"))
+
+		_, err := newClient(mock).Get(ctx, "/broken")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "boom")
+	})
+
+	t.Run("header after transport error", func(t *testing.T) {
+		mock := New()
+		assert.NotPanics(t, func() {
+			mock.On("GET", "/broken").ReplyError(errors.New("boom")).ReplyHeader("X-Trace", "1")
+		})
+	})
+
+	t.Run("assert call counts", func(t *testing.T) {
+		mock := New()
+		mock.On("DELETE", "/users/1").Times(1).Reply(204, "")
+		client := newClient(mock)
+
+		rt := &recordingT{}
+		assert.False(t, mock.AssertExpectations(rt))
+
+		_, err := client.Delete(ctx, "/users/1")
+		require.NoError(t, err)
+		assert.True(t, mock.AssertExpectations(&recordingT{}))
+
+		_, err = client.Delete(ctx, "/users/1")
+		assert.Error(t, err)
+		assert.False(t, mock.AssertExpectations(&recordingT{}))
+	})
+
+	t.Run("fallback", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("real"))
+		}))
+		defer server.Close()
+
+		mock := New()
+		mock.SetFallback(http.DefaultTransport)
+		client := khttp.New(&khttp.Config{BaseURL: server.URL, Transport: mock})
+
+		resp, err := client.Get(ctx, "/")
+		require.NoError(t, err)
+		assert.Equal(t, "real", resp.Text)
+	})
+}
+
+func TestRecorder(t *testing.T) {
+	var hits int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt64(&hits, 1)
+		w.Header().Set("X-Hit", string(rune('0'+n)))
+		if r.Method == http.MethodPost {
+			w.Write([]byte{0xff, 0xfe, byte(n)})
+			return
+		}
+		w.Write([]byte("hello " + r.URL.Query().Get("name")))
+	}))
+	defer server.Close()
+
+	golden := filepath.Join(t.TempDir(), "testdata", "interactions.json")
+	ctx := context.Background()
+
+	// 录制
+	recorder, err := NewRecorder(golden, ModeRecord, nil)
+	require.NoError(t, err)
+	client := khttp.New(&khttp.Config{BaseURL: server.URL, Transport: recorder})
+
+	resp, err := client.Get(ctx, "/greet", khttp.WithQuery("name", "a"))
+	require.NoError(t, err)
+	assert.Equal(t, "hello a", resp.Text)
+	_, err = client.Post(ctx, "/bin", "payload")
+	require.NoError(t, err)
+	_, err = client.Post(ctx, "/bin", "payload")
+	require.NoError(t, err)
+	require.NoError(t, recorder.Save())
+	assert.Equal(t, int64(3), atomic.LoadInt64(&hits))
+
+	// 回放，不访问真实服务
+	server.Close()
+	replay, err := NewRecorder(golden, ModeReplay, nil)
+	require.NoError(t, err)
+	client = khttp.New(&khttp.Config{BaseURL: server.URL, Transport: replay, Retry: &khttp.RetryConfig{}})
+
+	resp, err = client.Get(ctx, "/greet", khttp.WithQuery("name", "a"))
+	require.NoError(t, err)
+	assert.Equal(t, "hello a", resp.Text)
+	assert.Equal(t, "1", resp.Headers["X-Hit"])
+
+	first, err := client.Post(ctx, "/bin", "payload")
+	require.NoError(t, err)
+	second, err := client.Post(ctx, "/bin", "payload")
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0xff, 0xfe, 2}, first.Body)
+	assert.Equal(t, []byte{0xff, 0xfe, 3}, second.Body)
+
+	_, err = client.Get(ctx, "/greet", khttp.WithQuery("name", "b"))
+	assert.Error(t, err)
+	assert.Equal(t, int64(3), atomic.LoadInt64(&hits))
+}
+
+func TestRecorderMissingGolden(t *testing.T) {
+	_, err := NewRecorder(filepath.Join(t.TempDir(), "missing.json"), ModeReplay, nil)
+	assert.Error(t, err)
+
+	recorder, err := NewRecorder(filepath.Join(t.TempDir(), "missing.json"), ModeReplayOrRecord, nil)
+	require.NoError(t, err)
+	assert.Empty(t, recorder.Interactions())
+}
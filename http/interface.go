@@ -0,0 +1,33 @@
+package http
+
+import (
+	"context"
+)
+
+// Doer 发送请求的最小接口
+type Doer interface {
+	Do(ctx context.Context, req *Request, options ...Option) (*Response, error)
+}
+
+// HTTPClient HTTP客户端接口，Client 和 SimpleClient 均实现该接口，
+// 其他包和测试替身应依赖该接口而不是具体类型
+type HTTPClient interface {
+	Doer
+
+	Get(ctx context.Context, url string, options ...Option) (*Response, error)
+	Post(ctx context.Context, url string, body interface{}, options ...Option) (*Response, error)
+	Put(ctx context.Context, url string, body interface{}, options ...Option) (*Response, error)
+	Delete(ctx context.Context, url string, options ...Option) (*Response, error)
+	Patch(ctx context.Context, url string, body interface{}, options ...Option) (*Response, error)
+
+	GetJSON(ctx context.Context, url string, result interface{}, options ...Option) error
+	PostJSON(ctx context.Context, url string, body interface{}, result interface{}, options ...Option) error
+	PutJSON(ctx context.Context, url string, body interface{}, result interface{}, options ...Option) error
+	DeleteJSON(ctx context.Context, url string, result interface{}, options ...Option) error
+	PatchJSON(ctx context.Context, url string, body interface{}, result interface{}, options ...Option) error
+}
+
+var (
+	_ HTTPClient = (*Client)(nil)
+	_ HTTPClient = (*SimpleClient)(nil)
+)
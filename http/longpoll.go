@@ -0,0 +1,85 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrStopPolling 处理函数返回该错误时停止长轮询，LongPoll返回nil
+var ErrStopPolling = errors.New("stop polling")
+
+// LongPollHandler 长轮询响应处理函数
+//
+// resp为nil表示本轮轮询在PollTimeout内未返回（服务端挂起后无数据），
+// 处理函数可以修改req（例如更新游标参数）供下一轮使用。
+type LongPollHandler func(req *Request, resp *Response) error
+
+// LongPollConfig 长轮询配置
+type LongPollConfig struct {
+	Interval    time.Duration `json:"interval" yaml:"interval"`         // 两次轮询之间的间隔
+	PollTimeout time.Duration `json:"poll_timeout" yaml:"poll_timeout"` // 单次轮询的超时时间，0表示只受ctx控制
+}
+
+// DefaultLongPollConfig 默认长轮询配置
+func DefaultLongPollConfig() *LongPollConfig {
+	return &LongPollConfig{
+		Interval:    0,
+		PollTimeout: 60 * time.Second,
+	}
+}
+
+// LongPoll 循环发送请求并将响应交给处理函数，直到ctx取消、处理函数返回错误或请求最终失败
+//
+// 单次轮询不受Config.Timeout限制，失败时复用客户端的重试与退避配置；
+// 重试耗尽后仍失败或返回可重试状态码时，LongPoll返回错误。
+func (c *Client) LongPoll(ctx context.Context, req *Request, handler LongPollHandler, config *LongPollConfig) error {
+	if config == nil {
+		config = DefaultLongPollConfig()
+	}
+
+	for {
+		resp, err := c.poll(ctx, req, config.PollTimeout)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			return err
+		}
+		if resp != nil && c.isRetryableStatusCode(resp.StatusCode) {
+			return fmt.Errorf("long poll failed: HTTP %d: %s", resp.StatusCode, resp.Text)
+		}
+
+		if err := handler(req, resp); err != nil {
+			if errors.Is(err, ErrStopPolling) {
+				return nil
+			}
+			return err
+		}
+
+		if config.Interval > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(config.Interval):
+			}
+		}
+	}
+}
+
+// poll 执行一轮轮询，单次超时到期而ctx仍有效时返回nil响应
+func (c *Client) poll(ctx context.Context, req *Request, timeout time.Duration) (*Response, error) {
+	pollCtx := withoutClientTimeout(ctx)
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		pollCtx, cancel = context.WithTimeout(pollCtx, timeout)
+		defer cancel()
+	}
+
+	resp, err := c.Do(pollCtx, req)
+	if err != nil && ctx.Err() == nil && pollCtx.Err() == context.DeadlineExceeded {
+		return nil, nil
+	}
+	return resp, err
+}
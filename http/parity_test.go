@@ -0,0 +1,143 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runClientSuite 对 HTTPClient 的实现运行相同的行为测试
+func runClientSuite(t *testing.T, newClient func(config *Config) HTTPClient) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.URL.Path == "/error" {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte("internal server error"))
+			return
+		}
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Echo-Method", r.Method)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"method":  r.Method,
+			"path":    r.URL.Path,
+			"query":   r.URL.Query().Get("key"),
+			"default": r.Header.Get("X-Default"),
+			"custom":  r.Header.Get("X-Custom"),
+			"body":    string(body),
+		})
+	}))
+	defer server.Close()
+
+	client := newClient(&Config{
+		BaseURL: server.URL,
+		Headers: map[string]string{"X-Default": "d"},
+		Retry: &RetryConfig{
+			MaxRetries: 1,
+			BaseDelay:  10 * time.Millisecond,
+			MaxDelay:   10 * time.Millisecond,
+			Strategy:   RetryStrategyFixed,
+		},
+	})
+	ctx := context.Background()
+
+	t.Run("methods", func(t *testing.T) {
+		calls := map[string]func() (*Response, error){
+			http.MethodGet:    func() (*Response, error) { return client.Get(ctx, "/echo") },
+			http.MethodPost:   func() (*Response, error) { return client.Post(ctx, "/echo", "x") },
+			http.MethodPut:    func() (*Response, error) { return client.Put(ctx, "/echo", "x") },
+			http.MethodDelete: func() (*Response, error) { return client.Delete(ctx, "/echo") },
+			http.MethodPatch:  func() (*Response, error) { return client.Patch(ctx, "/echo", "x") },
+		}
+		for method, call := range calls {
+			resp, err := call()
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Equal(t, method, resp.Headers["X-Echo-Method"])
+		}
+	})
+
+	t.Run("Do with options", func(t *testing.T) {
+		var result map[string]string
+		resp, err := client.Do(ctx, &Request{Method: http.MethodGet, URL: "/echo"},
+			WithQuery("key", "v"), WithHeader("X-Custom", "c"))
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(resp.Body, &result))
+		assert.Equal(t, "v", result["query"])
+		assert.Equal(t, "c", result["custom"])
+		assert.Equal(t, "d", result["default"])
+	})
+
+	t.Run("JSON helpers", func(t *testing.T) {
+		var result map[string]string
+		require.NoError(t, client.PostJSON(ctx, "/echo", map[string]int{"a": 1}, &result))
+		assert.Equal(t, `{"a":1}`, result["body"])
+
+		require.NoError(t, client.GetJSON(ctx, "/echo", &result))
+		assert.Equal(t, http.MethodGet, result["method"])
+		require.NoError(t, client.PutJSON(ctx, "/echo", "raw", &result))
+		assert.Equal(t, "raw", result["body"])
+		require.NoError(t, client.PatchJSON(ctx, "/echo", []byte("bytes"), &result))
+		assert.Equal(t, "bytes", result["body"])
+		require.NoError(t, client.DeleteJSON(ctx, "/echo", &result))
+		assert.Equal(t, http.MethodDelete, result["method"])
+	})
+
+	t.Run("error status returns response", func(t *testing.T) {
+		resp, err := client.Get(ctx, "/error")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		assert.Equal(t, "internal server error", resp.Text)
+
+		resp, err = client.Get(ctx, "/missing")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("cancelled context", func(t *testing.T) {
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		_, err := client.Get(cancelled, "/echo")
+		assert.Error(t, err)
+	})
+}
+
+func TestClientParity(t *testing.T) {
+	t.Run("Client", func(t *testing.T) {
+		runClientSuite(t, func(config *Config) HTTPClient { return New(config) })
+	})
+
+	t.Run("SimpleClient", func(t *testing.T) {
+		runClientSuite(t, func(config *Config) HTTPClient { return NewSimpleClient(config) })
+	})
+}
+
+func TestSimpleClientNoRetry(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	config := &Config{BaseURL: server.URL, Retry: DefaultRetryConfig()}
+	client := NewSimpleClient(config)
+
+	resp, err := client.Get(context.Background(), "/")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, 1, attempts)
+	// 调用方的配置不应被修改
+	assert.Equal(t, 3, config.Retry.MaxRetries)
+}
@@ -0,0 +1,301 @@
+package http
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Event Server-Sent Events 事件
+type Event struct {
+	ID    string        `json:"id"`    // 事件ID
+	Event string        `json:"event"` // 事件类型，默认为message
+	Data  string        `json:"data"`  // 事件数据，多行data以换行符连接
+	Retry time.Duration `json:"retry"` // 服务端建议的重连间隔
+}
+
+// SSEConfig SSE客户端配置
+type SSEConfig struct {
+	ReconnectDelay time.Duration `json:"reconnect_delay" yaml:"reconnect_delay"` // 默认重连间隔，服务端retry字段会覆盖该值
+	MaxReconnects  int           `json:"max_reconnects" yaml:"max_reconnects"`   // 连续重连失败的最大次数，0表示不限制
+	BufferSize     int           `json:"buffer_size" yaml:"buffer_size"`         // 事件通道缓冲大小
+	LastEventID    string        `json:"last_event_id" yaml:"last_event_id"`     // 初始Last-Event-ID，用于从指定事件恢复
+}
+
+// DefaultSSEConfig 默认SSE配置
+func DefaultSSEConfig() *SSEConfig {
+	return &SSEConfig{
+		ReconnectDelay: 3 * time.Second,
+		MaxReconnects:  0,
+		BufferSize:     16,
+	}
+}
+
+// EventStream SSE事件流，断线后携带Last-Event-ID自动重连
+type EventStream struct {
+	events      chan *Event
+	cancel      context.CancelFunc
+	done        chan struct{}
+	err         error
+	lastEventID string
+	mutex       sync.RWMutex
+}
+
+// Events 返回事件通道，事件流结束时通道关闭
+func (s *EventStream) Events() <-chan *Event {
+	return s.events
+}
+
+// Err 返回事件流结束的原因，应在事件通道关闭后调用
+func (s *EventStream) Err() error {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.err
+}
+
+// LastEventID 返回最后收到的事件ID
+func (s *EventStream) LastEventID() string {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.lastEventID
+}
+
+// Close 关闭事件流并等待后台协程退出
+func (s *EventStream) Close() {
+	s.cancel()
+	<-s.done
+}
+
+// SSE 订阅Server-Sent Events，事件通过通道投递，ctx取消或调用Close时结束
+func (c *Client) SSE(ctx context.Context, url string, config *SSEConfig, options ...Option) *EventStream {
+	if config == nil {
+		config = DefaultSSEConfig()
+	}
+	bufferSize := config.BufferSize
+	if bufferSize <= 0 {
+		bufferSize = DefaultSSEConfig().BufferSize
+	}
+
+	req := &Request{Method: http.MethodGet, URL: url}
+	for _, option := range options {
+		option(req)
+	}
+
+	ctx, cancel := context.WithCancel(withoutClientTimeout(ctx))
+	stream := &EventStream{
+		events:      make(chan *Event, bufferSize),
+		cancel:      cancel,
+		done:        make(chan struct{}),
+		lastEventID: config.LastEventID,
+	}
+
+	go func() {
+		defer close(stream.done)
+		defer close(stream.events)
+		err := c.runSSE(ctx, req, config, stream)
+		stream.mutex.Lock()
+		stream.err = err
+		stream.mutex.Unlock()
+	}()
+
+	return stream
+}
+
+// runSSE 连接、读取并在断线后重连，直到上下文取消或重连次数耗尽
+func (c *Client) runSSE(ctx context.Context, req *Request, config *SSEConfig, stream *EventStream) error {
+	delay := config.ReconnectDelay
+	if delay <= 0 {
+		delay = DefaultSSEConfig().ReconnectDelay
+	}
+
+	failures := 0
+	for {
+		received, retry, err := c.readSSE(ctx, req, stream)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err == errSSEStop {
+			return nil
+		}
+		if retry > 0 {
+			delay = retry
+		}
+
+		if received {
+			failures = 0
+		} else {
+			failures++
+			if config.MaxReconnects > 0 && failures > config.MaxReconnects {
+				if err == nil {
+					err = io.ErrUnexpectedEOF
+				}
+				return fmt.Errorf("sse reconnect failed after %d attempts: %w", failures, err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// errSSEStop 服务端返回204，要求客户端停止重连
+var errSSEStop = fmt.Errorf("sse stream stopped by server")
+
+// readSSE 建立一次连接并读取事件，返回是否收到过事件以及服务端建议的重连间隔
+func (c *Client) readSSE(ctx context.Context, req *Request, stream *EventStream) (bool, time.Duration, error) {
+	baseURL := c.config.BaseURL
+	if ep := c.pickEndpoint(make(map[string]bool)); ep != nil {
+		baseURL = ep.url
+	}
+
+	httpReq, err := c.buildRequest(ctx, baseURL, req)
+	if err != nil {
+		return false, 0, err
+	}
+	httpReq.Header.Set("Accept", "text/event-stream")
+	httpReq.Header.Set("Cache-Control", "no-cache")
+	if id := stream.LastEventID(); id != "" {
+		httpReq.Header.Set("Last-Event-ID", id)
+	}
+
+	resp, err := c.httpClient(ctx).Do(httpReq)
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to connect event stream: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNoContent:
+		return false, 0, errSSEStop
+	case resp.StatusCode == http.StatusUnauthorized && c.config.Auth != nil:
+		c.config.Auth.Invalidate()
+		return false, 0, fmt.Errorf("event stream returned HTTP %d", resp.StatusCode)
+	case resp.StatusCode != http.StatusOK:
+		return false, 0, fmt.Errorf("event stream returned HTTP %d", resp.StatusCode)
+	}
+
+	// 新连接沿用已有的事件ID，未带id的事件不应清空它
+	parser := newSSEParser(resp.Body, stream.LastEventID())
+	received := false
+	var retry time.Duration
+	for {
+		event, err := parser.next()
+		if parser.retry > 0 {
+			retry = parser.retry
+		}
+		if err != nil {
+			if err == io.EOF {
+				err = nil
+			}
+			return received, retry, err
+		}
+
+		stream.mutex.Lock()
+		stream.lastEventID = parser.lastEventID
+		stream.mutex.Unlock()
+
+		if event == nil {
+			continue
+		}
+		received = true
+		select {
+		case stream.events <- event:
+		case <-ctx.Done():
+			return received, retry, ctx.Err()
+		}
+	}
+}
+
+// sseParser SSE协议解析器
+type sseParser struct {
+	reader      *bufio.Reader
+	lastEventID string
+	retry       time.Duration
+}
+
+// newSSEParser 创建解析器，lastEventID为初始的事件ID
+func newSSEParser(r io.Reader, lastEventID string) *sseParser {
+	return &sseParser{reader: bufio.NewReader(r), lastEventID: lastEventID}
+}
+
+// next 读取下一个事件，遇到空行时派发；没有data的事件块返回nil事件
+func (p *sseParser) next() (*Event, error) {
+	var data strings.Builder
+	hasData := false
+	eventType := ""
+	seenField := false
+
+	for {
+		line, err := p.reader.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		if line == "" {
+			if !seenField {
+				if err == io.EOF {
+					return nil, io.EOF
+				}
+				continue
+			}
+			if !hasData {
+				return nil, nil
+			}
+			if eventType == "" {
+				eventType = "message"
+			}
+			return &Event{
+				ID:    p.lastEventID,
+				Event: eventType,
+				Data:  data.String(),
+				Retry: p.retry,
+			}, nil
+		}
+
+		// 注释行
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+		seenField = true
+
+		field, value := line, ""
+		if i := strings.IndexByte(line, ':'); i >= 0 {
+			field, value = line[:i], line[i+1:]
+			value = strings.TrimPrefix(value, " ")
+		}
+
+		switch field {
+		case "event":
+			eventType = value
+		case "data":
+			if hasData {
+				data.WriteByte('\n')
+			}
+			data.WriteString(value)
+			hasData = true
+		case "id":
+			if !strings.ContainsRune(value, 0) {
+				p.lastEventID = value
+			}
+		case "retry":
+			if ms, err := strconv.Atoi(value); err == nil && ms >= 0 {
+				p.retry = time.Duration(ms) * time.Millisecond
+			}
+		}
+
+		// 流在事件未以空行结束时断开，丢弃不完整的事件
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+	}
+}
@@ -0,0 +1,338 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// collectEvents 从事件流读取n个事件
+func collectEvents(t *testing.T, stream *EventStream, n int) []*Event {
+	t.Helper()
+	var events []*Event
+	timeout := time.After(2 * time.Second)
+	for len(events) < n {
+		select {
+		case event, ok := <-stream.Events():
+			if !ok {
+				t.Fatalf("stream closed after %d events: %v", len(events), stream.Err())
+			}
+			events = append(events, event)
+		case <-timeout:
+			t.Fatalf("timed out after %d events", len(events))
+		}
+	}
+	return events
+}
+
+func TestSSEParser(t *testing.T) {
+	input := ": comment\n" +
+		"data: hello\n\n" +
+		"event: update\n" +
+		"id: 1\n" +
+		"data: line1\n" +
+		"data: line2\n\n" +
+		"id: 2\n\n" +
+		"retry: 1500\r\n" +
+		"data:no-space\r\n\r\n" +
+		"data: incomplete"
+
+	parser := newSSEParser(strings.NewReader(input), "")
+
+	event, err := parser.next()
+	require.NoError(t, err)
+	assert.Equal(t, &Event{Event: "message", Data: "hello"}, event)
+
+	event, err = parser.next()
+	require.NoError(t, err)
+	assert.Equal(t, &Event{ID: "1", Event: "update", Data: "line1\nline2"}, event)
+
+	// 只有id没有data的事件块不派发，但会更新Last-Event-ID
+	event, err = parser.next()
+	require.NoError(t, err)
+	assert.Nil(t, event)
+	assert.Equal(t, "2", parser.lastEventID)
+
+	event, err = parser.next()
+	require.NoError(t, err)
+	assert.Equal(t, &Event{ID: "2", Event: "message", Data: "no-space", Retry: 1500 * time.Millisecond}, event)
+
+	// 未以空行结束的事件被丢弃
+	event, err = parser.next()
+	assert.Nil(t, event)
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestSSE(t *testing.T) {
+	t.Run("receive events", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "text/event-stream", r.Header.Get("Accept"))
+			assert.Equal(t, "token", r.Header.Get("X-Token"))
+			w.Header().Set("Content-Type", "text/event-stream")
+			for i := 1; i <= 3; i++ {
+				fmt.Fprintf(w, "id: %d\ndata: event-%d\n\n", i, i)
+				w.(http.Flusher).Flush()
+			}
+			<-r.Context().Done()
+		}))
+		defer server.Close()
+
+		client := New(&Config{BaseURL: server.URL, Timeout: 50 * time.Millisecond})
+		stream := client.SSE(context.Background(), "/events", nil, WithHeader("X-Token", "token"))
+		defer stream.Close()
+
+		// 流式请求不受Config.Timeout限制
+		time.Sleep(100 * time.Millisecond)
+		events := collectEvents(t, stream, 3)
+		assert.Equal(t, "event-1", events[0].Data)
+		assert.Equal(t, "event-3", events[2].Data)
+		assert.Equal(t, "3", stream.LastEventID())
+	})
+
+	t.Run("reconnect with last event id", func(t *testing.T) {
+		var connections int64
+		var mutex sync.Mutex
+		var lastIDs []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			n := atomic.AddInt64(&connections, 1)
+			mutex.Lock()
+			lastIDs = append(lastIDs, r.Header.Get("Last-Event-ID"))
+			mutex.Unlock()
+
+			start := 1
+			if id := r.Header.Get("Last-Event-ID"); id != "" {
+				last, _ := strconv.Atoi(id)
+				start = last + 1
+			}
+			fmt.Fprint(w, "retry: 10\n\n")
+			for i := start; i < start+2; i++ {
+				fmt.Fprintf(w, "id: %d\ndata: %d\n\n", i, i)
+			}
+			if n >= 3 {
+				w.(http.Flusher).Flush()
+				<-r.Context().Done()
+			}
+		}))
+		defer server.Close()
+
+		client := New(&Config{BaseURL: server.URL})
+		stream := client.SSE(context.Background(), "/", &SSEConfig{ReconnectDelay: time.Minute})
+		defer stream.Close()
+
+		events := collectEvents(t, stream, 6)
+		for i, event := range events {
+			assert.Equal(t, strconv.Itoa(i+1), event.Data)
+		}
+		mutex.Lock()
+		assert.Equal(t, []string{"", "2", "4"}, lastIDs)
+		mutex.Unlock()
+	})
+
+	t.Run("event without id keeps last event id across reconnects", func(t *testing.T) {
+		var connections int64
+		var mutex sync.Mutex
+		var lastIDs []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			n := atomic.AddInt64(&connections, 1)
+			mutex.Lock()
+			lastIDs = append(lastIDs, r.Header.Get("Last-Event-ID"))
+			mutex.Unlock()
+
+			fmt.Fprint(w, "retry: 10\n\n")
+			switch n {
+			case 1:
+				fmt.Fprint(w, "id: 6\ndata: a\n\n")
+			case 2:
+				fmt.Fprint(w, "data: b\n\n")
+			default:
+				w.(http.Flusher).Flush()
+				<-r.Context().Done()
+			}
+		}))
+		defer server.Close()
+
+		client := New(&Config{BaseURL: server.URL})
+		stream := client.SSE(context.Background(), "/", &SSEConfig{LastEventID: "5", ReconnectDelay: time.Minute})
+		defer stream.Close()
+
+		events := collectEvents(t, stream, 2)
+		assert.Equal(t, "6", events[0].ID)
+		assert.Equal(t, "b", events[1].Data)
+		assert.Equal(t, "6", events[1].ID)
+
+		require.Eventually(t, func() bool {
+			return atomic.LoadInt64(&connections) >= 3
+		}, 2*time.Second, 10*time.Millisecond)
+		mutex.Lock()
+		assert.Equal(t, []string{"5", "6", "6"}, lastIDs)
+		mutex.Unlock()
+		assert.Equal(t, "6", stream.LastEventID())
+	})
+
+	t.Run("initial last event id applies to first event", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, "data: first\n\n")
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		}))
+		defer server.Close()
+
+		client := New(&Config{BaseURL: server.URL})
+		stream := client.SSE(context.Background(), "/", &SSEConfig{LastEventID: "41"})
+		defer stream.Close()
+
+		events := collectEvents(t, stream, 1)
+		assert.Equal(t, "41", events[0].ID)
+	})
+
+	t.Run("context cancellation closes stream", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		}))
+		defer server.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		client := New(&Config{BaseURL: server.URL})
+		stream := client.SSE(ctx, "/", nil)
+		cancel()
+
+		select {
+		case _, ok := <-stream.Events():
+			assert.False(t, ok)
+		case <-time.After(2 * time.Second):
+			t.Fatal("stream not closed")
+		}
+		assert.ErrorIs(t, stream.Err(), context.Canceled)
+	})
+
+	t.Run("no content stops reconnecting", func(t *testing.T) {
+		server, count := newCountingServer(http.StatusNoContent, 0, "")
+		defer server.Close()
+
+		client := New(&Config{BaseURL: server.URL})
+		stream := client.SSE(context.Background(), "/", &SSEConfig{ReconnectDelay: time.Millisecond})
+		_, ok := <-stream.Events()
+		assert.False(t, ok)
+		assert.NoError(t, stream.Err())
+		assert.Equal(t, int64(1), atomic.LoadInt64(count))
+	})
+
+	t.Run("max reconnects", func(t *testing.T) {
+		server, count := newCountingServer(http.StatusBadGateway, 0, "")
+		defer server.Close()
+
+		client := New(&Config{BaseURL: server.URL})
+		stream := client.SSE(context.Background(), "/", &SSEConfig{ReconnectDelay: time.Millisecond, MaxReconnects: 2})
+		_, ok := <-stream.Events()
+		assert.False(t, ok)
+		assert.Error(t, stream.Err())
+		assert.Equal(t, int64(3), atomic.LoadInt64(count))
+	})
+}
+
+func TestLongPoll(t *testing.T) {
+	t.Run("cursor and stop", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			cursor, _ := strconv.Atoi(r.URL.Query().Get("cursor"))
+			fmt.Fprint(w, cursor+1)
+		}))
+		defer server.Close()
+
+		client := New(&Config{BaseURL: server.URL})
+		req := &Request{Method: http.MethodGet, URL: "/poll", Query: map[string]string{"cursor": "0"}}
+		var seen []string
+		err := client.LongPoll(context.Background(), req, func(req *Request, resp *Response) error {
+			seen = append(seen, resp.Text)
+			if len(seen) == 3 {
+				return ErrStopPolling
+			}
+			req.Query["cursor"] = resp.Text
+			return nil
+		}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"1", "2", "3"}, seen)
+	})
+
+	t.Run("poll timeout yields empty poll", func(t *testing.T) {
+		var calls int64
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt64(&calls, 1) == 1 {
+				<-r.Context().Done()
+				return
+			}
+			fmt.Fprint(w, "data")
+		}))
+		defer server.Close()
+
+		client := New(&Config{BaseURL: server.URL, Retry: fastRetry(0)})
+		var results []*Response
+		err := client.LongPoll(context.Background(), &Request{Method: http.MethodGet, URL: "/"}, func(req *Request, resp *Response) error {
+			results = append(results, resp)
+			if resp != nil {
+				return ErrStopPolling
+			}
+			return nil
+		}, &LongPollConfig{PollTimeout: 50 * time.Millisecond})
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		assert.Nil(t, results[0])
+		assert.Equal(t, "data", results[1].Text)
+	})
+
+	t.Run("retries with client config", func(t *testing.T) {
+		var calls int64
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt64(&calls, 1) <= 2 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			fmt.Fprint(w, "ok")
+		}))
+		defer server.Close()
+
+		client := New(&Config{BaseURL: server.URL, Retry: fastRetry(2)})
+		err := client.LongPoll(context.Background(), &Request{Method: http.MethodGet, URL: "/"}, func(req *Request, resp *Response) error {
+			assert.Equal(t, "ok", resp.Text)
+			return ErrStopPolling
+		}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), atomic.LoadInt64(&calls))
+	})
+
+	t.Run("fails when retries exhausted", func(t *testing.T) {
+		server, count := newCountingServer(http.StatusServiceUnavailable, 0, "down")
+		defer server.Close()
+
+		client := New(&Config{BaseURL: server.URL, Retry: fastRetry(1)})
+		err := client.LongPoll(context.Background(), &Request{Method: http.MethodGet, URL: "/"}, func(req *Request, resp *Response) error {
+			t.Fatal("handler should not be called")
+			return nil
+		}, nil)
+		assert.Error(t, err)
+		assert.Equal(t, int64(2), atomic.LoadInt64(count))
+	})
+
+	t.Run("handler error", func(t *testing.T) {
+		server, _ := newCountingServer(http.StatusOK, 0, "ok")
+		defer server.Close()
+
+		client := New(&Config{BaseURL: server.URL})
+		handlerErr := fmt.Errorf("boom")
+		err := client.LongPoll(context.Background(), &Request{Method: http.MethodGet, URL: "/"}, func(req *Request, resp *Response) error {
+			return handlerErr
+		}, nil)
+		assert.Equal(t, handlerErr, err)
+	})
+}
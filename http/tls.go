@@ -0,0 +1,335 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TLSConfig TLS配置
+//
+// 证书和CA文件按 ReloadInterval 检查内容是否变化，轮换后关闭空闲连接并在新连接上使用新证书，无需重建客户端。
+type TLSConfig struct {
+	CAFile         string        `json:"ca_file" yaml:"ca_file"`                 // CA证书包路径（PEM），为空时使用系统根证书
+	CertFile       string        `json:"cert_file" yaml:"cert_file"`             // 客户端证书路径（PEM），用于双向TLS
+	KeyFile        string        `json:"key_file" yaml:"key_file"`               // 客户端私钥路径（PEM）
+	MinVersion     string        `json:"min_version" yaml:"min_version"`         // 最低TLS版本：1.0、1.1、1.2、1.3，默认1.2
+	CipherSuites   []string      `json:"cipher_suites" yaml:"cipher_suites"`     // 允许的加密套件名称，例如TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256
+	ServerName     string        `json:"server_name" yaml:"server_name"`         // 覆盖SNI及证书校验使用的主机名
+	ReloadInterval time.Duration `json:"reload_interval" yaml:"reload_interval"` // 检查证书文件变化的最小间隔，默认10秒，负数表示不重新加载
+}
+
+// DefaultTLSReloadInterval 默认证书检查间隔
+const DefaultTLSReloadInterval = 10 * time.Second
+
+// tlsVersions TLS版本名称
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// parseTLSVersion 解析TLS版本，为空时返回TLS 1.2
+func parseTLSVersion(version string) (uint16, error) {
+	if version == "" {
+		return tls.VersionTLS12, nil
+	}
+	v, ok := tlsVersions[strings.TrimPrefix(strings.ToLower(version), "tls")]
+	if !ok {
+		return 0, fmt.Errorf("unsupported TLS version: %s", version)
+	}
+	return v, nil
+}
+
+// parseCipherSuites 将加密套件名称转换为ID
+func parseCipherSuites(names []string) ([]uint16, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	known := make(map[string]uint16)
+	for _, suite := range tls.CipherSuites() {
+		known[suite.Name] = suite.ID
+	}
+	for _, suite := range tls.InsecureCipherSuites() {
+		known[suite.Name] = suite.ID
+	}
+
+	ids := make([]uint16, 0, len(names))
+	for _, name := range names {
+		id, ok := known[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown cipher suite: %s", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// tlsReloader 加载并按需重新加载证书文件
+type tlsReloader struct {
+	config   *TLSConfig
+	interval time.Duration
+	onReload func()
+
+	mutex     sync.RWMutex
+	cert      *tls.Certificate
+	roots     *x509.CertPool
+	certPEM   []byte
+	keyPEM    []byte
+	caPEM     []byte
+	lastCheck time.Time
+}
+
+// newTLSReloader 创建证书加载器并立即加载一次
+func newTLSReloader(config *TLSConfig) (*tlsReloader, error) {
+	interval := config.ReloadInterval
+	if interval == 0 {
+		interval = DefaultTLSReloadInterval
+	}
+	r := &tlsReloader{config: config, interval: interval}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// reload 读取证书文件，内容未变化时不做处理；加载失败时保留原证书
+func (r *tlsReloader) reload() error {
+	r.mutex.Lock()
+	r.lastCheck = time.Now()
+	r.mutex.Unlock()
+
+	var certPEM, keyPEM, caPEM []byte
+	var err error
+	if r.config.CertFile != "" || r.config.KeyFile != "" {
+		if certPEM, err = os.ReadFile(r.config.CertFile); err != nil {
+			return fmt.Errorf("failed to read client certificate: %w", err)
+		}
+		if keyPEM, err = os.ReadFile(r.config.KeyFile); err != nil {
+			return fmt.Errorf("failed to read client key: %w", err)
+		}
+	}
+	if r.config.CAFile != "" {
+		if caPEM, err = os.ReadFile(r.config.CAFile); err != nil {
+			return fmt.Errorf("failed to read CA file: %w", err)
+		}
+	}
+
+	r.mutex.Lock()
+	rotated := false
+	if certPEM != nil && (!bytes.Equal(certPEM, r.certPEM) || !bytes.Equal(keyPEM, r.keyPEM)) {
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			r.mutex.Unlock()
+			return fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		rotated = rotated || r.cert != nil
+		r.cert, r.certPEM, r.keyPEM = &cert, certPEM, keyPEM
+	}
+	if caPEM != nil && !bytes.Equal(caPEM, r.caPEM) {
+		roots := x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(caPEM) {
+			r.mutex.Unlock()
+			return fmt.Errorf("no valid certificates in CA file: %s", r.config.CAFile)
+		}
+		rotated = rotated || r.roots != nil
+		r.roots, r.caPEM = roots, caPEM
+	}
+	r.mutex.Unlock()
+
+	// 已建立的连接仍使用旧证书，关闭空闲连接使新证书尽快生效
+	if rotated && r.onReload != nil {
+		r.onReload()
+	}
+	return nil
+}
+
+// maybeReload 距上次检查超过间隔时重新加载
+func (r *tlsReloader) maybeReload() {
+	if r.interval < 0 {
+		return
+	}
+	r.mutex.RLock()
+	due := time.Since(r.lastCheck) >= r.interval
+	r.mutex.RUnlock()
+	if due {
+		// 加载失败时继续使用旧证书，下次检查时再试
+		_ = r.reload()
+	}
+}
+
+// getClientCertificate 返回当前客户端证书
+func (r *tlsReloader) getClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	r.maybeReload()
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	if r.cert == nil {
+		return &tls.Certificate{}, nil
+	}
+	return r.cert, nil
+}
+
+// verifyConnection 使用当前CA校验服务端证书链，主机名取自握手状态。
+// 主机为IP地址时握手状态中没有主机名，此时无法校验证书，直接拒绝连接；
+// 直连时由 dialTLSContext 传入实际拨号的主机，只有经代理的连接会走到这里
+func (r *tlsReloader) verifyConnection(state tls.ConnectionState) error {
+	serverName := state.ServerName
+	if r.config.ServerName != "" {
+		serverName = r.config.ServerName
+	}
+	return r.verify(state, serverName)
+}
+
+// verify 使用当前CA校验服务端证书链及主机名，serverName为空时拒绝连接
+func (r *tlsReloader) verify(state tls.ConnectionState, serverName string) error {
+	if serverName == "" {
+		return fmt.Errorf("tls: no server name to verify the certificate against, set TLSConfig.ServerName")
+	}
+	r.maybeReload()
+	r.mutex.RLock()
+	roots := r.roots
+	r.mutex.RUnlock()
+
+	if len(state.PeerCertificates) == 0 {
+		return fmt.Errorf("tls: server did not provide a certificate")
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range state.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := state.PeerCertificates[0].Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		DNSName:       serverName,
+	})
+	return err
+}
+
+// dialTLSContext 返回直连时使用的TLS拨号函数，按拨号地址中的主机校验证书（包括IP地址主机）
+func (r *tlsReloader) dialTLSContext(base *tls.Config) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		config := base.Clone()
+		if config.ServerName == "" {
+			config.ServerName = host
+		}
+		serverName := config.ServerName
+		config.VerifyConnection = func(state tls.ConnectionState) error {
+			return r.verify(state, serverName)
+		}
+
+		rawConn, err := dialer.DialContext(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		conn := tls.Client(rawConn, config)
+		if err := conn.HandshakeContext(ctx); err != nil {
+			rawConn.Close()
+			return nil, err
+		}
+		return conn, nil
+	}
+}
+
+// buildTLSConfig 根据配置创建tls.Config，返回的加载器在未配置证书文件时为nil
+func buildTLSConfig(config *TLSConfig, insecure bool) (*tls.Config, *tlsReloader, error) {
+	if config == nil {
+		if !insecure {
+			return nil, nil, nil
+		}
+		return &tls.Config{InsecureSkipVerify: true}, nil, nil
+	}
+
+	minVersion, err := parseTLSVersion(config.MinVersion)
+	if err != nil {
+		return nil, nil, err
+	}
+	cipherSuites, err := parseCipherSuites(config.CipherSuites)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         minVersion,
+		CipherSuites:       cipherSuites,
+		ServerName:         config.ServerName,
+		InsecureSkipVerify: insecure,
+	}
+	if config.CAFile == "" && config.CertFile == "" && config.KeyFile == "" {
+		return tlsConfig, nil, nil
+	}
+
+	reloader, err := newTLSReloader(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	if config.CertFile != "" {
+		tlsConfig.GetClientCertificate = reloader.getClientCertificate
+	}
+	if config.CAFile != "" && !insecure {
+		// 由verifyConnection使用可轮换的CA校验，跳过标准库基于固定RootCAs的校验
+		tlsConfig.InsecureSkipVerify = true
+		tlsConfig.VerifyConnection = reloader.verifyConnection
+	}
+	return tlsConfig, reloader, nil
+}
+
+// errorTransport 配置无效时返回固定错误的Transport
+type errorTransport struct {
+	err error
+}
+
+// RoundTrip 实现 http.RoundTripper 接口
+func (t *errorTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, t.err
+}
+
+// reloadingTransport 在每次请求前检查证书文件是否轮换，长连接复用时也能及时切换证书
+type reloadingTransport struct {
+	*http.Transport
+	reloader *tlsReloader
+}
+
+// RoundTrip 实现 http.RoundTripper 接口
+func (t *reloadingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.reloader.maybeReload()
+	return t.Transport.RoundTrip(req)
+}
+
+// newTransport 根据连接池和TLS配置创建Transport
+func newTransport(config *Config) (http.RoundTripper, error) {
+	tlsConfig, reloader, err := buildTLSConfig(config.TLS, config.Insecure)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tls config: %w", err)
+	}
+
+	transport := &http.Transport{
+		MaxIdleConns:        config.Pool.MaxIdleConns,
+		MaxIdleConnsPerHost: config.Pool.MaxIdleConnsPerHost,
+		MaxConnsPerHost:     config.Pool.MaxConnsPerHost,
+		IdleConnTimeout:     config.Pool.IdleConnTimeout,
+		DisableKeepAlives:   config.Pool.DisableKeepAlives,
+		TLSClientConfig:     tlsConfig,
+	}
+	if reloader == nil {
+		return transport, nil
+	}
+	if tlsConfig.VerifyConnection != nil {
+		transport.DialTLSContext = reloader.dialTLSContext(tlsConfig)
+	}
+	reloader.onReload = transport.CloseIdleConnections
+	return &reloadingTransport{Transport: transport, reloader: reloader}, nil
+}
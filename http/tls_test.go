@@ -0,0 +1,309 @@
+package http
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testCA 测试用证书颁发机构
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+// newTestCA 生成自签名CA
+func newTestCA(t *testing.T, name string) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue 签发证书，返回证书和私钥的PEM，证书包含 127.0.0.1 的IP SAN
+func (ca *testCA) issue(t *testing.T, commonName string, usage x509.ExtKeyUsage, dnsNames ...string) ([]byte, []byte) {
+	t.Helper()
+	return ca.issueFor(t, commonName, usage, dnsNames, []net.IP{net.ParseIP("127.0.0.1")})
+}
+
+// issueFor 签发指定DNS名称和IP地址的证书
+func (ca *testCA) issueFor(t *testing.T, commonName string, usage x509.ExtKeyUsage, dnsNames []string, ips []net.IP) ([]byte, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		DNSNames:     dnsNames,
+		IPAddresses:  ips,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+// writeFile 写入临时文件并返回路径
+func writeFile(t *testing.T, dir, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, data, 0600))
+	return path
+}
+
+// newMTLSServer 创建要求客户端证书的服务器，响应体为客户端证书的CN
+func newMTLSServer(t *testing.T, ca *testCA) *httptest.Server {
+	t.Helper()
+	certPEM, keyPEM := ca.issue(t, "server", x509.ExtKeyUsageServerAuth, "localhost", "api.internal")
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	require.NoError(t, err)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(ca.cert)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Server-Name", r.TLS.ServerName)
+		w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+	}))
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+	}
+	server.StartTLS()
+	return server
+}
+
+func TestTLSConfig(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("custom CA", func(t *testing.T) {
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("ok"))
+		}))
+		defer server.Close()
+
+		caFile := writeFile(t, t.TempDir(), "ca.pem", pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+
+		_, err := New(&Config{BaseURL: server.URL, Retry: fastRetry(0)}).Get(ctx, "/")
+		assert.Error(t, err)
+
+		client := New(&Config{BaseURL: server.URL, Retry: fastRetry(0), TLS: &TLSConfig{CAFile: caFile}})
+		resp, err := client.Get(ctx, "/")
+		require.NoError(t, err)
+		assert.Equal(t, "ok", resp.Text)
+	})
+
+	t.Run("custom CA rejects certificate for another host on IP address", func(t *testing.T) {
+		ca := newTestCA(t, "test-ca")
+		certPEM, keyPEM := ca.issueFor(t, "server", x509.ExtKeyUsageServerAuth, []string{"other.example"}, nil)
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		require.NoError(t, err)
+		server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("ok"))
+		}))
+		server.TLS = &tls.Config{Certificates: []tls.Certificate{cert}}
+		server.StartTLS()
+		defer server.Close()
+
+		caFile := writeFile(t, t.TempDir(), "ca.pem", ca.pem)
+		_, err = New(&Config{BaseURL: server.URL, Retry: fastRetry(0), TLS: &TLSConfig{CAFile: caFile}}).Get(ctx, "/")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "127.0.0.1")
+
+		// 显式指定证书中的主机名时可以连接
+		client := New(&Config{BaseURL: server.URL, Retry: fastRetry(0), TLS: &TLSConfig{CAFile: caFile, ServerName: "other.example"}})
+		resp, err := client.Get(ctx, "/")
+		require.NoError(t, err)
+		assert.Equal(t, "ok", resp.Text)
+	})
+
+	t.Run("insecure", func(t *testing.T) {
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("ok"))
+		}))
+		defer server.Close()
+
+		client := New(&Config{BaseURL: server.URL, Retry: fastRetry(0), Insecure: true})
+		_, err := client.Get(ctx, "/")
+		require.NoError(t, err)
+	})
+
+	t.Run("mutual TLS with SNI override", func(t *testing.T) {
+		ca := newTestCA(t, "test-ca")
+		server := newMTLSServer(t, ca)
+		defer server.Close()
+
+		dir := t.TempDir()
+		certPEM, keyPEM := ca.issue(t, "client-a", x509.ExtKeyUsageClientAuth)
+		tlsConfig := &TLSConfig{
+			CAFile:     writeFile(t, dir, "ca.pem", ca.pem),
+			CertFile:   writeFile(t, dir, "client.pem", certPEM),
+			KeyFile:    writeFile(t, dir, "client.key", keyPEM),
+			ServerName: "api.internal",
+		}
+
+		client := New(&Config{BaseURL: server.URL, Retry: fastRetry(0), TLS: tlsConfig})
+		resp, err := client.Get(ctx, "/")
+		require.NoError(t, err)
+		assert.Equal(t, "client-a", resp.Text)
+		assert.Equal(t, "api.internal", resp.Headers["X-Server-Name"])
+
+		// 没有客户端证书时握手失败
+		noCert := New(&Config{BaseURL: server.URL, Retry: fastRetry(0), TLS: &TLSConfig{CAFile: tlsConfig.CAFile}})
+		_, err = noCert.Get(ctx, "/")
+		assert.Error(t, err)
+
+		// SNI与证书不匹配时校验失败
+		wrongName := *tlsConfig
+		wrongName.ServerName = "other.internal"
+		_, err = New(&Config{BaseURL: server.URL, Retry: fastRetry(0), TLS: &wrongName}).Get(ctx, "/")
+		assert.Error(t, err)
+	})
+
+	t.Run("client certificate rotation", func(t *testing.T) {
+		ca := newTestCA(t, "test-ca")
+		server := newMTLSServer(t, ca)
+		defer server.Close()
+
+		dir := t.TempDir()
+		certPEM, keyPEM := ca.issue(t, "client-v1", x509.ExtKeyUsageClientAuth)
+		tlsConfig := &TLSConfig{
+			CAFile:         writeFile(t, dir, "ca.pem", ca.pem),
+			CertFile:       writeFile(t, dir, "client.pem", certPEM),
+			KeyFile:        writeFile(t, dir, "client.key", keyPEM),
+			ReloadInterval: time.Millisecond,
+		}
+		client := New(&Config{BaseURL: server.URL, Retry: fastRetry(0), TLS: tlsConfig})
+
+		resp, err := client.Get(ctx, "/")
+		require.NoError(t, err)
+		assert.Equal(t, "client-v1", resp.Text)
+
+		certPEM, keyPEM = ca.issue(t, "client-v2", x509.ExtKeyUsageClientAuth)
+		writeFile(t, dir, "client.pem", certPEM)
+		writeFile(t, dir, "client.key", keyPEM)
+		time.Sleep(5 * time.Millisecond)
+
+		resp, err = client.Get(ctx, "/")
+		require.NoError(t, err)
+		assert.Equal(t, "client-v2", resp.Text)
+	})
+
+	t.Run("invalid rotation keeps previous certificate", func(t *testing.T) {
+		ca := newTestCA(t, "test-ca")
+		server := newMTLSServer(t, ca)
+		defer server.Close()
+
+		dir := t.TempDir()
+		certPEM, keyPEM := ca.issue(t, "client-v1", x509.ExtKeyUsageClientAuth)
+		tlsConfig := &TLSConfig{
+			CAFile:         writeFile(t, dir, "ca.pem", ca.pem),
+			CertFile:       writeFile(t, dir, "client.pem", certPEM),
+			KeyFile:        writeFile(t, dir, "client.key", keyPEM),
+			ReloadInterval: time.Millisecond,
+		}
+		client := New(&Config{BaseURL: server.URL, Retry: fastRetry(0), Pool: &PoolConfig{DisableKeepAlives: true}, TLS: tlsConfig})
+
+		writeFile(t, dir, "client.pem", []byte("garbage"))
+		time.Sleep(5 * time.Millisecond)
+
+		resp, err := client.Get(ctx, "/")
+		require.NoError(t, err)
+		assert.Equal(t, "client-v1", resp.Text)
+	})
+
+	t.Run("CA rotation", func(t *testing.T) {
+		ca := newTestCA(t, "test-ca")
+		server := newMTLSServer(t, ca)
+		defer server.Close()
+
+		dir := t.TempDir()
+		certPEM, keyPEM := ca.issue(t, "client", x509.ExtKeyUsageClientAuth)
+		tlsConfig := &TLSConfig{
+			CAFile:         writeFile(t, dir, "ca.pem", newTestCA(t, "other-ca").pem),
+			CertFile:       writeFile(t, dir, "client.pem", certPEM),
+			KeyFile:        writeFile(t, dir, "client.key", keyPEM),
+			ReloadInterval: time.Millisecond,
+		}
+		client := New(&Config{BaseURL: server.URL, Retry: fastRetry(0), TLS: tlsConfig})
+
+		_, err := client.Get(ctx, "/")
+		assert.Error(t, err)
+
+		writeFile(t, dir, "ca.pem", ca.pem)
+		time.Sleep(5 * time.Millisecond)
+
+		resp, err := client.Get(ctx, "/")
+		require.NoError(t, err)
+		assert.Equal(t, "client", resp.Text)
+	})
+
+	t.Run("min version and cipher suites", func(t *testing.T) {
+		server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(tls.CipherSuiteName(r.TLS.CipherSuite)))
+		}))
+		server.TLS = &tls.Config{MaxVersion: tls.VersionTLS12}
+		server.StartTLS()
+		defer server.Close()
+
+		caFile := writeFile(t, t.TempDir(), "ca.pem", pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+
+		_, err := New(&Config{BaseURL: server.URL, Retry: fastRetry(0), TLS: &TLSConfig{CAFile: caFile, MinVersion: "1.3"}}).Get(ctx, "/")
+		assert.Error(t, err)
+
+		suite := "TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384"
+		client := New(&Config{BaseURL: server.URL, Retry: fastRetry(0), TLS: &TLSConfig{CAFile: caFile, CipherSuites: []string{suite}}})
+		resp, err := client.Get(ctx, "/")
+		require.NoError(t, err)
+		assert.Equal(t, suite, resp.Text)
+	})
+
+	t.Run("invalid config", func(t *testing.T) {
+		client := New(&Config{BaseURL: "https://127.0.0.1:1", Retry: fastRetry(0), TLS: &TLSConfig{MinVersion: "2.0"}})
+		_, err := client.Get(ctx, "/")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid tls config")
+
+		err = client.SetTLSConfig(&TLSConfig{CipherSuites: []string{"TLS_NOPE"}})
+		assert.Error(t, err)
+		err = client.SetTLSConfig(&TLSConfig{CAFile: filepath.Join(t.TempDir(), "missing.pem")})
+		assert.Error(t, err)
+		assert.NoError(t, client.SetTLSConfig(&TLSConfig{MinVersion: "1.3"}))
+	})
+}
@@ -0,0 +1,145 @@
+package redis
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// ErrAlertChannelFull 告警通道已满，告警被丢弃
+var ErrAlertChannelFull = errors.New("redis: alert channel full")
+
+// AlertStatus 告警状态
+type AlertStatus string
+
+const (
+	// AlertFiring 条件满足，告警触发
+	AlertFiring AlertStatus = "firing"
+	// AlertResolved 条件不再满足，告警恢复
+	AlertResolved AlertStatus = "resolved"
+)
+
+// Alert 告警通知内容
+type Alert struct {
+	Rule       string        `json:"rule"`        // 规则名称
+	ScriptName string        `json:"script_name"` // 脚本名称
+	Status     AlertStatus   `json:"status"`      // 告警状态
+	Condition  string        `json:"condition"`   // 告警条件
+	Operator   string        `json:"operator"`    // 比较运算符
+	Threshold  float64       `json:"threshold"`   // 阈值
+	Value      float64       `json:"value"`       // 当前值
+	Window     time.Duration `json:"window"`      // 统计时间窗口，0表示全部历史
+	StartsAt   time.Time     `json:"starts_at"`   // 开始触发的时间
+	Time       time.Time     `json:"time"`        // 通知时间
+	Message    string        `json:"message"`     // 可读的告警描述
+}
+
+// AlertNotifier 告警通知器
+type AlertNotifier interface {
+	Notify(ctx context.Context, alert *Alert) error
+}
+
+// WebhookNotifier 以JSON格式POST告警到指定地址
+type WebhookNotifier struct {
+	URL     string            // 接收地址
+	Headers map[string]string // 额外的请求头，例如认证信息
+	Client  *http.Client      // HTTP客户端
+}
+
+// NewWebhookNotifier 创建Webhook通知器
+func NewWebhookNotifier(url string) *WebhookNotifier {
+	return &WebhookNotifier{
+		URL:     url,
+		Headers: make(map[string]string),
+		Client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Notify 发送告警，非2xx响应视为失败
+func (n *WebhookNotifier) Notify(ctx context.Context, alert *Alert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return fmt.Errorf("failed to encode alert: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range n.Headers {
+		req.Header.Set(key, value)
+	}
+
+	client := n.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// LoggerNotifier 将告警写入日志，触发时使用Warn级别，恢复时使用Info级别
+type LoggerNotifier struct {
+	logger Logger
+}
+
+// NewLoggerNotifier 创建日志通知器，logger为nil时使用默认日志
+func NewLoggerNotifier(logger Logger) *LoggerNotifier {
+	if logger == nil {
+		logger = &DefaultLogger{}
+	}
+	return &LoggerNotifier{logger: logger}
+}
+
+// Notify 写入告警日志
+func (n *LoggerNotifier) Notify(ctx context.Context, alert *Alert) error {
+	if alert.Status == AlertResolved {
+		n.logger.Infof("ALERT RESOLVED: %s", alert.Message)
+	} else {
+		n.logger.Warnf("ALERT: %s", alert.Message)
+	}
+	return nil
+}
+
+// ChannelNotifier 将告警发送到通道，由调用方自行处理
+type ChannelNotifier struct {
+	ch chan *Alert
+}
+
+// NewChannelNotifier 创建通道通知器，size为通道缓冲大小
+func NewChannelNotifier(size int) *ChannelNotifier {
+	if size < 0 {
+		size = 0
+	}
+	return &ChannelNotifier{ch: make(chan *Alert, size)}
+}
+
+// C 返回接收告警的通道
+func (n *ChannelNotifier) C() <-chan *Alert {
+	return n.ch
+}
+
+// Notify 发送告警，通道已满时不阻塞监控，返回 ErrAlertChannelFull
+func (n *ChannelNotifier) Notify(ctx context.Context, alert *Alert) error {
+	select {
+	case n.ch <- alert:
+		return nil
+	default:
+		return ErrAlertChannelFull
+	}
+}
@@ -0,0 +1,354 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// 布隆过滤器使用的键，均以 {name} 为哈希标签：
+//
+//	meta    过滤器参数、子过滤器数量 filters 和各子过滤器的元素数 count:<i>
+//	<i>     第i个子过滤器的位图
+//
+// 可扩展布隆过滤器：元素写入最后一个子过滤器，子过滤器写满后创建容量为上一个 Growth 倍、
+// 误判率为上一个 TighteningRatio 倍的新子过滤器，查询时检查所有子过滤器。
+// 脚本访问的子过滤器键都通过KEYS传入：调用方按已知的子过滤器数量传入所有键，写入时多传一个用于扩容，
+// 脚本回复的第一个元素为当前的子过滤器数量，数量变化或批量写入超出传入的键时只处理部分元素，由调用方按新数量重试
+
+// bloomParamsLua 计算第i个子过滤器的容量、位数和哈希函数个数，与 bloomFilterParams 保持一致
+// KEYS: meta、子过滤器0、子过滤器1...；ARGV: 容量、误判率、扩容倍数、误判率收紧比例、调用方已知的子过滤器数量，之后每两个为一个元素的哈希值
+const bloomParamsLua = `
+local meta = KEYS[1]
+local capacity = tonumber(redis.call('HGET', meta, 'capacity') or ARGV[1])
+local errorRate = tonumber(redis.call('HGET', meta, 'error_rate') or ARGV[2])
+local growth = tonumber(redis.call('HGET', meta, 'growth') or ARGV[3])
+local ratio = tonumber(redis.call('HGET', meta, 'ratio') or ARGV[4])
+local filters = tonumber(redis.call('HGET', meta, 'filters') or '0')
+local declared = #KEYS - 1
+
+local function params(i)
+    local cap = math.floor(capacity * growth ^ i)
+    local p = errorRate * ratio ^ i
+    local bits = math.ceil(-cap * math.log(p) / (math.log(2) ^ 2))
+    local hashes = math.ceil(bits / cap * math.log(2))
+    return cap, bits, hashes
+end
+
+local function contains(i, h1, h2)
+    local _, bits, hashes = params(i)
+    for j = 0, hashes - 1 do
+        if redis.call('GETBIT', KEYS[i + 2], (h1 + j * h2) % bits) == 0 then
+            return false
+        end
+    end
+    return true
+end
+
+local function exists(h1, h2)
+    for i = filters - 1, 0, -1 do
+        if contains(i, h1, h2) then
+            return true
+        end
+    end
+    return false
+end
+`
+
+// bloomAddScript 添加元素，返回子过滤器数量和每个已处理元素是否为新元素（1新增，0可能已存在）
+var bloomAddScript = redis.NewScript(bloomParamsLua + `
+if filters ~= tonumber(ARGV[5]) then
+    return {filters}
+end
+redis.call('HSETNX', meta, 'capacity', ARGV[1])
+redis.call('HSETNX', meta, 'error_rate', ARGV[2])
+redis.call('HSETNX', meta, 'growth', ARGV[3])
+redis.call('HSETNX', meta, 'ratio', ARGV[4])
+
+local results = {0}
+for x = 6, #ARGV, 2 do
+    if filters == 0 then
+        filters = 1
+        redis.call('HSET', meta, 'filters', filters)
+    end
+    local current = filters - 1
+    if current >= declared then
+        -- 扩容后的子过滤器键未传入，剩余元素由调用方重试
+        break
+    end
+    local h1, h2 = tonumber(ARGV[x]), tonumber(ARGV[x + 1])
+    if exists(h1, h2) then
+        table.insert(results, 0)
+    else
+        local cap, bits, hashes = params(current)
+        if bits > 4294967295 then
+            return redis.error_reply('bloom filter exceeds the maximum size of a string')
+        end
+        for j = 0, hashes - 1 do
+            redis.call('SETBIT', KEYS[current + 2], (h1 + j * h2) % bits, 1)
+        end
+        if redis.call('HINCRBY', meta, 'count:' .. current, 1) >= cap then
+            filters = filters + 1
+            redis.call('HSET', meta, 'filters', filters)
+        end
+        table.insert(results, 1)
+    end
+end
+results[1] = filters
+return results`)
+
+// bloomExistsScript 查询元素是否可能存在，返回子过滤器数量和每个元素的结果（1可能存在，0一定不存在）
+var bloomExistsScript = redis.NewScript(bloomParamsLua + `
+if filters ~= tonumber(ARGV[5]) then
+    return {filters}
+end
+local results = {filters}
+for x = 6, #ARGV, 2 do
+    if exists(tonumber(ARGV[x]), tonumber(ARGV[x + 1])) then
+        table.insert(results, 1)
+    else
+        table.insert(results, 0)
+    end
+end
+return results`)
+
+// bloomMaxRetries 子过滤器数量连续变化时的最大重试次数
+const bloomMaxRetries = 10
+
+// ErrBloomFilterContention 并发扩容导致子过滤器数量持续变化，重试次数耗尽
+var ErrBloomFilterContention = errors.New("redis: bloom filter resized concurrently, retries exhausted")
+
+// BloomConfig 布隆过滤器配置，创建后参数保存在Redis中，之后修改配置对已有过滤器无效
+type BloomConfig struct {
+	Capacity        int64   `json:"capacity" yaml:"capacity"`                 // 预计元素数量，即第一个子过滤器的容量
+	ErrorRate       float64 `json:"error_rate" yaml:"error_rate"`             // 期望的总误判率
+	Growth          float64 `json:"growth" yaml:"growth"`                     // 扩容时新子过滤器的容量倍数
+	TighteningRatio float64 `json:"tightening_ratio" yaml:"tightening_ratio"` // 新子过滤器误判率相对上一个的比例，保证总误判率不超过 ErrorRate
+}
+
+// DefaultBloomConfig 默认布隆过滤器配置
+func DefaultBloomConfig() *BloomConfig {
+	return &BloomConfig{
+		Capacity:        10000,
+		ErrorRate:       0.01,
+		Growth:          2,
+		TighteningRatio: 0.5,
+	}
+}
+
+// BloomFilter 基于位图和Lua脚本的可扩展布隆过滤器，不依赖 RedisBloom 模块。
+// 判断为不存在的元素一定不存在，判断为存在的元素有 ErrorRate 左右的概率误判，不支持删除元素
+type BloomFilter struct {
+	client  redis.UniversalClient
+	prefix  keyPrefix
+	name    string
+	config  *BloomConfig
+	filters atomic.Int64 // 最近一次得知的子过滤器数量，只用于选择传入脚本的键
+}
+
+// NewBloomFilter 创建布隆过滤器，config为nil时使用默认配置
+func (c *Client) NewBloomFilter(name string, config *BloomConfig) (*BloomFilter, error) {
+	if config == nil {
+		config = DefaultBloomConfig()
+	}
+	cfg := *config
+	defaults := DefaultBloomConfig()
+	if cfg.Capacity <= 0 {
+		cfg.Capacity = defaults.Capacity
+	}
+	if cfg.ErrorRate == 0 {
+		cfg.ErrorRate = defaults.ErrorRate
+	}
+	if cfg.Growth == 0 {
+		cfg.Growth = defaults.Growth
+	}
+	if cfg.TighteningRatio == 0 {
+		cfg.TighteningRatio = defaults.TighteningRatio
+	}
+	if cfg.ErrorRate <= 0 || cfg.ErrorRate >= 1 {
+		return nil, fmt.Errorf("bloom filter error rate must be in (0, 1), got %v", cfg.ErrorRate)
+	}
+	if cfg.Growth < 1 {
+		return nil, fmt.Errorf("bloom filter growth must be at least 1, got %v", cfg.Growth)
+	}
+	if cfg.TighteningRatio <= 0 || cfg.TighteningRatio >= 1 {
+		return nil, fmt.Errorf("bloom filter tightening ratio must be in (0, 1), got %v", cfg.TighteningRatio)
+	}
+	return &BloomFilter{client: c.client, prefix: c.prefix, name: name, config: &cfg}, nil
+}
+
+// key 返回布隆过滤器的键
+func (b *BloomFilter) key(suffix string) string {
+	return b.prefix.key("{" + b.name + "}:" + suffix)
+}
+
+// keys 返回脚本访问的键：meta和前n个子过滤器
+func (b *BloomFilter) keys(n int64) []string {
+	keys := make([]string, 0, n+1)
+	keys = append(keys, b.key("meta"))
+	for i := int64(0); i < n; i++ {
+		keys = append(keys, b.key(strconv.FormatInt(i, 10)))
+	}
+	return keys
+}
+
+// args 返回脚本参数，第一个子过滤器的误判率为 ErrorRate*(1-TighteningRatio)，
+// 各子过滤器误判率之和收敛于 ErrorRate
+func (b *BloomFilter) args(filters int64, items []string) []interface{} {
+	args := make([]interface{}, 0, 5+len(items)*2)
+	args = append(args,
+		b.config.Capacity,
+		b.config.ErrorRate*(1-b.config.TighteningRatio),
+		b.config.Growth,
+		b.config.TighteningRatio,
+		filters,
+	)
+	for _, item := range items {
+		h1, h2 := bloomHash(item)
+		args = append(args, h1, h2)
+	}
+	return args
+}
+
+// bloomHash 计算元素的两个32位哈希值，子过滤器使用 h1 + j*h2 生成第j个位置
+func bloomHash(item string) (uint32, uint32) {
+	h := fnv.New64a()
+	h.Write([]byte(item))
+	x := h.Sum64()
+	// splitmix64 混合，改善FNV低位的分布
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return uint32(x), uint32(x>>32) | 1
+}
+
+// Add 添加元素，元素之前不存在时返回true；误判时可能对新元素返回false
+func (b *BloomFilter) Add(ctx context.Context, item string) (bool, error) {
+	results, err := b.AddMany(ctx, item)
+	if err != nil {
+		return false, err
+	}
+	return results[0], nil
+}
+
+// AddMany 批量添加元素，返回每个元素是否为新元素
+func (b *BloomFilter) AddMany(ctx context.Context, items ...string) ([]bool, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+	return b.run(ctx, bloomAddScript, items, 1)
+}
+
+// Exists 判断元素是否可能存在，返回false时元素一定不存在
+func (b *BloomFilter) Exists(ctx context.Context, item string) (bool, error) {
+	results, err := b.ExistsMany(ctx, item)
+	if err != nil {
+		return false, err
+	}
+	return results[0], nil
+}
+
+// ExistsMany 批量判断元素是否可能存在
+func (b *BloomFilter) ExistsMany(ctx context.Context, items ...string) ([]bool, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+	return b.run(ctx, bloomExistsScript, items, 0)
+}
+
+// run 执行脚本并转换结果，子过滤器数量与已知的不一致或批量写入需要更多子过滤器时按新数量重试。
+// spare为写入时额外传入的子过滤器键数量
+func (b *BloomFilter) run(ctx context.Context, script *redis.Script, items []string, spare int64) ([]bool, error) {
+	results := make([]bool, 0, len(items))
+	filters := b.filters.Load()
+	for retries := 0; len(results) < len(items); {
+		values, err := script.Run(ctx, b.client, b.keys(filters+spare), b.args(filters, items[len(results):])...).Int64Slice()
+		if err != nil {
+			return nil, err
+		}
+		if len(values) == 0 || len(values)-1 > len(items)-len(results) {
+			return nil, errors.New("unexpected bloom filter reply length")
+		}
+		filters = values[0]
+		b.filters.Store(filters)
+		if len(values) == 1 {
+			// 没有处理任何元素，说明子过滤器数量已被其他客户端改变
+			if retries++; retries > bloomMaxRetries {
+				return nil, ErrBloomFilterContention
+			}
+			continue
+		}
+		retries = 0
+		for _, v := range values[1:] {
+			results = append(results, v == 1)
+		}
+	}
+	return results, nil
+}
+
+// BloomInfo 布隆过滤器状态
+type BloomInfo struct {
+	Filters   int     `json:"filters"`    // 子过滤器数量
+	Count     int64   `json:"count"`      // 已添加的元素数量
+	Capacity  int64   `json:"capacity"`   // 当前所有子过滤器的总容量
+	Bits      int64   `json:"bits"`       // 占用的总位数
+	ErrorRate float64 `json:"error_rate"` // 按当前子过滤器数量计算的误判率上限
+}
+
+// Info 返回布隆过滤器状态，过滤器不存在时返回零值
+func (b *BloomFilter) Info(ctx context.Context) (*BloomInfo, error) {
+	meta, err := b.client.HGetAll(ctx, b.key("meta")).Result()
+	if err != nil {
+		return nil, err
+	}
+	info := &BloomInfo{}
+	if len(meta) == 0 {
+		return info, nil
+	}
+
+	capacity, _ := strconv.ParseFloat(meta["capacity"], 64)
+	errorRate, _ := strconv.ParseFloat(meta["error_rate"], 64)
+	growth, _ := strconv.ParseFloat(meta["growth"], 64)
+	ratio, _ := strconv.ParseFloat(meta["ratio"], 64)
+	info.Filters, _ = strconv.Atoi(meta["filters"])
+
+	for i := 0; i < info.Filters; i++ {
+		count, _ := strconv.ParseInt(meta["count:"+strconv.Itoa(i)], 10, 64)
+		filterCapacity, bits, _ := bloomFilterParams(capacity, errorRate, growth, ratio, i)
+		info.Count += count
+		info.Capacity += filterCapacity
+		info.Bits += bits
+		info.ErrorRate += errorRate * math.Pow(ratio, float64(i))
+	}
+	return info, nil
+}
+
+// Delete 删除布隆过滤器
+func (b *BloomFilter) Delete(ctx context.Context) error {
+	filters, err := b.client.HGet(ctx, b.key("meta"), "filters").Int()
+	if err != nil && err != redis.Nil {
+		return err
+	}
+	if err := b.client.Del(ctx, b.keys(int64(filters))...).Err(); err != nil {
+		return err
+	}
+	b.filters.Store(0)
+	return nil
+}
+
+// bloomFilterParams 计算第i个子过滤器的容量、位数和哈希函数个数
+func bloomFilterParams(capacity, errorRate, growth, ratio float64, i int) (int64, int64, int64) {
+	filterCapacity := math.Floor(capacity * math.Pow(growth, float64(i)))
+	p := errorRate * math.Pow(ratio, float64(i))
+	bits := math.Ceil(-filterCapacity * math.Log(p) / (math.Ln2 * math.Ln2))
+	hashes := math.Ceil(bits / filterCapacity * math.Ln2)
+	return int64(filterCapacity), int64(bits), int64(hashes)
+}
@@ -0,0 +1,201 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// scriptKeysHook 记录脚本调用传入的键
+type scriptKeysHook struct {
+	mu   sync.Mutex
+	keys [][]string
+}
+
+func (h *scriptKeysHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		args := cmd.Args()
+		if name := cmd.Name(); (name == "evalsha" || name == "eval") && len(args) >= 3 {
+			n, _ := args[2].(int)
+			keys := make([]string, 0, n)
+			for _, arg := range args[3 : 3+n] {
+				keys = append(keys, fmt.Sprint(arg))
+			}
+			h.mu.Lock()
+			h.keys = append(h.keys, keys)
+			h.mu.Unlock()
+		}
+		return next(ctx, cmd)
+	}
+}
+
+func (h *scriptKeysHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return next
+}
+
+func (h *scriptKeysHook) last() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.keys) == 0 {
+		return nil
+	}
+	return h.keys[len(h.keys)-1]
+}
+
+func TestBloomFilterParams(t *testing.T) {
+	// 100万元素、1%误判率约需要 958.5 万位和 7 个哈希函数
+	capacity, bits, hashes := bloomFilterParams(1000000, 0.01, 2, 0.5, 0)
+	if capacity != 1000000 || bits != 9585059 || hashes != 7 {
+		t.Errorf("Unexpected params %d %d %d", capacity, bits, hashes)
+	}
+	capacity, _, _ = bloomFilterParams(1000, 0.01, 2, 0.5, 3)
+	if capacity != 8000 {
+		t.Errorf("Expected 4th filter capacity 8000, got %d", capacity)
+	}
+
+	if _, err := (&Client{}).NewBloomFilter("x", &BloomConfig{ErrorRate: 1.5}); err == nil {
+		t.Error("Expected invalid error rate to fail")
+	}
+}
+
+func TestBloomFilter(t *testing.T) {
+	client := newTestClient(t)
+	defer client.Close()
+
+	ctx := context.Background()
+	bf, err := client.NewBloomFilter("test:bloom", &BloomConfig{Capacity: 100, ErrorRate: 0.01})
+	if err != nil {
+		t.Fatalf("NewBloomFilter failed: %v", err)
+	}
+	defer bf.Delete(ctx)
+
+	if ok, err := bf.Exists(ctx, "missing"); err != nil || ok {
+		t.Errorf("Expected empty filter to report missing, got %v %v", ok, err)
+	}
+	if added, _ := bf.Add(ctx, "user:1"); !added {
+		t.Error("Expected first add to return true")
+	}
+	if added, _ := bf.Add(ctx, "user:1"); added {
+		t.Error("Expected duplicate add to return false")
+	}
+
+	// 超过第一个子过滤器容量后自动扩容
+	items := make([]string, 1000)
+	for i := range items {
+		items[i] = fmt.Sprintf("item:%d", i)
+	}
+	added, err := bf.AddMany(ctx, items...)
+	if err != nil {
+		t.Fatalf("AddMany failed: %v", err)
+	}
+	newCount := 0
+	for _, ok := range added {
+		if ok {
+			newCount++
+		}
+	}
+	if newCount < 980 {
+		t.Errorf("Expected almost all items to be new, got %d", newCount)
+	}
+
+	exists, err := bf.ExistsMany(ctx, items...)
+	if err != nil {
+		t.Fatalf("ExistsMany failed: %v", err)
+	}
+	for i, ok := range exists {
+		if !ok {
+			t.Fatalf("Expected no false negatives, %s missing", items[i])
+		}
+	}
+
+	probes := make([]string, 5000)
+	for i := range probes {
+		probes[i] = fmt.Sprintf("other:%d", i)
+	}
+	exists, _ = bf.ExistsMany(ctx, probes...)
+	falsePositives := 0
+	for _, ok := range exists {
+		if ok {
+			falsePositives++
+		}
+	}
+	if rate := float64(falsePositives) / float64(len(probes)); rate > 0.03 {
+		t.Errorf("False positive rate too high: %.4f", rate)
+	}
+
+	info, err := bf.Info(ctx)
+	if err != nil {
+		t.Fatalf("Info failed: %v", err)
+	}
+	if info.Filters < 4 || info.Count != int64(newCount+1) || info.Capacity < info.Count || info.ErrorRate > 0.01 {
+		t.Errorf("Unexpected info %+v", info)
+	}
+
+	// 配置保存在Redis中，之后使用不同配置打开同一个过滤器不影响已有参数
+	reopened, _ := client.NewBloomFilter("test:bloom", &BloomConfig{Capacity: 5, ErrorRate: 0.2})
+	if ok, _ := reopened.Exists(ctx, "user:1"); !ok {
+		t.Error("Expected reopened filter to use stored parameters")
+	}
+
+	if err := bf.Delete(ctx); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if n, _ := client.Exists(ctx, "{test:bloom}:meta", "{test:bloom}:0"); n != 0 {
+		t.Errorf("Expected filter keys deleted, %d remain", n)
+	}
+}
+
+func TestBloomFilterDeclaresKeys(t *testing.T) {
+	client := newTestClient(t)
+	defer client.Close()
+	hook := &scriptKeysHook{}
+	client.AddHook(hook)
+
+	ctx := context.Background()
+	writer, _ := client.NewBloomFilter("test:bloom:keys", &BloomConfig{Capacity: 10, ErrorRate: 0.01})
+	defer writer.Delete(ctx)
+	reader, _ := client.NewBloomFilter("test:bloom:keys", nil)
+
+	if ok, err := reader.Exists(ctx, "item:0"); err != nil || ok {
+		t.Fatalf("Expected empty filter to report missing, got %v %v", ok, err)
+	}
+
+	// 一次批量写入跨越多个子过滤器，超出传入的键时自动重试
+	items := make([]string, 200)
+	for i := range items {
+		items[i] = fmt.Sprintf("item:%d", i)
+	}
+	if _, err := writer.AddMany(ctx, items...); err != nil {
+		t.Fatalf("AddMany failed: %v", err)
+	}
+	info, _ := writer.Info(ctx)
+	if info.Filters < 4 {
+		t.Fatalf("Expected filter to grow, got %+v", info)
+	}
+	if keys := hook.last(); len(keys) != info.Filters+1 {
+		t.Errorf("Expected meta and %d filter keys, got %v", info.Filters, keys)
+	}
+
+	// reader记录的子过滤器数量已过期，按脚本返回的数量重试
+	exists, err := reader.ExistsMany(ctx, items...)
+	if err != nil {
+		t.Fatalf("ExistsMany failed: %v", err)
+	}
+	for i, ok := range exists {
+		if !ok {
+			t.Fatalf("Expected no false negatives, %s missing", items[i])
+		}
+	}
+	keys := hook.last()
+	if len(keys) != info.Filters+1 || keys[0] != "{test:bloom:keys}:meta" {
+		t.Fatalf("Unexpected keys %v", keys)
+	}
+	for i, key := range keys[1:] {
+		if want := fmt.Sprintf("{test:bloom:keys}:%d", i); key != want {
+			t.Errorf("Expected key %s, got %s", want, key)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package redis
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -10,12 +11,36 @@ import (
 
 // Client Redis客户端封装
 type Client struct {
-	client *redis.Client
+	client redis.UniversalClient
 	config *Config
+	prefix keyPrefix     // 键前缀，见 Config.KeyPrefix 和 WithNamespace
+	guard  *commandGuard // 危险命令守卫，见 Config.GuardDangerousCommands
 }
 
+// Mode 部署模式
+type Mode string
+
+const (
+	// ModeStandalone 单机模式
+	ModeStandalone Mode = "standalone"
+	// ModeSentinel 哨兵模式，自动发现主节点并在故障转移后重连
+	ModeSentinel Mode = "sentinel"
+	// ModeCluster 集群模式
+	ModeCluster Mode = "cluster"
+)
+
 // Config Redis配置
 type Config struct {
+	// 部署模式配置
+	Mode             Mode     `json:"mode" yaml:"mode"`                           // 部署模式，默认单机
+	Addrs            []string `json:"addrs" yaml:"addrs"`                         // 哨兵地址或集群种子节点地址
+	MasterName       string   `json:"master_name" yaml:"master_name"`             // 哨兵模式的主节点名称
+	SentinelUsername string   `json:"sentinel_username" yaml:"sentinel_username"` // 哨兵用户名
+	SentinelPassword string   `json:"sentinel_password" yaml:"sentinel_password"` // 哨兵密码
+	ReadOnly         bool     `json:"read_only" yaml:"read_only"`                 // 只读命令发送到从节点
+	RouteByLatency   bool     `json:"route_by_latency" yaml:"route_by_latency"`   // 只读命令发送到延迟最低的节点，隐含ReadOnly
+	RouteRandomly    bool     `json:"route_randomly" yaml:"route_randomly"`       // 只读命令随机发送到任意节点，隐含ReadOnly
+
 	// 基本配置
 	Addr     string `json:"addr" yaml:"addr"`         // Redis地址，格式：host:port
 	Password string `json:"password" yaml:"password"` // 密码
@@ -43,11 +68,17 @@ type Config struct {
 	Protocol     int    `json:"protocol" yaml:"protocol"`           // 协议版本 (2 或 3)
 	DisableAuth  bool   `json:"disable_auth" yaml:"disable_auth"`   // 禁用认证
 	DisableAuth2 bool   `json:"disable_auth2" yaml:"disable_auth2"` // 禁用认证2 (兼容性)
+	KeyPrefix    string `json:"key_prefix" yaml:"key_prefix"`       // 键前缀，所有数据结构封装自动为键加上该前缀
+
+	// 安全配置
+	GuardDangerousCommands bool     `json:"guard_dangerous_commands" yaml:"guard_dangerous_commands"` // 禁止 KEYS、FLUSHDB、FLUSHALL，返回 ErrCommandDisabled
+	AllowedCommands        []string `json:"allowed_commands" yaml:"allowed_commands"`                 // 开启守卫时仍然允许的命令，如 "FLUSHDB"
 }
 
 // DefaultConfig 返回默认配置
 func DefaultConfig() *Config {
 	return &Config{
+		Mode:            ModeStandalone,
 		Addr:            "localhost:6379",
 		Password:        "",
 		DB:              0,
@@ -75,49 +106,133 @@ func New(config *Config) (*Client, error) {
 		config = DefaultConfig()
 	}
 
-	// 创建Redis客户端选项
-	opts := &redis.Options{
-		Addr:            config.Addr,
-		Password:        config.Password,
-		DB:              config.DB,
-		PoolSize:        config.PoolSize,
-		MinIdleConns:    config.MinIdleConns,
-		MaxIdleConns:    config.MaxIdleConns,
-		ConnMaxIdleTime: config.ConnMaxIdleTime,
-		ConnMaxLifetime: config.ConnMaxLifetime,
-		DialTimeout:     config.DialTimeout,
-		ReadTimeout:     config.ReadTimeout,
-		WriteTimeout:    config.WriteTimeout,
-		MaxRetries:      config.MaxRetries,
-		MinRetryBackoff: config.MinRetryBackoff,
-		MaxRetryBackoff: config.MaxRetryBackoff,
-		Username:        config.Username,
-		Protocol:        config.Protocol,
-	}
-
-	// 处理认证禁用选项（兼容性处理）
-	if config.DisableAuth || config.DisableAuth2 {
-		// go-redis v9 不支持 DisableAuth，通过设置空密码实现
-		opts.Password = ""
+	client, err := newUniversalClient(config)
+	if err != nil {
+		return nil, err
 	}
 
-	// 创建Redis客户端
-	client := redis.NewClient(opts)
-
 	// 测试连接
 	ctx, cancel := context.WithTimeout(context.Background(), config.DialTimeout)
 	defer cancel()
 
 	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
 		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
 	}
 
+	var guard *commandGuard
+	if config.GuardDangerousCommands {
+		guard = newCommandGuard(config.AllowedCommands)
+		client.AddHook(guard)
+	}
+
 	return &Client{
 		client: client,
 		config: config,
+		prefix: keyPrefix(config.KeyPrefix),
+		guard:  guard,
 	}, nil
 }
 
+// newUniversalClient 根据部署模式创建底层客户端
+func newUniversalClient(config *Config) (redis.UniversalClient, error) {
+	password := config.Password
+	if config.DisableAuth || config.DisableAuth2 {
+		// go-redis v9 不支持 DisableAuth，通过设置空密码实现
+		password = ""
+	}
+
+	switch config.Mode {
+	case "", ModeStandalone:
+		return redis.NewClient(&redis.Options{
+			Addr:            config.Addr,
+			Username:        config.Username,
+			Password:        password,
+			DB:              config.DB,
+			Protocol:        config.Protocol,
+			PoolSize:        config.PoolSize,
+			MinIdleConns:    config.MinIdleConns,
+			MaxIdleConns:    config.MaxIdleConns,
+			ConnMaxIdleTime: config.ConnMaxIdleTime,
+			ConnMaxLifetime: config.ConnMaxLifetime,
+			DialTimeout:     config.DialTimeout,
+			ReadTimeout:     config.ReadTimeout,
+			WriteTimeout:    config.WriteTimeout,
+			MaxRetries:      config.MaxRetries,
+			MinRetryBackoff: config.MinRetryBackoff,
+			MaxRetryBackoff: config.MaxRetryBackoff,
+		}), nil
+
+	case ModeSentinel:
+		if config.MasterName == "" {
+			return nil, fmt.Errorf("master name is required in sentinel mode")
+		}
+		if len(config.Addrs) == 0 {
+			return nil, fmt.Errorf("sentinel addrs are required in sentinel mode")
+		}
+		opts := &redis.FailoverOptions{
+			MasterName:       config.MasterName,
+			SentinelAddrs:    config.Addrs,
+			SentinelUsername: config.SentinelUsername,
+			SentinelPassword: config.SentinelPassword,
+			RouteByLatency:   config.RouteByLatency,
+			RouteRandomly:    config.RouteRandomly,
+			ReplicaOnly:      config.ReadOnly && !config.RouteByLatency && !config.RouteRandomly,
+			Username:         config.Username,
+			Password:         password,
+			DB:               config.DB,
+			Protocol:         config.Protocol,
+			PoolSize:         config.PoolSize,
+			MinIdleConns:     config.MinIdleConns,
+			MaxIdleConns:     config.MaxIdleConns,
+			ConnMaxIdleTime:  config.ConnMaxIdleTime,
+			ConnMaxLifetime:  config.ConnMaxLifetime,
+			DialTimeout:      config.DialTimeout,
+			ReadTimeout:      config.ReadTimeout,
+			WriteTimeout:     config.WriteTimeout,
+			MaxRetries:       config.MaxRetries,
+			MinRetryBackoff:  config.MinRetryBackoff,
+			MaxRetryBackoff:  config.MaxRetryBackoff,
+		}
+		// 按延迟或随机路由需要同时连接主从节点
+		if config.RouteByLatency || config.RouteRandomly {
+			return redis.NewFailoverClusterClient(opts), nil
+		}
+		return redis.NewFailoverClient(opts), nil
+
+	case ModeCluster:
+		if len(config.Addrs) == 0 {
+			return nil, fmt.Errorf("seed addrs are required in cluster mode")
+		}
+		if config.DB != 0 {
+			return nil, fmt.Errorf("cluster mode only supports DB 0, got %d", config.DB)
+		}
+		return redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:           config.Addrs,
+			ReadOnly:        config.ReadOnly,
+			RouteByLatency:  config.RouteByLatency,
+			RouteRandomly:   config.RouteRandomly,
+			Username:        config.Username,
+			Password:        password,
+			Protocol:        config.Protocol,
+			PoolSize:        config.PoolSize,
+			MinIdleConns:    config.MinIdleConns,
+			MaxIdleConns:    config.MaxIdleConns,
+			ConnMaxIdleTime: config.ConnMaxIdleTime,
+			ConnMaxLifetime: config.ConnMaxLifetime,
+			DialTimeout:     config.DialTimeout,
+			ReadTimeout:     config.ReadTimeout,
+			WriteTimeout:    config.WriteTimeout,
+			MaxRetries:      config.MaxRetries,
+			MinRetryBackoff: config.MinRetryBackoff,
+			MaxRetryBackoff: config.MaxRetryBackoff,
+		}), nil
+
+	default:
+		return nil, fmt.Errorf("unknown redis mode: %s", config.Mode)
+	}
+}
+
 // NewFromURL 从URL创建Redis客户端
 func NewFromURL(url string) (*Client, error) {
 	opts, err := redis.ParseURL(url)
@@ -138,6 +253,7 @@ func NewFromURL(url string) (*Client, error) {
 	return &Client{
 		client: client,
 		config: &Config{
+			Mode:     ModeStandalone,
 			Addr:     opts.Addr,
 			Password: opts.Password,
 			DB:       opts.DB,
@@ -145,11 +261,34 @@ func NewFromURL(url string) (*Client, error) {
 	}, nil
 }
 
-// GetClient 获取原始Redis客户端
+// GetClient 获取原始Redis客户端。
+//
+// 注意：底层为 *redis.ClusterClient 时（集群模式，或哨兵模式开启 RouteByLatency、RouteRandomly）
+// 返回nil且不报错，直接使用返回值会在运行时panic。
+//
+// Deprecated: 使用 GetUniversalClient，它在所有模式下都返回可用的客户端
 func (c *Client) GetClient() *redis.Client {
+	client, _ := c.client.(*redis.Client)
+	return client
+}
+
+// GetUniversalClient 获取原始Redis客户端，单机和哨兵模式为 *redis.Client，集群模式为 *redis.ClusterClient
+func (c *Client) GetUniversalClient() redis.UniversalClient {
 	return c.client
 }
 
+// forEachMaster 在每个主节点上执行fn，单机和哨兵模式只有一个主节点
+func (c *Client) forEachMaster(ctx context.Context, fn func(ctx context.Context, client *redis.Client) error) error {
+	switch client := c.client.(type) {
+	case *redis.ClusterClient:
+		return client.ForEachMaster(ctx, fn)
+	case *redis.Client:
+		return fn(ctx, client)
+	default:
+		return fmt.Errorf("unsupported redis client type %T", c.client)
+	}
+}
+
 // GetConfig 获取配置
 func (c *Client) GetConfig() *Config {
 	return c.config
@@ -185,6 +324,8 @@ func (c *Client) SetConfig(config *Config) error {
 	// 更新客户端
 	c.client = newClient.client
 	c.config = newClient.config
+	c.prefix = newClient.prefix
+	c.guard = newClient.guard
 
 	return nil
 }
@@ -194,14 +335,24 @@ func (c *Client) GetStats() *redis.PoolStats {
 	return c.client.PoolStats()
 }
 
-// FlushDB 清空当前数据库
+// FlushDB 清空当前数据库，集群模式下清空所有主节点；不受键前缀限制
 func (c *Client) FlushDB(ctx context.Context) error {
-	return c.client.FlushDB(ctx).Err()
+	if err := c.guard.check("flushdb"); err != nil {
+		return err
+	}
+	return c.forEachMaster(ctx, func(ctx context.Context, client *redis.Client) error {
+		return client.FlushDB(ctx).Err()
+	})
 }
 
-// FlushAll 清空所有数据库
+// FlushAll 清空所有数据库，集群模式下清空所有主节点；不受键前缀限制
 func (c *Client) FlushAll(ctx context.Context) error {
-	return c.client.FlushAll(ctx).Err()
+	if err := c.guard.check("flushall"); err != nil {
+		return err
+	}
+	return c.forEachMaster(ctx, func(ctx context.Context, client *redis.Client) error {
+		return client.FlushAll(ctx).Err()
+	})
 }
 
 // Info 获取Redis信息
@@ -209,32 +360,75 @@ func (c *Client) Info(ctx context.Context, section ...string) (string, error) {
 	return c.client.Info(ctx, section...).Result()
 }
 
-// Keys 获取匹配的键
+// Keys 获取匹配的键，集群模式下汇总所有主节点；只匹配当前前缀下的键，返回的键名不含前缀。
+// KEYS 会阻塞服务端，生产环境应使用 Scan
 func (c *Client) Keys(ctx context.Context, pattern string) ([]string, error) {
-	return c.client.Keys(ctx, pattern).Result()
+	if err := c.guard.check("keys"); err != nil {
+		return nil, err
+	}
+	var mutex sync.Mutex
+	var keys []string
+	err := c.forEachMaster(ctx, func(ctx context.Context, client *redis.Client) error {
+		found, err := client.Keys(ctx, c.prefix.pattern(pattern)).Result()
+		if err != nil {
+			return err
+		}
+		mutex.Lock()
+		keys = append(keys, c.prefix.stripAll(found)...)
+		mutex.Unlock()
+		return nil
+	})
+	return keys, err
 }
 
 // Exists 检查键是否存在
 func (c *Client) Exists(ctx context.Context, keys ...string) (int64, error) {
-	return c.client.Exists(ctx, keys...).Result()
+	return c.sumPerKey(ctx, keys, func(client redis.Cmdable, keys ...string) *redis.IntCmd {
+		return client.Exists(ctx, keys...)
+	})
 }
 
 // Del 删除键
 func (c *Client) Del(ctx context.Context, keys ...string) (int64, error) {
-	return c.client.Del(ctx, keys...).Result()
+	return c.sumPerKey(ctx, keys, func(client redis.Cmdable, keys ...string) *redis.IntCmd {
+		return client.Del(ctx, keys...)
+	})
+}
+
+// sumPerKey 执行返回计数的多键命令。集群模式下多个键不一定位于同一槽位，
+// 直接执行会返回CROSSSLOT错误，因此拆分为单键命令通过流水线发往各自的节点后求和
+func (c *Client) sumPerKey(ctx context.Context, keys []string, cmd func(client redis.Cmdable, keys ...string) *redis.IntCmd) (int64, error) {
+	keys = c.prefix.keys(keys)
+	if _, ok := c.client.(*redis.ClusterClient); !ok || len(keys) <= 1 {
+		return cmd(c.client, keys...).Result()
+	}
+	cmds := make([]*redis.IntCmd, len(keys))
+	if _, err := c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			cmds[i] = cmd(pipe, key)
+		}
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+	var total int64
+	for _, cmd := range cmds {
+		total += cmd.Val()
+	}
+	return total, nil
 }
 
 // Expire 设置键的过期时间
 func (c *Client) Expire(ctx context.Context, key string, expiration time.Duration) (bool, error) {
-	return c.client.Expire(ctx, key, expiration).Result()
+	return c.client.Expire(ctx, c.prefix.key(key), expiration).Result()
 }
 
 // TTL 获取键的剩余生存时间
 func (c *Client) TTL(ctx context.Context, key string) (time.Duration, error) {
-	return c.client.TTL(ctx, key).Result()
+	return c.client.TTL(ctx, c.prefix.key(key)).Result()
 }
 
 // Type 获取键的类型
 func (c *Client) Type(ctx context.Context, key string) (string, error) {
-	return c.client.Type(ctx, key).Result()
+	return c.client.Type(ctx, c.prefix.key(key)).Result()
 }
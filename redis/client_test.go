@@ -2,7 +2,10 @@ package redis
 
 import (
 	"context"
+	"fmt"
 	"testing"
+
+	"github.com/redis/go-redis/v9"
 )
 
 func TestClient(t *testing.T) {
@@ -132,3 +135,133 @@ func TestClientSetConfig(t *testing.T) {
 		t.Errorf("Expected DB %d, got %d", newConfig.DB, clientConfig.DB)
 	}
 }
+
+func TestNewUniversalClientModes(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(c *Config)
+		want    string
+		wantErr bool
+	}{
+		{"standalone", func(c *Config) {}, "*redis.Client", false},
+		{"empty mode", func(c *Config) { c.Mode = "" }, "*redis.Client", false},
+		{"sentinel", func(c *Config) {
+			c.Mode = ModeSentinel
+			c.MasterName = "mymaster"
+			c.Addrs = []string{"localhost:26379"}
+		}, "*redis.Client", false},
+		{"sentinel route by latency", func(c *Config) {
+			c.Mode = ModeSentinel
+			c.MasterName = "mymaster"
+			c.Addrs = []string{"localhost:26379"}
+			c.RouteByLatency = true
+		}, "*redis.ClusterClient", false},
+		{"sentinel without master name", func(c *Config) {
+			c.Mode = ModeSentinel
+			c.Addrs = []string{"localhost:26379"}
+		}, "", true},
+		{"sentinel without addrs", func(c *Config) {
+			c.Mode = ModeSentinel
+			c.MasterName = "mymaster"
+		}, "", true},
+		{"cluster", func(c *Config) {
+			c.Mode = ModeCluster
+			c.Addrs = []string{"localhost:7000", "localhost:7001"}
+			c.ReadOnly = true
+		}, "*redis.ClusterClient", false},
+		{"cluster without addrs", func(c *Config) { c.Mode = ModeCluster }, "", true},
+		{"cluster with db", func(c *Config) {
+			c.Mode = ModeCluster
+			c.Addrs = []string{"localhost:7000"}
+			c.DB = 1
+		}, "", true},
+		{"unknown mode", func(c *Config) { c.Mode = "proxy" }, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			tt.modify(config)
+
+			client, err := newUniversalClient(config)
+			if tt.wantErr {
+				if err == nil {
+					client.Close()
+					t.Errorf("Expected error for config %+v", config)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("newUniversalClient failed: %v", err)
+			}
+			defer client.Close()
+
+			if got := fmt.Sprintf("%T", client); got != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestGetClient(t *testing.T) {
+	config := DefaultConfig()
+	standalone, err := newUniversalClient(config)
+	if err != nil {
+		t.Fatalf("newUniversalClient failed: %v", err)
+	}
+	defer standalone.Close()
+
+	client := &Client{client: standalone, config: config}
+	if client.GetClient() == nil {
+		t.Error("Expected *redis.Client in standalone mode")
+	}
+	if client.GetUniversalClient() != standalone {
+		t.Error("Expected GetUniversalClient to return the underlying client")
+	}
+
+	config = DefaultConfig()
+	config.Mode = ModeCluster
+	config.Addrs = []string{"localhost:7000"}
+	cluster, err := newUniversalClient(config)
+	if err != nil {
+		t.Fatalf("newUniversalClient failed: %v", err)
+	}
+	defer cluster.Close()
+
+	client = &Client{client: cluster, config: config}
+	if client.GetClient() != nil {
+		t.Error("Expected nil *redis.Client in cluster mode")
+	}
+	if client.GetUniversalClient() != cluster {
+		t.Error("Expected GetUniversalClient to return the cluster client")
+	}
+}
+
+func TestClusterDelExists(t *testing.T) {
+	// 所有槽位都指向本地节点，验证集群模式下按键拆分的路径
+	cluster := redis.NewClusterClient(&redis.ClusterOptions{
+		ClusterSlots: func(ctx context.Context) ([]redis.ClusterSlot, error) {
+			return []redis.ClusterSlot{{Start: 0, End: 16383, Nodes: []redis.ClusterNode{{Addr: "localhost:6379"}}}}, nil
+		},
+	})
+	defer cluster.Close()
+	ctx := context.Background()
+	if err := cluster.Ping(ctx).Err(); err != nil {
+		t.Skipf("Skipping test: Redis not available: %v", err)
+	}
+
+	client := &Client{client: cluster, config: DefaultConfig()}
+	keys := []string{"test:cluster:a", "test:cluster:b", "test:cluster:c"}
+	cluster.Set(ctx, keys[0], "1", 0)
+	cluster.Set(ctx, keys[1], "2", 0)
+
+	if n, err := client.Exists(ctx, keys...); err != nil || n != 2 {
+		t.Errorf("Expected 2 existing keys, got %d, %v", n, err)
+	}
+	if n, err := client.Del(ctx, keys...); err != nil || n != 2 {
+		t.Errorf("Expected 2 deleted keys, got %d, %v", n, err)
+	}
+	if n, _ := client.Exists(ctx, keys...); n != 0 {
+		t.Errorf("Expected no keys after delete, got %d", n)
+	}
+}
@@ -0,0 +1,217 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// 延迟队列在 Queue 的键之外增加：
+//
+//	delayed  待执行任务的有序集合，分数为执行时间（毫秒）
+
+// delayScheduleScript 保存任务内容并按执行时间加入有序集合
+var delayScheduleScript = redis.NewScript(`
+redis.call('HSET', KEYS[1], ARGV[1], ARGV[2])
+return redis.call('ZADD', KEYS[2], ARGV[3], ARGV[1])`)
+
+// delayPollScript 将到期的任务移入待处理列表，多个轮询者并发执行时每个任务只会被移动一次
+var delayPollScript = redis.NewScript(`
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local ids = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', now, 'LIMIT', 0, tonumber(ARGV[1]))
+for _, id in ipairs(ids) do
+    redis.call('ZREM', KEYS[1], id)
+    redis.call('LPUSH', KEYS[2], id)
+end
+return #ids`)
+
+// delayCancelScript 取消尚未开始处理的任务
+var delayCancelScript = redis.NewScript(`
+if redis.call('ZREM', KEYS[1], ARGV[1]) == 0 and redis.call('LREM', KEYS[2], 0, ARGV[1]) == 0 then
+    return 0
+end
+redis.call('HDEL', KEYS[3], ARGV[1])
+redis.call('HDEL', KEYS[4], ARGV[1])
+return 1`)
+
+// delayRetryScript 处理失败的任务在退避时间后重新执行，达到最大投递次数时进入死信列表
+// 返回 0 未持有，1 重新调度，2 进入死信列表
+var delayRetryScript = redis.NewScript(`
+if redis.call('LREM', KEYS[1], 1, ARGV[1]) == 0 then
+    return 0
+end
+redis.call('ZREM', KEYS[2], ARGV[1])
+redis.call('HDEL', KEYS[3], ARGV[1])
+local max = tonumber(ARGV[2])
+local attempts = tonumber(redis.call('HGET', KEYS[4], ARGV[1]) or '0')
+if max > 0 and attempts >= max then
+    redis.call('LPUSH', KEYS[6], ARGV[1])
+    return 2
+end
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+redis.call('ZADD', KEYS[5], now + tonumber(ARGV[3]), ARGV[1])
+return 1`)
+
+// DelayQueueConfig 延迟队列配置
+type DelayQueueConfig struct {
+	QueueConfig `yaml:",inline"`
+
+	PollInterval  time.Duration `json:"poll_interval" yaml:"poll_interval"`     // 检查到期任务的间隔
+	BatchSize     int           `json:"batch_size" yaml:"batch_size"`           // 每次最多移动的到期任务数
+	RetryDelay    time.Duration `json:"retry_delay" yaml:"retry_delay"`         // 失败后首次重试的延迟，之后按指数增长
+	MaxRetryDelay time.Duration `json:"max_retry_delay" yaml:"max_retry_delay"` // 最大重试延迟
+}
+
+// DefaultDelayQueueConfig 默认延迟队列配置
+func DefaultDelayQueueConfig() *DelayQueueConfig {
+	return &DelayQueueConfig{
+		QueueConfig:   *DefaultQueueConfig(),
+		PollInterval:  time.Second,
+		BatchSize:     100,
+		RetryDelay:    10 * time.Second,
+		MaxRetryDelay: 10 * time.Minute,
+	}
+}
+
+// DelayQueue 延迟任务队列，到期的任务进入 Queue 的待处理列表，由 Queue 提供可靠投递
+type DelayQueue struct {
+	*Queue
+	config *DelayQueueConfig
+}
+
+// NewDelayQueue 创建延迟队列，config为nil时使用默认配置
+func (c *Client) NewDelayQueue(name string, config *DelayQueueConfig) *DelayQueue {
+	if config == nil {
+		config = DefaultDelayQueueConfig()
+	}
+	cfg := *config
+	defaults := DefaultDelayQueueConfig()
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = defaults.PollInterval
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaults.BatchSize
+	}
+	if cfg.RetryDelay <= 0 {
+		cfg.RetryDelay = defaults.RetryDelay
+	}
+	if cfg.MaxRetryDelay < cfg.RetryDelay {
+		cfg.MaxRetryDelay = cfg.RetryDelay
+	}
+
+	q := &DelayQueue{Queue: c.NewQueue(name, &cfg.QueueConfig), config: &cfg}
+	q.Queue.retry = q.Retry
+	return q
+}
+
+// Schedule 添加在at时刻执行的任务并返回任务ID，at早于当前时间时在下次轮询时执行
+func (q *DelayQueue) Schedule(ctx context.Context, payload []byte, at time.Time) (string, error) {
+	id, err := newLockToken()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate job id: %w", err)
+	}
+	keys := []string{q.key("jobs"), q.key("delayed")}
+	if err := delayScheduleScript.Run(ctx, q.client, keys, id, payload, at.UnixMilli()).Err(); err != nil {
+		return "", fmt.Errorf("failed to schedule job: %w", err)
+	}
+	return id, nil
+}
+
+// ScheduleAfter 添加在delay之后执行的任务
+func (q *DelayQueue) ScheduleAfter(ctx context.Context, payload []byte, delay time.Duration) (string, error) {
+	return q.Schedule(ctx, payload, time.Now().Add(delay))
+}
+
+// Cancel 取消尚未开始处理的任务，任务不存在或已在处理中时返回false
+func (q *DelayQueue) Cancel(ctx context.Context, id string) (bool, error) {
+	keys := []string{q.key("delayed"), q.key("pending"), q.key("jobs"), q.key("attempts")}
+	cancelled, err := delayCancelScript.Run(ctx, q.client, keys, id).Int64()
+	if err != nil {
+		return false, fmt.Errorf("failed to cancel job: %w", err)
+	}
+	return cancelled == 1, nil
+}
+
+// Poll 将到期的任务移入待处理列表，返回移动的任务数
+func (q *DelayQueue) Poll(ctx context.Context) (int64, error) {
+	moved, err := delayPollScript.Run(ctx, q.client, []string{q.key("delayed"), q.key("pending")}, q.config.BatchSize).Int64()
+	if err != nil {
+		return 0, fmt.Errorf("failed to poll delayed jobs: %w", err)
+	}
+	return moved, nil
+}
+
+// Retry 任务处理失败，按投递次数指数退避后重新执行；达到最大投递次数时进入死信列表并返回true
+func (q *DelayQueue) Retry(ctx context.Context, job *Job) (bool, error) {
+	delay := q.config.RetryDelay
+	for i := 1; i < job.Attempts && delay < q.config.MaxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > q.config.MaxRetryDelay {
+		delay = q.config.MaxRetryDelay
+	}
+
+	keys := []string{job.processing, q.key("inflight"), q.key("owners"), q.key("attempts"), q.key("delayed"), q.key("dead")}
+	result, err := delayRetryScript.Run(ctx, q.client, keys, job.ID, q.config.MaxAttempts, delay.Milliseconds()).Int64()
+	if err != nil {
+		return false, fmt.Errorf("failed to retry job: %w", err)
+	}
+	if result == 0 {
+		return false, ErrJobNotHeld
+	}
+	return result == 2, nil
+}
+
+// Scheduled 返回尚未到期的任务数
+func (q *DelayQueue) Scheduled(ctx context.Context) (int64, error) {
+	return q.client.ZCard(ctx, q.key("delayed")).Result()
+}
+
+// ScheduledAt 返回任务的执行时间，任务不在等待中时返回false
+func (q *DelayQueue) ScheduledAt(ctx context.Context, id string) (time.Time, bool, error) {
+	score, err := q.client.ZScore(ctx, q.key("delayed"), id).Result()
+	if err == redis.Nil {
+		return time.Time{}, false, nil
+	}
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	return time.UnixMilli(int64(score)), true, nil
+}
+
+// Consume 定期移动到期任务并消费，直到ctx结束。多个实例可以同时运行，每个任务只会被投递给一个消费者；
+// handler返回错误时任务按退避时间重新调度
+func (q *DelayQueue) Consume(ctx context.Context, config *ConsumerConfig, handler QueueHandler) error {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	pollCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(q.config.PollInterval)
+		defer ticker.Stop()
+		for {
+			// 积压较多时连续移动
+			for {
+				moved, err := q.Poll(pollCtx)
+				if err != nil || moved < int64(q.config.BatchSize) {
+					break
+				}
+			}
+			select {
+			case <-pollCtx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return q.Queue.Consume(ctx, config, handler)
+}
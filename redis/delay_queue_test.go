@@ -0,0 +1,178 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestDelayQueue 创建测试延迟队列并清理残留数据
+func newTestDelayQueue(t *testing.T, name string, config *DelayQueueConfig) *DelayQueue {
+	t.Helper()
+	client := newTestClient(t)
+	cleanup := func() {
+		keys, _ := client.Keys(context.Background(), "{"+name+"}:*")
+		if len(keys) > 0 {
+			client.Del(context.Background(), keys...)
+		}
+	}
+	cleanup()
+	t.Cleanup(cleanup)
+	return client.NewDelayQueue(name, config)
+}
+
+func TestDelayQueue(t *testing.T) {
+	ctx := context.Background()
+	queue := newTestDelayQueue(t, "test:delay:basic", nil)
+
+	later, _ := queue.ScheduleAfter(ctx, []byte("later"), time.Hour)
+	soon, _ := queue.ScheduleAfter(ctx, []byte("soon"), 50*time.Millisecond)
+	cancelled, _ := queue.ScheduleAfter(ctx, []byte("cancelled"), 50*time.Millisecond)
+
+	if n, _ := queue.Scheduled(ctx); n != 3 {
+		t.Errorf("Expected 3 scheduled jobs, got %d", n)
+	}
+	if at, ok, err := queue.ScheduledAt(ctx, later); err != nil || !ok || time.Until(at) < 59*time.Minute {
+		t.Errorf("Unexpected schedule time %v, %v, %v", at, ok, err)
+	}
+
+	// 取消等待中的任务
+	if ok, err := queue.Cancel(ctx, cancelled); err != nil || !ok {
+		t.Fatalf("Cancel returned %v, %v", ok, err)
+	}
+	if ok, _ := queue.Cancel(ctx, cancelled); ok {
+		t.Error("Expected second cancel to return false")
+	}
+
+	// 未到期时不移动
+	if moved, _ := queue.Poll(ctx); moved != 0 {
+		t.Errorf("Expected no due jobs, got %d", moved)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if moved, err := queue.Poll(ctx); err != nil || moved != 1 {
+		t.Fatalf("Poll returned %d, %v", moved, err)
+	}
+
+	job, err := queue.Dequeue(ctx, "c1")
+	if err != nil || job.ID != soon || string(job.Payload) != "soon" {
+		t.Fatalf("Unexpected job %+v, %v", job, err)
+	}
+	if err := queue.Ack(ctx, job); err != nil {
+		t.Errorf("Ack failed: %v", err)
+	}
+
+	// 已进入待处理列表的任务同样可以取消
+	due, _ := queue.Schedule(ctx, []byte("due"), time.Now().Add(-time.Second))
+	queue.Poll(ctx)
+	if ok, _ := queue.Cancel(ctx, due); !ok {
+		t.Error("Expected pending job to be cancellable")
+	}
+	if _, err := queue.Dequeue(ctx, "c1"); err != ErrQueueEmpty {
+		t.Errorf("Expected cancelled job not to be delivered, got %v", err)
+	}
+}
+
+func TestDelayQueueRetry(t *testing.T) {
+	ctx := context.Background()
+	config := DefaultDelayQueueConfig()
+	config.MaxAttempts = 3
+	config.RetryDelay = 100 * time.Millisecond
+	config.MaxRetryDelay = 150 * time.Millisecond
+	queue := newTestDelayQueue(t, "test:delay:retry", config)
+
+	queue.Schedule(ctx, []byte("x"), time.Now())
+	queue.Poll(ctx)
+	job, _ := queue.Dequeue(ctx, "c1")
+
+	// 第一次失败在RetryDelay后重新执行
+	if dead, err := queue.Retry(ctx, job); err != nil || dead {
+		t.Fatalf("Retry returned %v, %v", dead, err)
+	}
+	at, ok, _ := queue.ScheduledAt(ctx, job.ID)
+	if delay := time.Until(at); !ok || delay < 50*time.Millisecond || delay > 100*time.Millisecond {
+		t.Errorf("Unexpected retry delay %v", delay)
+	}
+	if err := queue.Ack(ctx, job); err != ErrJobNotHeld {
+		t.Errorf("Expected ErrJobNotHeld after retry, got %v", err)
+	}
+
+	// 退避时间不超过MaxRetryDelay
+	time.Sleep(150 * time.Millisecond)
+	queue.Poll(ctx)
+	job, _ = queue.Dequeue(ctx, "c1")
+	queue.Retry(ctx, job)
+	at, _, _ = queue.ScheduledAt(ctx, job.ID)
+	if delay := time.Until(at); delay > 150*time.Millisecond {
+		t.Errorf("Expected delay capped at MaxRetryDelay, got %v", delay)
+	}
+
+	// 达到最大投递次数进入死信列表
+	time.Sleep(200 * time.Millisecond)
+	queue.Poll(ctx)
+	job, _ = queue.Dequeue(ctx, "c1")
+	if dead, err := queue.Retry(ctx, job); err != nil || !dead {
+		t.Fatalf("Expected job to be dead-lettered, got %v, %v", dead, err)
+	}
+	if n, _ := queue.DeadLen(ctx); n != 1 {
+		t.Errorf("Expected 1 dead job, got %d", n)
+	}
+}
+
+func TestDelayQueueCompetingConsumers(t *testing.T) {
+	ctx := context.Background()
+	config := DefaultDelayQueueConfig()
+	config.PollInterval = 10 * time.Millisecond
+	config.RetryDelay = 50 * time.Millisecond
+	config.BatchSize = 5
+	queue := newTestDelayQueue(t, "test:delay:competing", config)
+
+	for i := 0; i < 30; i++ {
+		queue.ScheduleAfter(ctx, []byte("job"), time.Duration(i)*5*time.Millisecond)
+	}
+	failing, _ := queue.ScheduleAfter(ctx, []byte("flaky"), 0)
+
+	var mu sync.Mutex
+	deliveries := make(map[string]int)
+	var flakyAttempts int32
+	handler := func(ctx context.Context, job *Job) error {
+		if job.ID == failing && atomic.AddInt32(&flakyAttempts, 1) == 1 {
+			return errors.New("transient")
+		}
+		mu.Lock()
+		deliveries[job.ID]++
+		mu.Unlock()
+		return nil
+	}
+
+	consumeCtx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	for _, name := range []string{"a", "b", "c"} {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			queue.Consume(consumeCtx, &ConsumerConfig{Name: name, Concurrency: 2, PollInterval: 5 * time.Millisecond}, handler)
+		}(name)
+	}
+
+	ok := waitFor(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(deliveries) == 31
+	})
+	cancel()
+	wg.Wait()
+	if !ok {
+		t.Fatalf("Expected 31 jobs delivered, got %d", len(deliveries))
+	}
+	for id, n := range deliveries {
+		if n != 1 {
+			t.Errorf("Job %s delivered %d times", id, n)
+		}
+	}
+	if flakyAttempts != 2 {
+		t.Errorf("Expected flaky job to be retried once, got %d attempts", flakyAttempts)
+	}
+}
@@ -0,0 +1,379 @@
+package redis
+
+import (
+	"context"
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// 结构体与哈希的映射规则：
+//
+//	Name    string        `redis:"name"`            // 字段名为name
+//	Email   string        `redis:"email,omitempty"` // 零值时不写入
+//	Secret  string        `redis:"-"`               // 忽略
+//	Age     int                                     // 没有标签时使用字段名Age
+//
+// 字符串、数字和布尔值按文本保存，可以配合 HIncrBy 使用；time.Time 按 RFC3339Nano 保存，
+// time.Duration 按 "1m30s" 格式保存；实现了 encoding.TextMarshaler 的类型使用其文本格式；
+// 结构体、切片、映射等其他类型使用 Hash 的编解码器（默认JSON）。匿名嵌入的结构体字段会展开。
+
+var (
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	durationType        = reflect.TypeOf(time.Duration(0))
+	timeType            = reflect.TypeOf(time.Time{})
+	bytesType           = reflect.TypeOf([]byte(nil))
+
+	// hashFieldCache 结构体类型到字段映射的缓存
+	hashFieldCache sync.Map
+)
+
+// hashField 结构体字段与哈希字段的映射
+type hashField struct {
+	name      string
+	index     []int
+	omitEmpty bool
+}
+
+// WithCodec 返回使用指定编解码器处理嵌套类型的哈希操作实例
+func (h *Hash) WithCodec(codec Codec) *Hash {
+	return &Hash{client: h.client, prefix: h.prefix, codec: codec}
+}
+
+// HSetStruct 将结构体的字段写入哈希，v为结构体或结构体指针。
+// 带omitempty的零值字段和nil指针字段不会写入，哈希中已有的其他字段保持不变
+func (h *Hash) HSetStruct(ctx context.Context, key string, v interface{}) error {
+	rv, err := structValue(v)
+	if err != nil {
+		return err
+	}
+	values, _, err := h.encodeStruct(rv)
+	if err != nil {
+		return err
+	}
+	if len(values) == 0 {
+		return nil
+	}
+
+	args := make([]interface{}, 0, len(values)*2)
+	for _, field := range hashFields(rv.Type()) {
+		if value, ok := values[field.name]; ok {
+			args = append(args, field.name, value)
+		}
+	}
+	return h.client.HSet(ctx, h.prefix.key(key), args...).Err()
+}
+
+// HGetStruct 读取哈希并填充到结构体指针，哈希中不存在的字段保持原值；键不存在时返回 redis.Nil
+func (h *Hash) HGetStruct(ctx context.Context, key string, dest interface{}) error {
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("dest must be a non-nil pointer to struct, got %T", dest)
+	}
+
+	values, err := h.client.HGetAll(ctx, h.prefix.key(key)).Result()
+	if err != nil {
+		return err
+	}
+	if len(values) == 0 {
+		return redis.Nil
+	}
+	return h.decodeStruct(rv.Elem(), values)
+}
+
+// HUpdateStruct 比较修改前后的两个结构体，只写入发生变化的字段，并删除变为零值的omitempty字段和变为nil的指针字段。
+// before为nil时写入after的全部字段，返回变化的哈希字段名
+func (h *Hash) HUpdateStruct(ctx context.Context, key string, before, after interface{}) ([]string, error) {
+	afterValue, err := structValue(after)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, afterOmitted, err := h.encodeStruct(afterValue)
+	if err != nil {
+		return nil, err
+	}
+
+	beforeFields := map[string]string{}
+	beforeOmitted := map[string]bool{}
+	if before != nil {
+		beforeValue, err := structValue(before)
+		if err != nil {
+			return nil, err
+		}
+		if beforeValue.Type() != afterValue.Type() {
+			return nil, fmt.Errorf("before and after must be the same type, got %T and %T", before, after)
+		}
+		if beforeFields, beforeOmitted, err = h.encodeStruct(beforeValue); err != nil {
+			return nil, err
+		}
+	}
+
+	var changed, deleted []string
+	var args []interface{}
+	for _, field := range hashFields(afterValue.Type()) {
+		value, ok := afterFields[field.name]
+		if ok {
+			if previous, exists := beforeFields[field.name]; !exists || previous != value {
+				args = append(args, field.name, value)
+				changed = append(changed, field.name)
+			}
+			continue
+		}
+		if afterOmitted[field.name] && before != nil && !beforeOmitted[field.name] {
+			deleted = append(deleted, field.name)
+			changed = append(changed, field.name)
+		}
+	}
+	if len(changed) == 0 {
+		return nil, nil
+	}
+
+	_, err = h.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		if len(args) > 0 {
+			pipe.HSet(ctx, h.prefix.key(key), args...)
+		}
+		if len(deleted) > 0 {
+			pipe.HDel(ctx, h.prefix.key(key), deleted...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return changed, nil
+}
+
+// structValue 返回结构体或结构体指针对应的可寻址结构体值
+func structValue(v interface{}) (reflect.Value, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return reflect.Value{}, fmt.Errorf("value must not be a nil pointer")
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("value must be a struct or pointer to struct, got %T", v)
+	}
+	if !rv.CanAddr() {
+		addressable := reflect.New(rv.Type()).Elem()
+		addressable.Set(rv)
+		rv = addressable
+	}
+	return rv, nil
+}
+
+// hashFields 返回结构体类型的字段映射
+func hashFields(t reflect.Type) []hashField {
+	if cached, ok := hashFieldCache.Load(t); ok {
+		return cached.([]hashField)
+	}
+	fields := collectHashFields(t, nil)
+	hashFieldCache.Store(t, fields)
+	return fields
+}
+
+// collectHashFields 解析结构体字段，展开没有标签的匿名嵌入结构体
+func collectHashFields(t reflect.Type, index []int) []hashField {
+	var fields []hashField
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("redis")
+		if tag == "-" {
+			continue
+		}
+		fieldIndex := append(append([]int{}, index...), i)
+
+		if sf.Anonymous && tag == "" && sf.Type.Kind() == reflect.Struct {
+			fields = append(fields, collectHashFields(sf.Type, fieldIndex)...)
+			continue
+		}
+		if !sf.IsExported() {
+			continue
+		}
+
+		options := strings.Split(tag, ",")
+		field := hashField{name: options[0], index: fieldIndex}
+		if field.name == "" {
+			field.name = sf.Name
+		}
+		for _, option := range options[1:] {
+			if option == "omitempty" {
+				field.omitEmpty = true
+			}
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// encodeStruct 编码结构体字段，返回写入的字段和被省略的字段
+func (h *Hash) encodeStruct(rv reflect.Value) (map[string]string, map[string]bool, error) {
+	values := make(map[string]string)
+	omitted := make(map[string]bool)
+	for _, field := range hashFields(rv.Type()) {
+		fv := rv.FieldByIndex(field.index)
+		if (fv.Kind() == reflect.Ptr && fv.IsNil()) || (field.omitEmpty && fv.IsZero()) {
+			omitted[field.name] = true
+			continue
+		}
+		value, err := h.encodeHashValue(fv)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to encode field %s: %w", field.name, err)
+		}
+		values[field.name] = value
+	}
+	return values, omitted, nil
+}
+
+// decodeStruct 将哈希字段解码到结构体
+func (h *Hash) decodeStruct(rv reflect.Value, values map[string]string) error {
+	for _, field := range hashFields(rv.Type()) {
+		value, ok := values[field.name]
+		if !ok {
+			continue
+		}
+		fv := rv.FieldByIndex(field.index)
+		if err := h.decodeHashValue(fv, value); err != nil {
+			return fmt.Errorf("failed to decode field %s: %w", field.name, err)
+		}
+	}
+	return nil
+}
+
+// encodeHashValue 将字段值编码为字符串
+func (h *Hash) encodeHashValue(v reflect.Value) (string, error) {
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+
+	switch v.Type() {
+	case durationType:
+		return time.Duration(v.Int()).String(), nil
+	case timeType:
+		return v.Interface().(time.Time).Format(time.RFC3339Nano), nil
+	case bytesType:
+		return string(v.Bytes()), nil
+	}
+	if v.Type().Implements(textMarshalerType) {
+		text, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(text), err
+	}
+	if v.CanAddr() && reflect.PointerTo(v.Type()).Implements(textMarshalerType) {
+		text, err := v.Addr().Interface().(encoding.TextMarshaler).MarshalText()
+		return string(text), err
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		if v.Bool() {
+			return "1", nil
+		}
+		return "0", nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 32), nil
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64), nil
+	}
+
+	data, err := h.getCodec().Marshal(v.Interface())
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// decodeHashValue 将字符串解码到字段
+func (h *Hash) decodeHashValue(v reflect.Value, value string) error {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+
+	switch v.Type() {
+	case durationType:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			// 兼容以纳秒整数保存的值
+			n, intErr := strconv.ParseInt(value, 10, 64)
+			if intErr != nil {
+				return err
+			}
+			d = time.Duration(n)
+		}
+		v.SetInt(int64(d))
+		return nil
+	case timeType:
+		t, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	case bytesType:
+		v.SetBytes([]byte(value))
+		return nil
+	}
+	if reflect.PointerTo(v.Type()).Implements(textUnmarshalerType) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value))
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+		return nil
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+		return nil
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+		return nil
+	}
+
+	return h.getCodec().Unmarshal([]byte(value), v.Addr().Interface())
+}
+
+// getCodec 返回嵌套类型使用的编解码器
+func (h *Hash) getCodec() Codec {
+	if h.codec == nil {
+		return JSONCodec
+	}
+	return h.codec
+}
@@ -0,0 +1,190 @@
+package redis
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type testAddress struct {
+	City   string `json:"city"`
+	Street string `json:"street"`
+}
+
+type testAudit struct {
+	CreatedAt time.Time `redis:"created_at"`
+}
+
+type testProfile struct {
+	testAudit
+	ID       int64             `redis:"id"`
+	Name     string            `redis:"name"`
+	Email    string            `redis:"email,omitempty"`
+	Active   bool              `redis:"active"`
+	Score    float64           `redis:"score"`
+	Timeout  time.Duration     `redis:"timeout"`
+	Address  testAddress       `redis:"address"`
+	Tags     []string          `redis:"tags,omitempty"`
+	Nickname *string           `redis:"nickname"`
+	Extra    map[string]string `redis:"extra,omitempty"`
+	Password string            `redis:"-"`
+	Level    uint8
+}
+
+func TestHashStruct(t *testing.T) {
+	client := newTestClient(t)
+	defer client.Close()
+
+	ctx := context.Background()
+	hash := client.NewHash()
+	key := "test:hash:profile"
+	defer client.Del(ctx, key)
+	client.Del(ctx, key)
+
+	nickname := "tom"
+	profile := testProfile{
+		testAudit: testAudit{CreatedAt: time.Date(2024, 5, 1, 8, 30, 0, 123, time.UTC)},
+		ID:        42,
+		Name:      "Tom",
+		Active:    true,
+		Score:     98.5,
+		Timeout:   90 * time.Second,
+		Address:   testAddress{City: "Shanghai", Street: "Nanjing Rd"},
+		Tags:      []string{"vip", "beta"},
+		Nickname:  &nickname,
+		Password:  "secret",
+		Level:     3,
+	}
+	if err := hash.HSetStruct(ctx, key, &profile); err != nil {
+		t.Fatalf("HSetStruct failed: %v", err)
+	}
+
+	raw, err := hash.HGetAll(ctx, key)
+	if err != nil {
+		t.Fatalf("HGetAll failed: %v", err)
+	}
+	expected := map[string]string{
+		"created_at": "2024-05-01T08:30:00.000000123Z",
+		"id":         "42",
+		"name":       "Tom",
+		"active":     "1",
+		"score":      "98.5",
+		"timeout":    "1m30s",
+		"address":    `{"city":"Shanghai","street":"Nanjing Rd"}`,
+		"tags":       `["vip","beta"]`,
+		"nickname":   "tom",
+		"Level":      "3",
+	}
+	if !reflect.DeepEqual(raw, expected) {
+		t.Errorf("Unexpected hash fields:\n got %v\nwant %v", raw, expected)
+	}
+
+	// 数字字段可以直接自增
+	if _, err := hash.HIncrBy(ctx, key, "id", 1); err != nil {
+		t.Fatalf("HIncrBy failed: %v", err)
+	}
+
+	var loaded testProfile
+	if err := hash.HGetStruct(ctx, key, &loaded); err != nil {
+		t.Fatalf("HGetStruct failed: %v", err)
+	}
+	profile.ID = 43
+	profile.Password = ""
+	if !reflect.DeepEqual(loaded, profile) {
+		t.Errorf("Unexpected struct:\n got %+v\nwant %+v", loaded, profile)
+	}
+
+	var missing testProfile
+	if err := hash.HGetStruct(ctx, "test:hash:missing", &missing); err != redis.Nil {
+		t.Errorf("Expected redis.Nil, got %v", err)
+	}
+	if err := hash.HGetStruct(ctx, key, loaded); err == nil {
+		t.Error("Expected error for non-pointer dest")
+	}
+}
+
+func TestHashUpdateStruct(t *testing.T) {
+	client := newTestClient(t)
+	defer client.Close()
+
+	ctx := context.Background()
+	hash := client.NewHash().WithCodec(MsgpackCodec)
+	key := "test:hash:update"
+	defer client.Del(ctx, key)
+	client.Del(ctx, key)
+
+	before := testProfile{ID: 1, Name: "Tom", Email: "tom@example.com", Tags: []string{"a"}}
+	changed, err := hash.HUpdateStruct(ctx, key, nil, &before)
+	if err != nil {
+		t.Fatalf("HUpdateStruct failed: %v", err)
+	}
+	if len(changed) != 10 {
+		t.Errorf("Expected all 10 written fields to change, got %v", changed)
+	}
+
+	after := before
+	after.Name = "Tommy"
+	after.Email = ""
+	after.Score = 1.5
+	changed, err = hash.HUpdateStruct(ctx, key, before, after)
+	if err != nil {
+		t.Fatalf("HUpdateStruct failed: %v", err)
+	}
+	sort.Strings(changed)
+	if !reflect.DeepEqual(changed, []string{"email", "name", "score"}) {
+		t.Errorf("Unexpected changed fields %v", changed)
+	}
+
+	exists, _ := hash.HExists(ctx, key, "email")
+	if exists {
+		t.Error("Expected cleared omitempty field to be deleted")
+	}
+
+	var loaded testProfile
+	if err := hash.HGetStruct(ctx, key, &loaded); err != nil {
+		t.Fatalf("HGetStruct failed: %v", err)
+	}
+	if loaded.Name != "Tommy" || loaded.Score != 1.5 || loaded.Email != "" || !reflect.DeepEqual(loaded.Tags, []string{"a"}) {
+		t.Errorf("Unexpected struct %+v", loaded)
+	}
+
+	// 没有变化时不写入
+	changed, err = hash.HUpdateStruct(ctx, key, after, after)
+	if err != nil || len(changed) != 0 {
+		t.Errorf("Expected no changes, got %v %v", changed, err)
+	}
+
+	if _, err := hash.HUpdateStruct(ctx, key, testAddress{}, after); err == nil {
+		t.Error("Expected error for mismatched types")
+	}
+}
+
+func TestHashScanAll(t *testing.T) {
+	client := newTestClient(t)
+	defer client.Close()
+
+	ctx := context.Background()
+	hash := client.NewHash()
+	key := "test:hash:scan"
+	defer client.Del(ctx, key)
+
+	values := make([]interface{}, 0, 200)
+	for i := 0; i < 100; i++ {
+		values = append(values, "field:"+time.Duration(i).String(), i)
+	}
+	if _, err := hash.HSet(ctx, key, values...); err != nil {
+		t.Fatalf("HSet failed: %v", err)
+	}
+
+	all, err := hash.HScanAll(ctx, key, "field:*", 10)
+	if err != nil {
+		t.Fatalf("HScanAll failed: %v", err)
+	}
+	if len(all) != 100 {
+		t.Errorf("Expected 100 fields, got %d", len(all))
+	}
+}
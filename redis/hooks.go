@@ -0,0 +1,407 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Hook 命令钩子，包装单条命令和管道（包括事务）的执行，实现方调用next继续执行
+type Hook interface {
+	ProcessHook(next redis.ProcessHook) redis.ProcessHook
+	ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook
+}
+
+// hookAdapter 将 Hook 适配为 go-redis 的 redis.Hook
+type hookAdapter struct {
+	Hook
+}
+
+// DialHook 实现 redis.Hook
+func (hookAdapter) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+// AddHook 添加命令钩子，先添加的钩子在外层；钩子作用于底层连接，共享连接的命名空间视图同样生效。
+// 集群模式下 Keys、Scan、FlushDB 等按节点执行的命令不经过钩子
+func (c *Client) AddHook(hooks ...Hook) {
+	for _, hook := range hooks {
+		c.client.AddHook(hookAdapter{hook})
+	}
+}
+
+// pipelineName 管道在统计和追踪中使用的名称
+const pipelineName = "pipeline"
+
+// isCommandError 判断命令错误是否计为失败，redis.Nil 表示键不存在，不算失败
+func isCommandError(err error) bool {
+	return err != nil && err != redis.Nil
+}
+
+// pipelineError 返回管道中第一个失败命令的错误
+func pipelineError(cmds []redis.Cmder) error {
+	for _, cmd := range cmds {
+		if err := cmd.Err(); isCommandError(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// DefaultLatencyBuckets 默认延迟直方图的桶上界
+var DefaultLatencyBuckets = []time.Duration{
+	100 * time.Microsecond,
+	500 * time.Microsecond,
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+}
+
+// CommandStats 单个命令的统计
+type CommandStats struct {
+	Name      string          `json:"name"`       // 命令名（小写），管道为 "pipeline"
+	Calls     int64           `json:"calls"`      // 调用次数
+	Errors    int64           `json:"errors"`     // 失败次数，不包括 redis.Nil
+	TotalTime time.Duration   `json:"total_time"` // 总耗时
+	MaxTime   time.Duration   `json:"max_time"`   // 最大耗时
+	Buckets   []time.Duration `json:"buckets"`    // 直方图的桶上界
+	Counts    []int64         `json:"counts"`     // 各桶的次数，最后一个为超过所有上界的次数
+}
+
+// AvgTime 返回平均耗时
+func (s CommandStats) AvgTime() time.Duration {
+	if s.Calls == 0 {
+		return 0
+	}
+	return s.TotalTime / time.Duration(s.Calls)
+}
+
+// Percentile 根据直方图估算百分位耗时（p取0-100），返回所在桶的上界，落在最后一个桶时返回 MaxTime
+func (s CommandStats) Percentile(p float64) time.Duration {
+	if s.Calls == 0 {
+		return 0
+	}
+	rank := int64(math.Ceil(p / 100 * float64(s.Calls)))
+	if rank < 1 {
+		rank = 1
+	}
+	var count int64
+	for i, n := range s.Counts {
+		count += n
+		if count >= rank {
+			if i < len(s.Buckets) {
+				return s.Buckets[i]
+			}
+			break
+		}
+	}
+	return s.MaxTime
+}
+
+// MetricsHook 记录每个命令的调用次数、失败次数和延迟直方图，管道整体记为一次 "pipeline" 调用
+type MetricsHook struct {
+	buckets  []time.Duration
+	mu       sync.Mutex
+	commands map[string]*CommandStats
+}
+
+// NewMetricsHook 创建指标钩子，buckets为直方图的桶上界，为空时使用 DefaultLatencyBuckets
+func NewMetricsHook(buckets ...time.Duration) *MetricsHook {
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+	sorted := append([]time.Duration(nil), buckets...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return &MetricsHook{
+		buckets:  sorted,
+		commands: make(map[string]*CommandStats),
+	}
+}
+
+// ProcessHook 实现 Hook
+func (h *MetricsHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmd)
+		h.record(cmd.Name(), time.Since(start), isCommandError(err))
+		return err
+	}
+}
+
+// ProcessPipelineHook 实现 Hook
+func (h *MetricsHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmds)
+		h.record(pipelineName, time.Since(start), isCommandError(err) || pipelineError(cmds) != nil)
+		return err
+	}
+}
+
+// record 记录一次调用
+func (h *MetricsHook) record(name string, elapsed time.Duration, failed bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	stats, ok := h.commands[name]
+	if !ok {
+		stats = &CommandStats{
+			Name:    name,
+			Buckets: h.buckets,
+			Counts:  make([]int64, len(h.buckets)+1),
+		}
+		h.commands[name] = stats
+	}
+	stats.Calls++
+	if failed {
+		stats.Errors++
+	}
+	stats.TotalTime += elapsed
+	if elapsed > stats.MaxTime {
+		stats.MaxTime = elapsed
+	}
+	bucket := sort.Search(len(h.buckets), func(i int) bool { return elapsed <= h.buckets[i] })
+	stats.Counts[bucket]++
+}
+
+// Stats 返回所有命令的统计，按命令名排序
+func (h *MetricsHook) Stats() []CommandStats {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	stats := make([]CommandStats, 0, len(h.commands))
+	for _, s := range h.commands {
+		snapshot := *s
+		snapshot.Counts = append([]int64(nil), s.Counts...)
+		stats = append(stats, snapshot)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Name < stats[j].Name })
+	return stats
+}
+
+// Command 返回指定命令的统计，命令名不区分大小写
+func (h *MetricsHook) Command(name string) (CommandStats, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s, ok := h.commands[strings.ToLower(name)]
+	if !ok {
+		return CommandStats{}, false
+	}
+	snapshot := *s
+	snapshot.Counts = append([]int64(nil), s.Counts...)
+	return snapshot, true
+}
+
+// Reset 清空统计
+func (h *MetricsHook) Reset() {
+	h.mu.Lock()
+	h.commands = make(map[string]*CommandStats)
+	h.mu.Unlock()
+}
+
+// sensitiveCommands 参数全部脱敏的命令
+var sensitiveCommands = map[string]bool{
+	"auth":    true,
+	"hello":   true,
+	"migrate": true,
+	"config":  true,
+	"acl":     true,
+}
+
+// Redactor 将命令参数转换为可记录的文本，args包含命令名
+type Redactor func(args []interface{}) string
+
+// RedactArgs 默认的参数脱敏：只保留命令名和第一个参数（通常是键），其余参数以 ? 代替；
+// AUTH、HELLO、CONFIG 等命令的参数全部隐藏
+func RedactArgs(args []interface{}) string {
+	if len(args) == 0 {
+		return ""
+	}
+	parts := make([]string, len(args))
+	parts[0] = fmt.Sprint(args[0])
+	sensitive := sensitiveCommands[strings.ToLower(parts[0])]
+	for i := 1; i < len(args); i++ {
+		if i == 1 && !sensitive {
+			parts[i] = truncateArg(fmt.Sprint(args[i]), 64)
+		} else {
+			parts[i] = "?"
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// truncateArg 截断过长的参数
+func truncateArg(arg string, max int) string {
+	if len(arg) <= max {
+		return arg
+	}
+	return arg[:max] + "..."
+}
+
+// SlowLogConfig 慢命令日志配置
+type SlowLogConfig struct {
+	Threshold      time.Duration // 耗时超过该值的命令记录警告日志
+	Logger         Logger        // 日志记录器，tool-kit/log 的 Logger 可以直接使用，nil时使用 DefaultLogger
+	Redactor       Redactor      // 参数脱敏函数，nil时使用 RedactArgs
+	MaxPipelineCmd int           // 慢管道日志中最多列出的命令数
+}
+
+// DefaultSlowLogConfig 默认慢命令日志配置
+func DefaultSlowLogConfig() *SlowLogConfig {
+	return &SlowLogConfig{
+		Threshold:      100 * time.Millisecond,
+		MaxPipelineCmd: 5,
+	}
+}
+
+// SlowLogHook 记录耗时超过阈值的命令和管道
+type SlowLogHook struct {
+	config *SlowLogConfig
+}
+
+// NewSlowLogHook 创建慢命令日志钩子，config为nil时使用默认配置
+func NewSlowLogHook(config *SlowLogConfig) *SlowLogHook {
+	if config == nil {
+		config = DefaultSlowLogConfig()
+	}
+	cfg := *config
+	defaults := DefaultSlowLogConfig()
+	if cfg.Threshold <= 0 {
+		cfg.Threshold = defaults.Threshold
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = &DefaultLogger{}
+	}
+	if cfg.Redactor == nil {
+		cfg.Redactor = RedactArgs
+	}
+	if cfg.MaxPipelineCmd <= 0 {
+		cfg.MaxPipelineCmd = defaults.MaxPipelineCmd
+	}
+	return &SlowLogHook{config: &cfg}
+}
+
+// ProcessHook 实现 Hook
+func (h *SlowLogHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmd)
+		if elapsed := time.Since(start); elapsed >= h.config.Threshold {
+			h.config.Logger.Warnf("slow redis command (%v): %s%s", elapsed, h.config.Redactor(cmd.Args()), errSuffix(cmd.Err()))
+		}
+		return err
+	}
+}
+
+// ProcessPipelineHook 实现 Hook
+func (h *SlowLogHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmds)
+		if elapsed := time.Since(start); elapsed >= h.config.Threshold {
+			listed := make([]string, 0, h.config.MaxPipelineCmd+1)
+			for i, cmd := range cmds {
+				if i == h.config.MaxPipelineCmd {
+					listed = append(listed, fmt.Sprintf("... %d more", len(cmds)-i))
+					break
+				}
+				listed = append(listed, h.config.Redactor(cmd.Args()))
+			}
+			h.config.Logger.Warnf("slow redis pipeline (%v, %d commands): %s%s", elapsed, len(cmds), strings.Join(listed, "; "), errSuffix(pipelineError(cmds)))
+		}
+		return err
+	}
+}
+
+// errSuffix 日志中附加的错误信息
+func errSuffix(err error) string {
+	if !isCommandError(err) {
+		return ""
+	}
+	return fmt.Sprintf(" error: %v", err)
+}
+
+// Tracer 追踪器，Start 基于ctx中的上级追踪上下文创建子span并返回新的ctx，
+// 命令完成后调用返回的end函数。可以用少量代码适配 OpenTelemetry 等追踪系统
+type Tracer interface {
+	Start(ctx context.Context, name string, attrs map[string]string) (context.Context, func(err error))
+}
+
+// TracerFunc 函数形式的 Tracer
+type TracerFunc func(ctx context.Context, name string, attrs map[string]string) (context.Context, func(err error))
+
+// Start 实现 Tracer
+func (f TracerFunc) Start(ctx context.Context, name string, attrs map[string]string) (context.Context, func(err error)) {
+	return f(ctx, name, attrs)
+}
+
+// TracingHook 为每个命令和管道创建span，span名为 "redis.<命令名>"，
+// 并将 Start 返回的ctx传给后续钩子，使追踪上下文贯穿整个调用链
+type TracingHook struct {
+	tracer   Tracer
+	redactor Redactor
+}
+
+// NewTracingHook 创建追踪钩子，redactor为nil时使用 RedactArgs 生成 db.statement
+func NewTracingHook(tracer Tracer, redactor Redactor) *TracingHook {
+	if redactor == nil {
+		redactor = RedactArgs
+	}
+	return &TracingHook{tracer: tracer, redactor: redactor}
+}
+
+// ProcessHook 实现 Hook
+func (h *TracingHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		ctx, end := h.tracer.Start(ctx, "redis."+cmd.Name(), map[string]string{
+			"db.system":    "redis",
+			"db.operation": cmd.Name(),
+			"db.statement": h.redactor(cmd.Args()),
+		})
+		err := next(ctx, cmd)
+		end(commandError(err))
+		return err
+	}
+}
+
+// ProcessPipelineHook 实现 Hook
+func (h *TracingHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		statements := make([]string, len(cmds))
+		for i, cmd := range cmds {
+			statements[i] = h.redactor(cmd.Args())
+		}
+		ctx, end := h.tracer.Start(ctx, "redis."+pipelineName, map[string]string{
+			"db.system":             "redis",
+			"db.operation":          pipelineName,
+			"db.statement":          strings.Join(statements, "\n"),
+			"db.redis.num_commands": fmt.Sprint(len(cmds)),
+		})
+		err := next(ctx, cmds)
+		if spanErr := commandError(err); spanErr != nil {
+			end(spanErr)
+		} else {
+			end(pipelineError(cmds))
+		}
+		return err
+	}
+}
+
+// commandError 将 redis.Nil 转换为nil，避免将键不存在记为span错误
+func commandError(err error) error {
+	if !isCommandError(err) {
+		return nil
+	}
+	return err
+}
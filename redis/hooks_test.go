@@ -0,0 +1,220 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// recordingLogger 记录警告日志的测试日志器
+type recordingLogger struct {
+	DefaultLogger
+	mu    sync.Mutex
+	warns []string
+}
+
+func (l *recordingLogger) Warnf(format string, args ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.warns = append(l.warns, fmt.Sprintf(format, args...))
+}
+
+// sleepHook 让指定命令变慢
+type sleepHook struct {
+	command string
+	delay   time.Duration
+}
+
+func (h sleepHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if cmd.Name() == h.command {
+			time.Sleep(h.delay)
+		}
+		return next(ctx, cmd)
+	}
+}
+
+func (h sleepHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		if len(cmds) > 0 && cmds[0].Name() == h.command {
+			time.Sleep(h.delay)
+		}
+		return next(ctx, cmds)
+	}
+}
+
+func TestRedactArgs(t *testing.T) {
+	tests := []struct {
+		args     []interface{}
+		expected string
+	}{
+		{[]interface{}{"set", "user:1", "secret", "ex", 10}, "set user:1 ? ? ?"},
+		{[]interface{}{"auth", "admin", "password"}, "auth ? ?"},
+		{[]interface{}{"get", strings.Repeat("k", 70)}, "get " + strings.Repeat("k", 64) + "..."},
+		{[]interface{}{"ping"}, "ping"},
+	}
+	for _, test := range tests {
+		if got := RedactArgs(test.args); got != test.expected {
+			t.Errorf("RedactArgs(%v) = %q, expected %q", test.args, got, test.expected)
+		}
+	}
+}
+
+func TestCommandStatsPercentile(t *testing.T) {
+	stats := CommandStats{
+		Calls:   10,
+		MaxTime: 3 * time.Second,
+		Buckets: []time.Duration{time.Millisecond, 10 * time.Millisecond},
+		Counts:  []int64{8, 1, 1},
+	}
+	if got := stats.Percentile(50); got != time.Millisecond {
+		t.Errorf("Expected p50 1ms, got %v", got)
+	}
+	if got := stats.Percentile(90); got != 10*time.Millisecond {
+		t.Errorf("Expected p90 10ms, got %v", got)
+	}
+	if got := stats.Percentile(99); got != 3*time.Second {
+		t.Errorf("Expected p99 to fall back to MaxTime, got %v", got)
+	}
+}
+
+func TestMetricsHook(t *testing.T) {
+	client := newTestClient(t)
+	defer client.Close()
+
+	ctx := context.Background()
+	metrics := NewMetricsHook()
+	client.AddHook(metrics)
+	defer client.Del(ctx, "test:hooks:metrics", "test:hooks:list")
+
+	str := client.NewString()
+	str.Set(ctx, "test:hooks:metrics", "v", 0)
+	str.Get(ctx, "test:hooks:metrics")
+	str.Get(ctx, "test:hooks:missing")
+	client.NewList().LPush(ctx, "test:hooks:list", "x")
+	if _, err := client.NewHash().HGet(ctx, "test:hooks:list", "f"); err == nil {
+		t.Fatal("Expected WRONGTYPE error")
+	}
+
+	pipe := client.NewPipeline()
+	pipe.Incr(ctx, "test:hooks:list")
+	pipe.Get(ctx, "test:hooks:metrics")
+	pipe.Exec(ctx)
+
+	get, ok := metrics.Command("GET")
+	if !ok || get.Calls != 2 || get.Errors != 0 {
+		t.Errorf("Expected 2 GET calls without errors (redis.Nil is not an error), got %+v", get)
+	}
+	var total int64
+	for _, n := range get.Counts {
+		total += n
+	}
+	if total != 2 || get.AvgTime() <= 0 || get.MaxTime < get.AvgTime() {
+		t.Errorf("Unexpected histogram %+v", get)
+	}
+	if hget, _ := metrics.Command("hget"); hget.Errors != 1 {
+		t.Errorf("Expected 1 HGET error, got %+v", hget)
+	}
+	if pipeline, _ := metrics.Command("pipeline"); pipeline.Calls != 1 || pipeline.Errors != 1 {
+		t.Errorf("Expected 1 failed pipeline, got %+v", pipeline)
+	}
+
+	metrics.Reset()
+	if len(metrics.Stats()) != 0 {
+		t.Error("Expected stats to be empty after Reset")
+	}
+}
+
+func TestSlowLogHook(t *testing.T) {
+	client := newTestClient(t)
+	defer client.Close()
+
+	ctx := context.Background()
+	logger := &recordingLogger{}
+	client.AddHook(NewSlowLogHook(&SlowLogConfig{Threshold: 20 * time.Millisecond, Logger: logger, MaxPipelineCmd: 1}))
+	client.AddHook(sleepHook{command: "set", delay: 30 * time.Millisecond})
+	defer client.Del(ctx, "test:hooks:slow")
+
+	str := client.NewString()
+	str.Set(ctx, "test:hooks:slow", "secret-value", 0)
+	str.Get(ctx, "test:hooks:slow")
+
+	pipe := client.NewPipeline()
+	pipe.Set(ctx, "test:hooks:slow", "secret-value", 0)
+	pipe.Get(ctx, "test:hooks:slow")
+	pipe.Exec(ctx)
+
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	if len(logger.warns) != 2 {
+		t.Fatalf("Expected 2 slow logs, got %v", logger.warns)
+	}
+	if !strings.Contains(logger.warns[0], "set test:hooks:slow ?") || strings.Contains(logger.warns[0], "secret-value") {
+		t.Errorf("Expected redacted slow command log, got %q", logger.warns[0])
+	}
+	if !strings.Contains(logger.warns[1], "2 commands): set test:hooks:slow ?;") || !strings.Contains(logger.warns[1], "... 1 more") {
+		t.Errorf("Unexpected slow pipeline log %q", logger.warns[1])
+	}
+}
+
+type spanKey struct{}
+
+func TestTracingHook(t *testing.T) {
+	client := newTestClient(t)
+	defer client.Close()
+
+	var mu sync.Mutex
+	var spans []string
+	tracer := TracerFunc(func(ctx context.Context, name string, attrs map[string]string) (context.Context, func(err error)) {
+		parent, _ := ctx.Value(spanKey{}).(string)
+		return context.WithValue(ctx, spanKey{}, name), func(err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			spans = append(spans, fmt.Sprintf("%s parent=%s statement=%q err=%v", name, parent, attrs["db.statement"], err))
+		}
+	})
+
+	// 追踪钩子之后的钩子能看到span上下文
+	var seen string
+	client.AddHook(NewTracingHook(tracer, nil), ctxProbeHook{seen: &seen})
+
+	ctx := context.WithValue(context.Background(), spanKey{}, "http.request")
+	defer client.Del(ctx, "test:hooks:trace")
+	client.NewString().Set(ctx, "test:hooks:trace", "v", 0)
+	client.NewString().Get(ctx, "test:hooks:missing")
+	client.NewHash().HGet(ctx, "test:hooks:trace", "f")
+
+	mu.Lock()
+	defer mu.Unlock()
+	expected := []string{
+		`redis.set parent=http.request statement="set test:hooks:trace ?" err=<nil>`,
+		`redis.get parent=http.request statement="get test:hooks:missing" err=<nil>`,
+	}
+	if len(spans) != 3 || spans[0] != expected[0] || spans[1] != expected[1] || !strings.Contains(spans[2], "WRONGTYPE") {
+		t.Errorf("Unexpected spans %v", spans)
+	}
+	if seen != "redis.hget" {
+		t.Errorf("Expected span context to propagate to later hooks, got %q", seen)
+	}
+}
+
+// ctxProbeHook 记录命令执行时上下文中的span
+type ctxProbeHook struct {
+	seen *string
+}
+
+func (h ctxProbeHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		*h.seen, _ = ctx.Value(spanKey{}).(string)
+		return next(ctx, cmd)
+	}
+}
+
+func (h ctxProbeHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return next
+}
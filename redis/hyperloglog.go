@@ -0,0 +1,33 @@
+package redis
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// HyperLogLog 基数统计操作，以约0.81%的标准误差统计不重复元素数量，每个键最多占用12KB
+type HyperLogLog struct {
+	client redis.UniversalClient
+	prefix keyPrefix
+}
+
+// NewHyperLogLog 创建基数统计操作实例
+func (c *Client) NewHyperLogLog() *HyperLogLog {
+	return &HyperLogLog{client: c.client, prefix: c.prefix}
+}
+
+// PFAdd 添加元素，估算的基数发生变化时返回1
+func (h *HyperLogLog) PFAdd(ctx context.Context, key string, elements ...interface{}) (int64, error) {
+	return h.client.PFAdd(ctx, h.prefix.key(key), elements...).Result()
+}
+
+// PFCount 返回基数估算值，多个键时返回并集的基数
+func (h *HyperLogLog) PFCount(ctx context.Context, keys ...string) (int64, error) {
+	return h.client.PFCount(ctx, h.prefix.keys(keys)...).Result()
+}
+
+// PFMerge 将多个键合并到目标键
+func (h *HyperLogLog) PFMerge(ctx context.Context, dest string, keys ...string) error {
+	return h.client.PFMerge(ctx, h.prefix.key(dest), h.prefix.keys(keys)...).Err()
+}
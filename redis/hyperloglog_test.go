@@ -0,0 +1,40 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestHyperLogLog(t *testing.T) {
+	client := newTestClient(t)
+	defer client.Close()
+
+	ctx := context.Background()
+	hll := client.WithNamespace("test:hll").NewHyperLogLog()
+	defer client.Del(ctx, "test:hll:mon", "test:hll:tue", "test:hll:week")
+
+	for i := 0; i < 1000; i++ {
+		hll.PFAdd(ctx, "mon", fmt.Sprintf("user:%d", i))
+	}
+	for i := 500; i < 1500; i++ {
+		hll.PFAdd(ctx, "tue", fmt.Sprintf("user:%d", i))
+	}
+	hll.PFAdd(ctx, "mon", "user:1", "user:2")
+
+	within := func(got, want int64) bool {
+		return float64(got) > float64(want)*0.97 && float64(got) < float64(want)*1.03
+	}
+	if count, err := hll.PFCount(ctx, "mon"); err != nil || !within(count, 1000) {
+		t.Errorf("Expected about 1000, got %d %v", count, err)
+	}
+	if err := hll.PFMerge(ctx, "week", "mon", "tue"); err != nil {
+		t.Fatalf("PFMerge failed: %v", err)
+	}
+	if count, _ := hll.PFCount(ctx, "week"); !within(count, 1500) {
+		t.Errorf("Expected merged about 1500, got %d", count)
+	}
+	if count, _ := client.NewHyperLogLog().PFCount(ctx, "test:hll:week"); !within(count, 1500) {
+		t.Errorf("Expected merged about 1500, got %d", count)
+	}
+}
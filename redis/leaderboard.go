@@ -0,0 +1,540 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrLeaderboardMerged 周期榜已经合并到总榜
+var ErrLeaderboardMerged = errors.New("redis: leaderboard period already merged")
+
+// 排行榜使用的键，均以 {name} 为哈希标签：
+//
+//	all                总榜有序集合，成员为 "<13位时间>:<成员ID>"，分数为实际分数
+//	all:idx            总榜成员ID -> 有序集合中的成员
+//	daily:<日期>        日榜，结构同总榜，到期自动删除
+//	weekly:<年W周>      周榜，结构同总榜，到期自动删除
+//	meta               成员ID -> 成员元数据（JSON）
+//	merged:<周期榜>     周期榜已合并到总榜的标记
+//
+// 有序集合中分数相同的成员按成员字符串排序，成员前缀中的时间使同分时先达到该分数的成员排名靠前
+
+// lbMaxTime 降序排行榜的时间前缀为 lbMaxTime - 毫秒时间戳，使反向字典序等于时间正序
+const lbMaxTime = 9999999999999
+
+// leaderboardApplySource 按模式更新榜单中的一个成员，返回更新后该成员在有序集合中的编码
+const leaderboardApplySource = `
+local function apply(board, index, id, score, member, mode, asc, ts)
+    local newScore = score
+    local replace = true
+    local old = redis.call('HGET', index, id)
+    if old then
+        local oldScore = tonumber(redis.call('ZSCORE', board, old))
+        if mode == 'best' then
+            if asc then
+                replace = score < oldScore
+            else
+                replace = score > oldScore
+            end
+        elseif mode == 'sum' then
+            newScore = oldScore + score
+        elseif mode == 'latest' then
+            local oldTs = tonumber(string.sub(old, 1, 13))
+            if not asc then
+                oldTs = 9999999999999 - oldTs
+            end
+            replace = ts >= oldTs
+        end
+        if replace then
+            redis.call('ZREM', board, old)
+        end
+    end
+    if replace then
+        redis.call('ZADD', board, newScore, member)
+        redis.call('HSET', index, id, member)
+        old = member
+    end
+    return old
+end
+`
+
+// leaderboardSubmitScript 按模式更新一个或多个榜单中的成员。
+// KEYS 为 (有序集合, 索引) 对；ARGV: 成员ID、分数、编码后的成员、模式、是否升序、时间戳、各榜单的过期时间（毫秒，0不过期）。
+// 返回第一个榜单中提交后的分数
+var leaderboardSubmitScript = redis.NewScript(leaderboardApplySource + `
+local id, score, member, mode, asc, ts = ARGV[1], tonumber(ARGV[2]), ARGV[3], ARGV[4], ARGV[5] == '1', tonumber(ARGV[6])
+local result
+for i = 1, #KEYS, 2 do
+    local board, index = KEYS[i], KEYS[i + 1]
+    local current = apply(board, index, id, score, member, mode, asc, ts)
+    local expireAt = tonumber(ARGV[6 + (i + 1) / 2])
+    if expireAt > 0 then
+        redis.call('PEXPIREAT', board, expireAt)
+        redis.call('PEXPIREAT', index, expireAt)
+    end
+    if not result then
+        result = redis.call('ZSCORE', board, current)
+    end
+end
+return result`)
+
+// leaderboardMergeScript 将周期榜按模式合并到总榜，全部成员合并后才写入合并标记。
+// KEYS: 周期榜、总榜、总榜索引、合并标记；ARGV: 模式、是否升序、标记的值、标记的过期时间（毫秒）。
+// 返回合并的成员数，已合并过时返回-1
+var leaderboardMergeScript = redis.NewScript(leaderboardApplySource + `
+if redis.call('EXISTS', KEYS[4]) == 1 then
+    return -1
+end
+local mode, asc = ARGV[1], ARGV[2] == '1'
+local members = redis.call('ZRANGE', KEYS[1], 0, -1, 'WITHSCORES')
+for i = 1, #members, 2 do
+    local member = members[i]
+    local id, ts = member, 0
+    if string.sub(member, 14, 14) == ':' then
+        id = string.sub(member, 15)
+        ts = tonumber(string.sub(member, 1, 13))
+        if not asc then
+            ts = 9999999999999 - ts
+        end
+    end
+    apply(KEYS[2], KEYS[3], id, tonumber(members[i + 1]), member, mode, asc, ts)
+end
+redis.call('SET', KEYS[4], ARGV[3], 'PX', ARGV[4])
+return #members / 2`)
+
+// leaderboardEntryScript 查询成员的排名、分数、编码后的成员和元数据
+// KEYS: 有序集合、索引、元数据；ARGV: 成员ID、是否升序
+var leaderboardEntryScript = redis.NewScript(`
+local member = redis.call('HGET', KEYS[2], ARGV[1])
+if not member then
+    return false
+end
+local rank
+if ARGV[2] == '1' then
+    rank = redis.call('ZRANK', KEYS[1], member)
+else
+    rank = redis.call('ZREVRANK', KEYS[1], member)
+end
+if not rank then
+    return false
+end
+return {rank, redis.call('ZSCORE', KEYS[1], member), member, redis.call('HGET', KEYS[3], ARGV[1]) or ''}`)
+
+// leaderboardRemoveScript 从多个榜单中删除成员，KEYS 为 (有序集合, 索引) 对；ARGV: 成员ID
+var leaderboardRemoveScript = redis.NewScript(`
+local removed = 0
+for i = 1, #KEYS, 2 do
+    local member = redis.call('HGET', KEYS[i + 1], ARGV[1])
+    if member then
+        removed = removed + redis.call('ZREM', KEYS[i], member)
+        redis.call('HDEL', KEYS[i + 1], ARGV[1])
+    end
+end
+return removed`)
+
+// ScoreMode 提交分数的模式
+type ScoreMode string
+
+const (
+	// ScoreBest 只保留最好成绩，成绩相同时保留先达到的记录
+	ScoreBest ScoreMode = "best"
+	// ScoreLatest 总是使用最新提交的成绩
+	ScoreLatest ScoreMode = "latest"
+	// ScoreSum 累加每次提交的分数，同分时按最后一次提交的时间排序
+	ScoreSum ScoreMode = "sum"
+)
+
+// Period 榜单周期
+type Period string
+
+const (
+	// PeriodAllTime 总榜
+	PeriodAllTime Period = "all"
+	// PeriodDaily 日榜
+	PeriodDaily Period = "daily"
+	// PeriodWeekly 周榜，按ISO周计算，周一开始
+	PeriodWeekly Period = "weekly"
+)
+
+// LeaderboardConfig 排行榜配置
+type LeaderboardConfig struct {
+	Mode         ScoreMode      `json:"mode" yaml:"mode"`                     // 提交模式，默认 ScoreBest
+	Ascending    bool           `json:"ascending" yaml:"ascending"`           // 分数越低排名越高，例如用时
+	Periods      []Period       `json:"periods" yaml:"periods"`               // 除总榜外同时维护的周期榜
+	Retention    int            `json:"retention" yaml:"retention"`           // 周期榜结束后继续保留的周期数，之后自动过期
+	Location     *time.Location `json:"-" yaml:"-"`                           // 划分周期使用的时区，默认UTC
+	MergeAllTime bool           `json:"merge_all_time" yaml:"merge_all_time"` // 提交时不更新总榜，由 MergePeriod 将周期榜合并到总榜
+}
+
+// DefaultLeaderboardConfig 默认排行榜配置
+func DefaultLeaderboardConfig() *LeaderboardConfig {
+	return &LeaderboardConfig{
+		Mode:      ScoreBest,
+		Retention: 1,
+		Location:  time.UTC,
+	}
+}
+
+// Leaderboard 排行榜，支持同分按达成时间排序、周期榜和成员元数据
+type Leaderboard struct {
+	client redis.UniversalClient
+	prefix keyPrefix
+	name   string
+	config *LeaderboardConfig
+	now    func() time.Time
+}
+
+// NewLeaderboard 创建排行榜，config为nil时使用默认配置
+func (c *Client) NewLeaderboard(name string, config *LeaderboardConfig) *Leaderboard {
+	if config == nil {
+		config = DefaultLeaderboardConfig()
+	}
+	cfg := *config
+	defaults := DefaultLeaderboardConfig()
+	if cfg.Mode == "" {
+		cfg.Mode = defaults.Mode
+	}
+	if cfg.Retention < 0 {
+		cfg.Retention = 0
+	}
+	if cfg.Location == nil {
+		cfg.Location = defaults.Location
+	}
+	return &Leaderboard{
+		client: c.client,
+		prefix: c.prefix,
+		name:   name,
+		config: &cfg,
+		now:    time.Now,
+	}
+}
+
+// LeaderboardEntry 排行榜条目
+type LeaderboardEntry struct {
+	Member string            `json:"member"`         // 成员ID
+	Score  float64           `json:"score"`          // 分数
+	Rank   int64             `json:"rank"`           // 排名，从1开始
+	Time   time.Time         `json:"time"`           // 达到当前分数的时间，同分时先达到的排名靠前
+	Meta   map[string]string `json:"meta,omitempty"` // 成员元数据
+}
+
+// key 返回排行榜的键
+func (lb *Leaderboard) key(suffix string) string {
+	return lb.prefix.key("{" + lb.name + "}:" + suffix)
+}
+
+// Submit 以当前时间提交分数，返回提交后在第一个更新的榜单（通常是总榜）上的分数
+func (lb *Leaderboard) Submit(ctx context.Context, member string, score float64) (float64, error) {
+	return lb.SubmitAt(ctx, member, score, lb.now())
+}
+
+// SubmitAt 以指定时间提交分数，时间决定同分时的先后和写入的周期榜
+func (lb *Leaderboard) SubmitAt(ctx context.Context, member string, score float64, at time.Time) (float64, error) {
+	if member == "" {
+		return 0, errors.New("leaderboard member cannot be empty")
+	}
+
+	var keys []string
+	var expires []interface{}
+	if !lb.config.MergeAllTime {
+		board := lb.AllTime()
+		keys = append(keys, board.key, board.index)
+		expires = append(expires, 0)
+	}
+	for _, period := range lb.config.Periods {
+		board := lb.Board(period, at)
+		keys = append(keys, board.key, board.index)
+		expires = append(expires, lb.expireAt(period, at).UnixMilli())
+	}
+	if len(keys) == 0 {
+		return 0, errors.New("leaderboard has no board to submit to")
+	}
+	return lb.submit(ctx, keys, member, score, at, expires...)
+}
+
+// submit 执行提交脚本
+func (lb *Leaderboard) submit(ctx context.Context, keys []string, member string, score float64, at time.Time, expires ...interface{}) (float64, error) {
+	ts := at.UnixMilli()
+	args := []interface{}{member, score, lb.encodeMember(member, ts), string(lb.config.Mode), lb.ascending(), ts}
+	args = append(args, expires...)
+	result, err := leaderboardSubmitScript.Run(ctx, lb.client, keys, args...).Text()
+	if err != nil {
+		return 0, fmt.Errorf("failed to submit score: %w", err)
+	}
+	return strconv.ParseFloat(result, 64)
+}
+
+// ascending 返回脚本参数中的排序方向
+func (lb *Leaderboard) ascending() string {
+	if lb.config.Ascending {
+		return "1"
+	}
+	return "0"
+}
+
+// encodeMember 将成员ID和时间编码为有序集合的成员
+func (lb *Leaderboard) encodeMember(member string, ts int64) string {
+	if !lb.config.Ascending {
+		ts = lbMaxTime - ts
+	}
+	return fmt.Sprintf("%013d:%s", ts, member)
+}
+
+// decodeMember 从有序集合的成员中解析成员ID和时间
+func (lb *Leaderboard) decodeMember(encoded string) (string, time.Time) {
+	if len(encoded) < 14 || encoded[13] != ':' {
+		return encoded, time.Time{}
+	}
+	ts, err := strconv.ParseInt(encoded[:13], 10, 64)
+	if err != nil {
+		return encoded, time.Time{}
+	}
+	if !lb.config.Ascending {
+		ts = lbMaxTime - ts
+	}
+	return encoded[14:], time.UnixMilli(ts)
+}
+
+// periodStart 返回t所在周期的开始时间
+func (lb *Leaderboard) periodStart(period Period, t time.Time) time.Time {
+	t = t.In(lb.config.Location)
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, lb.config.Location)
+	if period == PeriodWeekly {
+		// 周一为一周的第一天
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset)
+	}
+	return day
+}
+
+// periodSuffix 返回周期榜的键后缀
+func (lb *Leaderboard) periodSuffix(period Period, t time.Time) string {
+	t = t.In(lb.config.Location)
+	switch period {
+	case PeriodDaily:
+		return "daily:" + t.Format("20060102")
+	case PeriodWeekly:
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("weekly:%04dW%02d", year, week)
+	default:
+		return string(PeriodAllTime)
+	}
+}
+
+// expireAt 返回周期榜的过期时间：周期结束后再保留 Retention 个周期
+func (lb *Leaderboard) expireAt(period Period, t time.Time) time.Time {
+	start := lb.periodStart(period, t)
+	if period == PeriodWeekly {
+		return start.AddDate(0, 0, 7*(1+lb.config.Retention))
+	}
+	return start.AddDate(0, 0, 1+lb.config.Retention)
+}
+
+// SetMeta 设置成员元数据，例如昵称、头像，查询条目时一并返回
+func (lb *Leaderboard) SetMeta(ctx context.Context, member string, meta map[string]string) error {
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+	return lb.client.HSet(ctx, lb.key("meta"), member, data).Err()
+}
+
+// Meta 获取成员元数据，不存在时返回nil
+func (lb *Leaderboard) Meta(ctx context.Context, member string) (map[string]string, error) {
+	data, err := lb.client.HGet(ctx, lb.key("meta"), member).Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return decodeLeaderboardMeta(data)
+}
+
+// decodeLeaderboardMeta 解码元数据
+func decodeLeaderboardMeta(data string) (map[string]string, error) {
+	if data == "" {
+		return nil, nil
+	}
+	var meta map[string]string
+	if err := json.Unmarshal([]byte(data), &meta); err != nil {
+		return nil, fmt.Errorf("failed to decode leaderboard meta: %w", err)
+	}
+	return meta, nil
+}
+
+// Remove 从总榜、当前周期榜中删除成员并删除其元数据，返回删除的榜单条目数
+func (lb *Leaderboard) Remove(ctx context.Context, member string) (int64, error) {
+	boards := append([]*LeaderboardBoard{lb.AllTime()}, lb.currentBoards()...)
+	keys := make([]string, 0, len(boards)*2)
+	for _, board := range boards {
+		keys = append(keys, board.key, board.index)
+	}
+	removed, err := leaderboardRemoveScript.Run(ctx, lb.client, keys, member).Int64()
+	if err != nil {
+		return 0, err
+	}
+	return removed, lb.client.HDel(ctx, lb.key("meta"), member).Err()
+}
+
+// currentBoards 返回当前时间所在的周期榜
+func (lb *Leaderboard) currentBoards() []*LeaderboardBoard {
+	now := lb.now()
+	boards := make([]*LeaderboardBoard, 0, len(lb.config.Periods))
+	for _, period := range lb.config.Periods {
+		boards = append(boards, lb.Board(period, now))
+	}
+	return boards
+}
+
+// MergePeriod 将t所在的周期榜按提交模式合并到总榜，返回合并的成员数，用于开启 MergeAllTime 的排行榜。
+// 每个周期榜只能合并一次，重复合并返回 ErrLeaderboardMerged。合并在一个脚本中执行，每个成员只合并一次，
+// 全部合并后才写入合并标记，失败时可以重新合并；周期榜很大时会较长时间阻塞Redis
+func (lb *Leaderboard) MergePeriod(ctx context.Context, period Period, t time.Time) (int64, error) {
+	if period == PeriodAllTime {
+		return 0, errors.New("cannot merge the all-time board into itself")
+	}
+	source := lb.Board(period, t)
+	target := lb.AllTime()
+	marker := lb.key("merged:" + lb.periodSuffix(period, t))
+	ttl := time.Until(lb.expireAt(period, t))
+	if ttl < 24*time.Hour {
+		ttl = 24 * time.Hour
+	}
+
+	keys := []string{source.key, target.key, target.index, marker}
+	merged, err := leaderboardMergeScript.Run(ctx, lb.client, keys, string(lb.config.Mode), lb.ascending(), time.Now().UnixMilli(), ttl.Milliseconds()).Int64()
+	if err != nil {
+		return 0, fmt.Errorf("failed to merge period: %w", err)
+	}
+	if merged < 0 {
+		return 0, ErrLeaderboardMerged
+	}
+	return merged, nil
+}
+
+// AllTime 返回总榜
+func (lb *Leaderboard) AllTime() *LeaderboardBoard {
+	return lb.Board(PeriodAllTime, time.Time{})
+}
+
+// Board 返回t所在周期的榜单，period为 PeriodAllTime 时忽略t
+func (lb *Leaderboard) Board(period Period, t time.Time) *LeaderboardBoard {
+	suffix := lb.periodSuffix(period, t)
+	return &LeaderboardBoard{
+		lb:    lb,
+		key:   lb.key(suffix),
+		index: lb.key(suffix + ":idx"),
+	}
+}
+
+// LeaderboardBoard 排行榜中的一个榜单（总榜或某个周期的周期榜）
+type LeaderboardBoard struct {
+	lb    *Leaderboard
+	key   string
+	index string
+}
+
+// Count 返回榜单中的成员数
+func (b *LeaderboardBoard) Count(ctx context.Context) (int64, error) {
+	return b.lb.client.ZCard(ctx, b.key).Result()
+}
+
+// Entry 返回成员的条目，成员不在榜单中时返回 redis.Nil
+func (b *LeaderboardBoard) Entry(ctx context.Context, member string) (*LeaderboardEntry, error) {
+	result, err := leaderboardEntryScript.Run(ctx, b.lb.client, []string{b.key, b.index, b.lb.key("meta")}, member, b.lb.ascending()).Slice()
+	if err != nil {
+		return nil, err
+	}
+	if len(result) != 4 {
+		return nil, fmt.Errorf("unexpected leaderboard entry reply: %v", result)
+	}
+
+	rank, _ := result[0].(int64)
+	score, err := strconv.ParseFloat(fmt.Sprint(result[1]), 64)
+	if err != nil {
+		return nil, err
+	}
+	id, at := b.lb.decodeMember(fmt.Sprint(result[2]))
+	meta, err := decodeLeaderboardMeta(fmt.Sprint(result[3]))
+	if err != nil {
+		return nil, err
+	}
+	return &LeaderboardEntry{Member: id, Score: score, Rank: rank + 1, Time: at, Meta: meta}, nil
+}
+
+// Rank 返回成员的排名（从1开始），成员不在榜单中时返回 redis.Nil
+func (b *LeaderboardBoard) Rank(ctx context.Context, member string) (int64, error) {
+	entry, err := b.Entry(ctx, member)
+	if err != nil {
+		return 0, err
+	}
+	return entry.Rank, nil
+}
+
+// Page 返回第page页（从1开始）的条目，每页size个
+func (b *LeaderboardBoard) Page(ctx context.Context, page, size int64) ([]*LeaderboardEntry, error) {
+	if page < 1 || size < 1 {
+		return nil, fmt.Errorf("invalid page %d or size %d", page, size)
+	}
+	start := (page - 1) * size
+	return b.rangeEntries(ctx, start, start+size-1)
+}
+
+// Around 返回成员及其前后各n名的条目，成员不在榜单中时返回 redis.Nil
+func (b *LeaderboardBoard) Around(ctx context.Context, member string, n int64) ([]*LeaderboardEntry, error) {
+	rank, err := b.Rank(ctx, member)
+	if err != nil {
+		return nil, err
+	}
+	start := rank - 1 - n
+	if start < 0 {
+		start = 0
+	}
+	return b.rangeEntries(ctx, start, rank-1+n)
+}
+
+// rangeEntries 返回排名区间[start, stop]（从0开始）的条目并附带元数据
+func (b *LeaderboardBoard) rangeEntries(ctx context.Context, start, stop int64) ([]*LeaderboardEntry, error) {
+	values, err := b.lb.client.ZRangeArgsWithScores(ctx, redis.ZRangeArgs{
+		Key:   b.key,
+		Start: start,
+		Stop:  stop,
+		Rev:   !b.lb.config.Ascending,
+	}).Result()
+	if err != nil || len(values) == 0 {
+		return nil, err
+	}
+
+	entries := make([]*LeaderboardEntry, len(values))
+	members := make([]string, len(values))
+	for i, z := range values {
+		id, at := b.lb.decodeMember(fmt.Sprint(z.Member))
+		entries[i] = &LeaderboardEntry{Member: id, Score: z.Score, Rank: start + int64(i) + 1, Time: at}
+		members[i] = id
+	}
+
+	metas, err := b.lb.client.HMGet(ctx, b.lb.key("meta"), members...).Result()
+	if err != nil {
+		return nil, err
+	}
+	for i, data := range metas {
+		if s, ok := data.(string); ok {
+			if entries[i].Meta, err = decodeLeaderboardMeta(s); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return entries, nil
+}
+
+// Clear 删除榜单
+func (b *LeaderboardBoard) Clear(ctx context.Context) error {
+	return b.lb.client.Del(ctx, b.key, b.index).Err()
+}
@@ -0,0 +1,241 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func entryMembers(entries []*LeaderboardEntry) []string {
+	members := make([]string, len(entries))
+	for i, entry := range entries {
+		members[i] = entry.Member
+	}
+	return members
+}
+
+func equalMembers(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestLeaderboardBest(t *testing.T) {
+	client := newTestClient(t)
+	defer client.Close()
+
+	ctx := context.Background()
+	lb := client.NewLeaderboard("test:lb:best", nil)
+	defer client.DeleteByPattern(ctx, "{test:lb:best}:*", nil)
+
+	base := time.Now().Add(-time.Hour)
+	lb.SubmitAt(ctx, "alice", 100, base)
+	lb.SubmitAt(ctx, "bob", 100, base.Add(time.Second)) // 同分，后达到
+	lb.SubmitAt(ctx, "carol", 300, base)
+	lb.SubmitAt(ctx, "dave", 50, base)
+
+	// 更差的成绩不会覆盖最好成绩
+	score, err := lb.SubmitAt(ctx, "carol", 200, base.Add(time.Minute))
+	if err != nil || score != 300 {
+		t.Errorf("Expected best score 300, got %v %v", score, err)
+	}
+	// 更好的成绩覆盖并更新时间，同分时排在alice之后
+	lb.SubmitAt(ctx, "dave", 100, base.Add(2*time.Second))
+
+	board := lb.AllTime()
+	page, err := board.Page(ctx, 1, 10)
+	if err != nil {
+		t.Fatalf("Page failed: %v", err)
+	}
+	if got := entryMembers(page); !equalMembers(got, []string{"carol", "alice", "bob", "dave"}) {
+		t.Errorf("Unexpected order %v", got)
+	}
+	if page[1].Rank != 2 || page[1].Score != 100 || page[1].Time.UnixMilli() != base.UnixMilli() {
+		t.Errorf("Unexpected entry %+v", page[1])
+	}
+
+	if rank, err := board.Rank(ctx, "bob"); err != nil || rank != 3 {
+		t.Errorf("Expected bob rank 3, got %d %v", rank, err)
+	}
+	if _, err := board.Rank(ctx, "nobody"); err != redis.Nil {
+		t.Errorf("Expected redis.Nil for missing member, got %v", err)
+	}
+
+	lb.SetMeta(ctx, "alice", map[string]string{"name": "Alice"})
+	around, err := board.Around(ctx, "alice", 1)
+	if err != nil {
+		t.Fatalf("Around failed: %v", err)
+	}
+	if got := entryMembers(around); !equalMembers(got, []string{"carol", "alice", "bob"}) || around[1].Meta["name"] != "Alice" {
+		t.Errorf("Unexpected around window %v %+v", got, around[1])
+	}
+	around, _ = board.Around(ctx, "dave", 2)
+	if got := entryMembers(around); !equalMembers(got, []string{"alice", "bob", "dave"}) || around[0].Rank != 2 {
+		t.Errorf("Unexpected around window at the bottom %v", got)
+	}
+
+	page, _ = board.Page(ctx, 2, 3)
+	if got := entryMembers(page); !equalMembers(got, []string{"dave"}) || page[0].Rank != 4 {
+		t.Errorf("Unexpected second page %v", got)
+	}
+
+	if removed, err := lb.Remove(ctx, "carol"); err != nil || removed != 1 {
+		t.Errorf("Expected carol removed, got %d %v", removed, err)
+	}
+	if count, _ := board.Count(ctx); count != 3 {
+		t.Errorf("Expected 3 members after remove, got %d", count)
+	}
+}
+
+func TestLeaderboardModes(t *testing.T) {
+	client := newTestClient(t)
+	defer client.Close()
+
+	ctx := context.Background()
+	defer client.DeleteByPattern(ctx, "{test:lb:mode:*}:*", nil)
+
+	sum := client.NewLeaderboard("test:lb:mode:sum", &LeaderboardConfig{Mode: ScoreSum})
+	sum.Submit(ctx, "alice", 10)
+	if score, _ := sum.Submit(ctx, "alice", 2.5); score != 12.5 {
+		t.Errorf("Expected sum 12.5, got %v", score)
+	}
+
+	latest := client.NewLeaderboard("test:lb:mode:latest", &LeaderboardConfig{Mode: ScoreLatest})
+	now := time.Now()
+	latest.SubmitAt(ctx, "alice", 10, now)
+	if score, _ := latest.SubmitAt(ctx, "alice", 3, now.Add(time.Second)); score != 3 {
+		t.Errorf("Expected latest 3, got %v", score)
+	}
+	// 较早时间的提交不覆盖较新的成绩
+	if score, _ := latest.SubmitAt(ctx, "alice", 7, now.Add(-time.Second)); score != 3 {
+		t.Errorf("Expected out-of-order submit to be ignored, got %v", score)
+	}
+
+	// 升序：用时越短越好，同分先达到的靠前
+	fastest := client.NewLeaderboard("test:lb:mode:asc", &LeaderboardConfig{Ascending: true})
+	fastest.SubmitAt(ctx, "alice", 12.3, now)
+	fastest.SubmitAt(ctx, "bob", 9.8, now)
+	fastest.SubmitAt(ctx, "carol", 12.3, now.Add(-time.Second))
+	fastest.SubmitAt(ctx, "bob", 11, now)
+	page, _ := fastest.AllTime().Page(ctx, 1, 10)
+	if got := entryMembers(page); !equalMembers(got, []string{"bob", "carol", "alice"}) || page[0].Score != 9.8 {
+		t.Errorf("Unexpected ascending order %v, best %v", got, page[0].Score)
+	}
+}
+
+func TestLeaderboardPeriods(t *testing.T) {
+	client := newTestClient(t)
+	defer client.Close()
+
+	ctx := context.Background()
+	defer client.DeleteByPattern(ctx, "{test:lb:period}:*", nil)
+
+	config := &LeaderboardConfig{Mode: ScoreSum, Periods: []Period{PeriodDaily, PeriodWeekly}, MergeAllTime: true, Retention: 1}
+	lb := client.NewLeaderboard("test:lb:period", config)
+
+	// 取最近的周日和之后的周一，两天属于不同的周
+	sunday := time.Now().UTC().Truncate(24 * time.Hour)
+	for sunday.Weekday() != time.Sunday {
+		sunday = sunday.AddDate(0, 0, -1)
+	}
+	monday := sunday.AddDate(0, 0, 1).Add(time.Hour)
+	sunday = sunday.Add(time.Hour)
+
+	lb.SubmitAt(ctx, "alice", 10, sunday)
+	lb.SubmitAt(ctx, "bob", 5, sunday)
+	lb.SubmitAt(ctx, "alice", 1, monday)
+
+	if entry, _ := lb.Board(PeriodDaily, sunday).Entry(ctx, "alice"); entry == nil || entry.Score != 10 {
+		t.Errorf("Expected alice 10 on sunday board, got %+v", entry)
+	}
+	if entry, _ := lb.Board(PeriodWeekly, monday).Entry(ctx, "alice"); entry == nil || entry.Score != 1 {
+		t.Errorf("Expected alice 1 on the new week board, got %+v", entry)
+	}
+	if count, _ := lb.AllTime().Count(ctx); count != 0 {
+		t.Errorf("Expected all-time board untouched before merge, got %d", count)
+	}
+
+	ttl, _ := client.GetClient().PTTL(ctx, lb.Board(PeriodDaily, monday).key).Result()
+	expected := time.Until(monday.Truncate(24*time.Hour).AddDate(0, 0, 2))
+	if ttl <= 0 || ttl > expected+time.Second || ttl < expected-time.Minute {
+		t.Errorf("Expected daily board to expire after retention, ttl %v expected %v", ttl, expected)
+	}
+
+	for _, day := range []time.Time{sunday, monday} {
+		if _, err := lb.MergePeriod(ctx, PeriodDaily, day); err != nil {
+			t.Fatalf("MergePeriod failed: %v", err)
+		}
+	}
+	if _, err := lb.MergePeriod(ctx, PeriodDaily, sunday); !errors.Is(err, ErrLeaderboardMerged) {
+		t.Errorf("Expected ErrLeaderboardMerged, got %v", err)
+	}
+	page, _ := lb.AllTime().Page(ctx, 1, 10)
+	if len(page) != 2 || page[0].Member != "alice" || page[0].Score != 11 || page[1].Score != 5 {
+		t.Errorf("Unexpected merged board %+v", page)
+	}
+}
+
+func TestLeaderboardMergeRetry(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+	client.DeleteByPattern(ctx, "{test:lb:retry}:*", nil)
+	defer client.DeleteByPattern(ctx, "{test:lb:retry}:*", nil)
+
+	config := &LeaderboardConfig{Mode: ScoreSum, Periods: []Period{PeriodDaily}, MergeAllTime: true}
+	lb := client.NewLeaderboard("test:lb:retry", config)
+	now := time.Now()
+	for i := 0; i < 600; i++ {
+		lb.SubmitAt(ctx, fmt.Sprintf("m%d", i), 1, now)
+	}
+
+	// 合并失败时不写入标记，可以重新合并
+	target := lb.AllTime()
+	client.GetUniversalClient().Set(ctx, target.index, "broken", 0)
+	if _, err := lb.MergePeriod(ctx, PeriodDaily, now); err == nil {
+		t.Fatal("Expected merge to fail on a broken index")
+	}
+	client.GetUniversalClient().Del(ctx, target.index)
+
+	// 每个成员只合并一次，累加模式下不会重复计分
+	merged, err := lb.MergePeriod(ctx, PeriodDaily, now)
+	if err != nil || merged != 600 {
+		t.Fatalf("Expected 600 merged, got %d, %v", merged, err)
+	}
+	if count, _ := target.Count(ctx); count != 600 {
+		t.Errorf("Expected 600 members on the all-time board, got %d", count)
+	}
+	if entry, _ := target.Entry(ctx, "m42"); entry == nil || entry.Score != 1 {
+		t.Errorf("Expected m42 to be merged once, got %+v", entry)
+	}
+	if _, err := lb.MergePeriod(ctx, PeriodDaily, now); !errors.Is(err, ErrLeaderboardMerged) {
+		t.Errorf("Expected ErrLeaderboardMerged, got %v", err)
+	}
+}
+
+func TestLeaderboardPeriodKeys(t *testing.T) {
+	lb := (&Client{}).NewLeaderboard("game", &LeaderboardConfig{Retention: 2})
+	at := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC) // 周四，ISO周 2026W01
+	if got := lb.periodSuffix(PeriodWeekly, at); got != "weekly:2026W01" {
+		t.Errorf("Unexpected weekly suffix %s", got)
+	}
+	if got := lb.periodSuffix(PeriodDaily, at); got != "daily:20260101" {
+		t.Errorf("Unexpected daily suffix %s", got)
+	}
+	if got := lb.expireAt(PeriodWeekly, at); !got.Equal(time.Date(2026, 1, 19, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected weekly expiry %v", got)
+	}
+	member, at2 := lb.decodeMember(lb.encodeMember("a:b", at.UnixMilli()))
+	if member != "a:b" || !at2.Equal(at) {
+		t.Errorf("Unexpected decoded member %s %v", member, at2)
+	}
+}
@@ -9,77 +9,86 @@ import (
 
 // List 列表操作
 type List struct {
-	client *redis.Client
+	client redis.UniversalClient
+	prefix keyPrefix
 }
 
 // NewList 创建列表操作实例
 func (c *Client) NewList() *List {
-	return &List{client: c.client}
+	return &List{client: c.client, prefix: c.prefix}
 }
 
 // LPush 从左侧推入元素
 func (l *List) LPush(ctx context.Context, key string, values ...interface{}) (int64, error) {
-	return l.client.LPush(ctx, key, values...).Result()
+	return l.client.LPush(ctx, l.prefix.key(key), values...).Result()
 }
 
 // RPush 从右侧推入元素
 func (l *List) RPush(ctx context.Context, key string, values ...interface{}) (int64, error) {
-	return l.client.RPush(ctx, key, values...).Result()
+	return l.client.RPush(ctx, l.prefix.key(key), values...).Result()
 }
 
 // LPushX 从左侧推入元素（仅当列表存在时）
 func (l *List) LPushX(ctx context.Context, key string, values ...interface{}) (int64, error) {
-	return l.client.LPushX(ctx, key, values...).Result()
+	return l.client.LPushX(ctx, l.prefix.key(key), values...).Result()
 }
 
 // RPushX 从右侧推入元素（仅当列表存在时）
 func (l *List) RPushX(ctx context.Context, key string, values ...interface{}) (int64, error) {
-	return l.client.RPushX(ctx, key, values...).Result()
+	return l.client.RPushX(ctx, l.prefix.key(key), values...).Result()
 }
 
 // LPop 从左侧弹出元素
 func (l *List) LPop(ctx context.Context, key string) (string, error) {
-	return l.client.LPop(ctx, key).Result()
+	return l.client.LPop(ctx, l.prefix.key(key)).Result()
 }
 
 // RPop 从右侧弹出元素
 func (l *List) RPop(ctx context.Context, key string) (string, error) {
-	return l.client.RPop(ctx, key).Result()
+	return l.client.RPop(ctx, l.prefix.key(key)).Result()
 }
 
 // LPopCount 从左侧弹出多个元素
 func (l *List) LPopCount(ctx context.Context, key string, count int) ([]string, error) {
-	return l.client.LPopCount(ctx, key, count).Result()
+	return l.client.LPopCount(ctx, l.prefix.key(key), count).Result()
 }
 
 // RPopCount 从右侧弹出多个元素
 func (l *List) RPopCount(ctx context.Context, key string, count int) ([]string, error) {
-	return l.client.RPopCount(ctx, key, count).Result()
+	return l.client.RPopCount(ctx, l.prefix.key(key), count).Result()
 }
 
-// BLPop 阻塞式从左侧弹出元素
+// BLPop 阻塞式从左侧弹出元素，返回 [键名, 元素]
 func (l *List) BLPop(ctx context.Context, timeout time.Duration, keys ...string) ([]string, error) {
-	return l.client.BLPop(ctx, timeout, keys...).Result()
+	result, err := l.client.BLPop(ctx, timeout, l.prefix.keys(keys)...).Result()
+	if len(result) > 0 {
+		result[0] = l.prefix.strip(result[0])
+	}
+	return result, err
 }
 
-// BRPop 阻塞式从右侧弹出元素
+// BRPop 阻塞式从右侧弹出元素，返回 [键名, 元素]
 func (l *List) BRPop(ctx context.Context, timeout time.Duration, keys ...string) ([]string, error) {
-	return l.client.BRPop(ctx, timeout, keys...).Result()
+	result, err := l.client.BRPop(ctx, timeout, l.prefix.keys(keys)...).Result()
+	if len(result) > 0 {
+		result[0] = l.prefix.strip(result[0])
+	}
+	return result, err
 }
 
 // BRPopLPush 阻塞式从右侧弹出元素并推入到另一个列表的左侧
 func (l *List) BRPopLPush(ctx context.Context, source, destination string, timeout time.Duration) (string, error) {
-	return l.client.BRPopLPush(ctx, source, destination, timeout).Result()
+	return l.client.BRPopLPush(ctx, l.prefix.key(source), l.prefix.key(destination), timeout).Result()
 }
 
 // LIndex 获取指定索引的元素
 func (l *List) LIndex(ctx context.Context, key string, index int64) (string, error) {
-	return l.client.LIndex(ctx, key, index).Result()
+	return l.client.LIndex(ctx, l.prefix.key(key), index).Result()
 }
 
 // LInsert 在指定位置插入元素
 func (l *List) LInsert(ctx context.Context, key, op string, pivot, value interface{}) (int64, error) {
-	return l.client.LInsert(ctx, key, op, pivot, value).Result()
+	return l.client.LInsert(ctx, l.prefix.key(key), op, pivot, value).Result()
 }
 
 // LInsertBefore 在指定元素前插入
@@ -94,37 +103,37 @@ func (l *List) LInsertAfter(ctx context.Context, key string, pivot, value interf
 
 // LLen 获取列表长度
 func (l *List) LLen(ctx context.Context, key string) (int64, error) {
-	return l.client.LLen(ctx, key).Result()
+	return l.client.LLen(ctx, l.prefix.key(key)).Result()
 }
 
 // LRange 获取指定范围的元素
 func (l *List) LRange(ctx context.Context, key string, start, stop int64) ([]string, error) {
-	return l.client.LRange(ctx, key, start, stop).Result()
+	return l.client.LRange(ctx, l.prefix.key(key), start, stop).Result()
 }
 
 // LRem 移除指定元素
 func (l *List) LRem(ctx context.Context, key string, count int64, value interface{}) (int64, error) {
-	return l.client.LRem(ctx, key, count, value).Result()
+	return l.client.LRem(ctx, l.prefix.key(key), count, value).Result()
 }
 
 // LSet 设置指定索引的元素
 func (l *List) LSet(ctx context.Context, key string, index int64, value interface{}) error {
-	return l.client.LSet(ctx, key, index, value).Err()
+	return l.client.LSet(ctx, l.prefix.key(key), index, value).Err()
 }
 
 // LTrim 修剪列表
 func (l *List) LTrim(ctx context.Context, key string, start, stop int64) error {
-	return l.client.LTrim(ctx, key, start, stop).Err()
+	return l.client.LTrim(ctx, l.prefix.key(key), start, stop).Err()
 }
 
 // RPopLPush 从右侧弹出元素并推入到另一个列表的左侧
 func (l *List) RPopLPush(ctx context.Context, source, destination string) (string, error) {
-	return l.client.RPopLPush(ctx, source, destination).Result()
+	return l.client.RPopLPush(ctx, l.prefix.key(source), l.prefix.key(destination)).Result()
 }
 
 // LMove 移动元素
 func (l *List) LMove(ctx context.Context, source, destination, srcpos, destpos string) (string, error) {
-	return l.client.LMove(ctx, source, destination, srcpos, destpos).Result()
+	return l.client.LMove(ctx, l.prefix.key(source), l.prefix.key(destination), srcpos, destpos).Result()
 }
 
 // LMoveRightLeft 从右侧弹出并推入到左侧
@@ -149,10 +158,10 @@ func (l *List) LMoveLeftLeft(ctx context.Context, source, destination string) (s
 
 // LPos 查找元素位置
 func (l *List) LPos(ctx context.Context, key string, value string, args redis.LPosArgs) (int64, error) {
-	return l.client.LPos(ctx, key, value, args).Result()
+	return l.client.LPos(ctx, l.prefix.key(key), value, args).Result()
 }
 
 // LPosCount 查找多个元素位置
 func (l *List) LPosCount(ctx context.Context, key string, value string, count int64, args redis.LPosArgs) ([]int64, error) {
-	return l.client.LPosCount(ctx, key, value, count, args).Result()
+	return l.client.LPosCount(ctx, l.prefix.key(key), value, count, args).Result()
 }
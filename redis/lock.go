@@ -0,0 +1,301 @@
+package redis
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	mathrand "math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	// ErrLockNotAcquired 锁已被其他持有者占用
+	ErrLockNotAcquired = errors.New("redis: lock not acquired")
+	// ErrLockNotHeld 锁已过期或被其他持有者获取
+	ErrLockNotHeld = errors.New("redis: lock not held")
+	// ErrLockLost 租约续期失败，锁已丢失
+	ErrLockLost = errors.New("redis: lock lost")
+	// ErrLockReleased 锁已被主动释放
+	ErrLockReleased = errors.New("redis: lock released")
+)
+
+// lockAcquireScript 获取锁并递增防护令牌
+var lockAcquireScript = redis.NewScript(`
+if redis.call('SET', KEYS[1], ARGV[1], 'NX', 'PX', ARGV[2]) then
+    return redis.call('INCR', KEYS[2])
+end
+return 0`)
+
+// lockRenewScript 持有者匹配时续期
+var lockRenewScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+    return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0`)
+
+// lockReleaseScript 持有者匹配时删除
+var lockReleaseScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+    return redis.call('DEL', KEYS[1])
+end
+return 0`)
+
+// LockConfig 分布式锁配置
+type LockConfig struct {
+	RetryDelay      time.Duration `json:"retry_delay" yaml:"retry_delay"`           // 阻塞获取时的初始重试间隔
+	MaxRetryDelay   time.Duration `json:"max_retry_delay" yaml:"max_retry_delay"`   // 最大重试间隔
+	RenewInterval   time.Duration `json:"renew_interval" yaml:"renew_interval"`     // 看门狗续期间隔，0表示TTL的1/3
+	DisableWatchdog bool          `json:"disable_watchdog" yaml:"disable_watchdog"` // 关闭自动续期
+}
+
+// DefaultLockConfig 默认分布式锁配置
+func DefaultLockConfig() *LockConfig {
+	return &LockConfig{
+		RetryDelay:    50 * time.Millisecond,
+		MaxRetryDelay: time.Second,
+	}
+}
+
+// Lock 分布式锁
+type Lock struct {
+	client redis.UniversalClient
+	prefix keyPrefix
+	config *LockConfig
+}
+
+// NewLock 创建分布式锁，config为nil时使用默认配置
+func (c *Client) NewLock(config *LockConfig) *Lock {
+	if config == nil {
+		config = DefaultLockConfig()
+	}
+	cfg := *config
+	defaults := DefaultLockConfig()
+	if cfg.RetryDelay <= 0 {
+		cfg.RetryDelay = defaults.RetryDelay
+	}
+	if cfg.MaxRetryDelay < cfg.RetryDelay {
+		cfg.MaxRetryDelay = cfg.RetryDelay
+	}
+	return &Lock{client: c.client, prefix: c.prefix, config: &cfg}
+}
+
+// Lease 锁租约
+type Lease struct {
+	lock  *Lock
+	key   string
+	token string
+	fence int64
+	ttl   time.Duration
+
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	done   chan struct{}
+	once   sync.Once
+	expiry *time.Timer // 关闭看门狗时在TTL耗尽后取消租约上下文
+}
+
+// fenceKey 防护令牌计数器的键，与锁键位于同一个集群槽位
+func fenceKey(key string) string {
+	if strings.Contains(key, "{") && strings.Contains(key, "}") {
+		return key + ":fence"
+	}
+	return "{" + key + "}:fence"
+}
+
+// newLockToken 生成随机的持有者标识
+func newLockToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// TryAcquire 尝试获取锁，锁被占用时立即返回 ErrLockNotAcquired
+func (l *Lock) TryAcquire(ctx context.Context, key string, ttl time.Duration) (*Lease, error) {
+	if ttl < time.Millisecond {
+		return nil, fmt.Errorf("lock ttl must be at least 1ms, got %v", ttl)
+	}
+	token, err := newLockToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate lock token: %w", err)
+	}
+
+	key = l.prefix.key(key)
+	start := time.Now()
+	fence, err := lockAcquireScript.Run(ctx, l.client, []string{key, fenceKey(key)}, token, ttl.Milliseconds()).Int64()
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire lock: %w", err)
+	}
+	if fence == 0 {
+		return nil, ErrLockNotAcquired
+	}
+
+	leaseCtx, cancel := context.WithCancelCause(context.Background())
+	lease := &Lease{
+		lock:   l,
+		key:    key,
+		token:  token,
+		fence:  fence,
+		ttl:    ttl,
+		ctx:    leaseCtx,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	if l.config.DisableWatchdog {
+		// 从发出请求的时间算起，保证不晚于服务端的过期时间
+		lease.expiry = time.AfterFunc(time.Until(start.Add(ttl)), func() { lease.stop(ErrLockLost) })
+		close(lease.done)
+	} else {
+		go lease.watchdog()
+	}
+	return lease, nil
+}
+
+// Acquire 获取锁，锁被占用时按指数退避重试，直到成功或ctx结束
+func (l *Lock) Acquire(ctx context.Context, key string, ttl time.Duration) (*Lease, error) {
+	delay := l.config.RetryDelay
+	for {
+		lease, err := l.TryAcquire(ctx, key, ttl)
+		if err != ErrLockNotAcquired {
+			return lease, err
+		}
+
+		// 加入随机抖动，避免多个等待者同时重试
+		wait := delay/2 + time.Duration(mathrand.Int63n(int64(delay/2)+1))
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, fmt.Errorf("%w: %w", ErrLockNotAcquired, ctx.Err())
+		case <-timer.C:
+		}
+
+		delay *= 2
+		if delay > l.config.MaxRetryDelay {
+			delay = l.config.MaxRetryDelay
+		}
+	}
+}
+
+// Key 返回锁的键
+func (l *Lease) Key() string {
+	return l.lock.prefix.strip(l.key)
+}
+
+// Token 返回持有者标识
+func (l *Lease) Token() string {
+	return l.token
+}
+
+// Fence 返回防护令牌，每次成功获取锁时单调递增，
+// 下游存储可以拒绝携带较小令牌的写入，防止锁过期后的旧持有者覆盖数据
+func (l *Lease) Fence() int64 {
+	return l.fence
+}
+
+// Locked 返回持有锁期间有效的上下文，租约丢失、释放或关闭看门狗后TTL耗尽时取消，
+// 通过 context.Cause 可以得到 ErrLockLost 或 ErrLockReleased
+func (l *Lease) Locked() context.Context {
+	return l.ctx
+}
+
+// Refresh 手动续期并将TTL更新为ttl，关闭看门狗时 Locked 的有效期同时延长
+func (l *Lease) Refresh(ctx context.Context, ttl time.Duration) error {
+	start := time.Now()
+	ok, err := lockRenewScript.Run(ctx, l.lock.client, []string{l.key}, l.token, ttl.Milliseconds()).Int64()
+	if err != nil {
+		return fmt.Errorf("failed to refresh lock: %w", err)
+	}
+	if ok == 0 {
+		l.stop(ErrLockLost)
+		return ErrLockNotHeld
+	}
+	if l.expiry != nil {
+		l.expiry.Reset(time.Until(start.Add(ttl)))
+	}
+	return nil
+}
+
+// TTL 返回锁的剩余时间，锁不再由当前租约持有时返回 ErrLockNotHeld
+func (l *Lease) TTL(ctx context.Context) (time.Duration, error) {
+	value, err := l.lock.client.Get(ctx, l.key).Result()
+	if err == redis.Nil || (err == nil && value != l.token) {
+		return 0, ErrLockNotHeld
+	}
+	if err != nil {
+		return 0, err
+	}
+	return l.lock.client.PTTL(ctx, l.key).Result()
+}
+
+// Release 释放锁并停止续期，锁已过期或被他人持有时返回 ErrLockNotHeld
+func (l *Lease) Release(ctx context.Context) error {
+	l.stop(ErrLockReleased)
+	<-l.done
+	if l.expiry != nil {
+		l.expiry.Stop()
+	}
+
+	deleted, err := lockReleaseScript.Run(ctx, l.lock.client, []string{l.key}, l.token).Int64()
+	if err != nil {
+		return fmt.Errorf("failed to release lock: %w", err)
+	}
+	if deleted == 0 {
+		return ErrLockNotHeld
+	}
+	return nil
+}
+
+// stop 取消租约上下文，只有第一次调用的原因生效
+func (l *Lease) stop(cause error) {
+	l.once.Do(func() {
+		l.cancel(cause)
+	})
+}
+
+// watchdog 定期续期，续期被拒绝或超过TTL仍未成功时判定锁丢失
+func (l *Lease) watchdog() {
+	defer close(l.done)
+
+	interval := l.lock.config.RenewInterval
+	if interval <= 0 {
+		interval = l.ttl / 3
+	}
+	if interval <= 0 {
+		interval = time.Millisecond
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	lastRenewed := time.Now()
+	for {
+		select {
+		case <-l.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		ctx, cancel := context.WithTimeout(l.ctx, interval)
+		ok, err := lockRenewScript.Run(ctx, l.lock.client, []string{l.key}, l.token, l.ttl.Milliseconds()).Int64()
+		cancel()
+
+		switch {
+		case err == nil && ok == 1:
+			lastRenewed = time.Now()
+		case err == nil:
+			l.stop(ErrLockLost)
+			return
+		case time.Since(lastRenewed) >= l.ttl:
+			// 网络错误持续到TTL耗尽，锁已经过期
+			l.stop(fmt.Errorf("%w: %w", ErrLockLost, err))
+			return
+		}
+	}
+}
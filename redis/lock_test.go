@@ -0,0 +1,225 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestClient 连接本地Redis，不可用时跳过测试
+func newTestClient(t *testing.T) *Client {
+	t.Helper()
+	config := DefaultConfig()
+	config.Addr = "localhost:6379"
+
+	client, err := New(config)
+	if err != nil {
+		t.Skipf("Skipping test: Redis not available: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestLock(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+	lock := client.NewLock(&LockConfig{RetryDelay: 10 * time.Millisecond, MaxRetryDelay: 50 * time.Millisecond})
+	key := "test:lock:basic"
+	client.Del(ctx, key, fenceKey(key))
+
+	// 获取与互斥
+	lease, err := lock.TryAcquire(ctx, key, time.Second)
+	if err != nil {
+		t.Fatalf("TryAcquire failed: %v", err)
+	}
+	if _, err := lock.TryAcquire(ctx, key, time.Second); err != ErrLockNotAcquired {
+		t.Errorf("Expected ErrLockNotAcquired, got %v", err)
+	}
+
+	ttl, err := lease.TTL(ctx)
+	if err != nil || ttl <= 0 || ttl > time.Second {
+		t.Errorf("Unexpected TTL %v, err %v", ttl, err)
+	}
+
+	// 释放后可以再次获取，防护令牌递增
+	if err := lease.Release(ctx); err != nil {
+		t.Errorf("Release failed: %v", err)
+	}
+	if !errors.Is(context.Cause(lease.Locked()), ErrLockReleased) {
+		t.Errorf("Expected ErrLockReleased cause, got %v", context.Cause(lease.Locked()))
+	}
+	if err := lease.Release(ctx); err != ErrLockNotHeld {
+		t.Errorf("Expected ErrLockNotHeld on double release, got %v", err)
+	}
+
+	second, err := lock.TryAcquire(ctx, key, time.Second)
+	if err != nil {
+		t.Fatalf("TryAcquire after release failed: %v", err)
+	}
+	defer second.Release(ctx)
+	if second.Fence() <= lease.Fence() {
+		t.Errorf("Expected fence to increase, got %d after %d", second.Fence(), lease.Fence())
+	}
+}
+
+func TestLockAcquireBlocks(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+	lock := client.NewLock(&LockConfig{RetryDelay: 5 * time.Millisecond, MaxRetryDelay: 20 * time.Millisecond})
+	key := "test:lock:blocking"
+	client.Del(ctx, key, fenceKey(key))
+
+	var holders int32
+	var maxHolders int32
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			lease, err := lock.Acquire(ctx, key, time.Second)
+			if err != nil {
+				t.Errorf("Acquire failed: %v", err)
+				return
+			}
+			n := atomic.AddInt32(&holders, 1)
+			for {
+				m := atomic.LoadInt32(&maxHolders)
+				if n <= m || atomic.CompareAndSwapInt32(&maxHolders, m, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			atomic.AddInt32(&holders, -1)
+			if err := lease.Release(ctx); err != nil {
+				t.Errorf("Release failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if maxHolders != 1 {
+		t.Errorf("Expected at most one holder, got %d", maxHolders)
+	}
+
+	// 超时返回
+	lease, err := lock.TryAcquire(ctx, key, time.Second)
+	if err != nil {
+		t.Fatalf("TryAcquire failed: %v", err)
+	}
+	defer lease.Release(ctx)
+	timeoutCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	if _, err := lock.Acquire(timeoutCtx, key, time.Second); !errors.Is(err, ErrLockNotAcquired) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected not acquired deadline error, got %v", err)
+	}
+}
+
+func TestLockWatchdog(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+	key := "test:lock:watchdog"
+	client.Del(ctx, key, fenceKey(key))
+
+	// 看门狗在TTL内续期，锁持续有效
+	lease, err := client.NewLock(nil).TryAcquire(ctx, key, 150*time.Millisecond)
+	if err != nil {
+		t.Fatalf("TryAcquire failed: %v", err)
+	}
+	time.Sleep(400 * time.Millisecond)
+	if lease.Locked().Err() != nil {
+		t.Errorf("Lease context cancelled while held: %v", context.Cause(lease.Locked()))
+	}
+	if _, err := lease.TTL(ctx); err != nil {
+		t.Errorf("Expected lock to be held, got %v", err)
+	}
+
+	// 锁被外部删除后，租约上下文被取消
+	client.Del(ctx, key)
+	select {
+	case <-lease.Locked().Done():
+		if !errors.Is(context.Cause(lease.Locked()), ErrLockLost) {
+			t.Errorf("Expected ErrLockLost cause, got %v", context.Cause(lease.Locked()))
+		}
+	case <-time.After(time.Second):
+		t.Error("Lease context not cancelled after lock loss")
+	}
+	if err := lease.Release(ctx); err != ErrLockNotHeld {
+		t.Errorf("Expected ErrLockNotHeld, got %v", err)
+	}
+}
+
+func TestLockWithoutWatchdog(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+	key := "test:lock:nowatchdog"
+	client.Del(ctx, key, fenceKey(key))
+	lock := client.NewLock(&LockConfig{DisableWatchdog: true})
+
+	lease, err := lock.TryAcquire(ctx, key, 100*time.Millisecond)
+	if err != nil {
+		t.Fatalf("TryAcquire failed: %v", err)
+	}
+	time.Sleep(250 * time.Millisecond)
+
+	// 未续期的锁过期后可以被他人获取
+	other, err := lock.TryAcquire(ctx, key, time.Second)
+	if err != nil {
+		t.Fatalf("Expected expired lock to be acquirable, got %v", err)
+	}
+	defer other.Release(ctx)
+
+	if err := lease.Refresh(ctx, time.Second); err != ErrLockNotHeld {
+		t.Errorf("Expected ErrLockNotHeld on refresh, got %v", err)
+	}
+	if lease.Locked().Err() == nil {
+		t.Error("Expected lease context to be cancelled after failed refresh")
+	}
+	if err := lease.Release(ctx); err != ErrLockNotHeld {
+		t.Errorf("Expected ErrLockNotHeld, got %v", err)
+	}
+}
+
+func TestLockWithoutWatchdogExpiresLocked(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+	key := "test:lock:nowatchdog:ctx"
+	client.Del(ctx, key, fenceKey(key))
+	lock := client.NewLock(&LockConfig{DisableWatchdog: true})
+
+	lease, err := lock.TryAcquire(ctx, key, 100*time.Millisecond)
+	if err != nil {
+		t.Fatalf("TryAcquire failed: %v", err)
+	}
+	defer lease.Release(ctx)
+
+	// 手动续期同时延长 Locked 的有效期
+	time.Sleep(60 * time.Millisecond)
+	if err := lease.Refresh(ctx, 200*time.Millisecond); err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if err := lease.Locked().Err(); err != nil {
+		t.Fatalf("Expected refreshed lease to be live, got %v", err)
+	}
+
+	// 不再续期时，TTL耗尽后 Locked 被取消
+	select {
+	case <-lease.Locked().Done():
+	case <-time.After(time.Second):
+		t.Fatal("Expected lease context to be cancelled after ttl")
+	}
+	if cause := context.Cause(lease.Locked()); cause != ErrLockLost {
+		t.Errorf("Expected ErrLockLost, got %v", cause)
+	}
+}
+
+func TestFenceKey(t *testing.T) {
+	if got := fenceKey("orders"); got != "{orders}:fence" {
+		t.Errorf("Unexpected fence key %s", got)
+	}
+	if got := fenceKey("{user:1}:lock"); got != "{user:1}:lock:fence" {
+		t.Errorf("Unexpected fence key %s", got)
+	}
+}
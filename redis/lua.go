@@ -5,6 +5,7 @@ import (
 	"crypto/sha1"
 	"encoding/hex"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -13,9 +14,11 @@ import (
 
 // Script Lua脚本管理器
 type Script struct {
-	client *redis.Client
-	cache  map[string]string // 脚本SHA缓存
-	mutex  sync.RWMutex
+	client  redis.UniversalClient
+	prefix  keyPrefix         // 执行时为 ScriptOptions.Keys 加上的键前缀
+	cache   map[string]string // 脚本SHA缓存
+	sources map[string]string // 已注册的脚本源码，用于服务端脚本缓存丢失后重新加载
+	mutex   sync.RWMutex
 }
 
 // ScriptInfo Lua脚本信息
@@ -66,8 +69,10 @@ func DefaultScriptOptions() *ScriptOptions {
 // NewScript 创建Lua脚本管理器
 func (c *Client) NewScript() *Script {
 	return &Script{
-		client: c.client,
-		cache:  make(map[string]string),
+		client:  c.client,
+		prefix:  c.prefix,
+		cache:   make(map[string]string),
+		sources: make(map[string]string),
 	}
 }
 
@@ -94,6 +99,7 @@ func (s *Script) Register(ctx context.Context, info *ScriptInfo) error {
 	// 缓存脚本
 	s.mutex.Lock()
 	s.cache[info.Name] = sha
+	s.sources[info.Name] = info.Source
 	s.mutex.Unlock()
 
 	// 预加载脚本到Redis
@@ -118,6 +124,7 @@ func (s *Script) Execute(ctx context.Context, name string, opts *ScriptOptions)
 	var execErr error
 
 	// 重试逻辑
+	reloaded := false
 	for i := 0; i <= opts.RetryCount; i++ {
 		if i > 0 {
 			time.Sleep(opts.RetryDelay)
@@ -134,7 +141,7 @@ func (s *Script) Execute(ctx context.Context, name string, opts *ScriptOptions)
 		// 执行脚本
 		if opts.UseCache && sha != "" {
 			// 使用缓存的SHA执行
-			result, execErr = s.client.EvalSha(execCtx, sha, opts.Keys, opts.Args...).Result()
+			result, execErr = s.client.EvalSha(execCtx, sha, s.prefix.keys(opts.Keys), opts.Args...).Result()
 		} else {
 			// 直接执行脚本源码
 			script, err := s.getScriptSource(ctx, name)
@@ -142,17 +149,21 @@ func (s *Script) Execute(ctx context.Context, name string, opts *ScriptOptions)
 				execErr = err
 				continue
 			}
-			result, execErr = s.client.Eval(execCtx, script, opts.Keys, opts.Args...).Result()
+			result, execErr = s.client.Eval(execCtx, script, s.prefix.keys(opts.Keys), opts.Args...).Result()
 		}
 
-		// 如果脚本不存在，尝试重新加载
+		// 如果脚本不存在（服务端重启、SCRIPT FLUSH或故障转移到新节点），重新加载后立即重试一次，不计入重试次数
 		if execErr != nil && isScriptNotFoundError(execErr) {
-			err := s.reloadScript(ctx, name)
-			if err != nil {
+			if reloaded {
+				continue
+			}
+			if err := s.reloadScript(ctx, name); err != nil {
 				continue
 			}
+			reloaded = true
 			// 重新获取SHA
 			sha, _ = s.getScriptSHA(ctx, name, false)
+			i--
 			continue
 		}
 
@@ -197,7 +208,7 @@ func (s *Script) ExecuteString(ctx context.Context, script string, opts *ScriptO
 		}
 
 		// 执行脚本
-		result, execErr = s.client.Eval(execCtx, script, opts.Keys, opts.Args...).Result()
+		result, execErr = s.client.Eval(execCtx, script, s.prefix.keys(opts.Keys), opts.Args...).Result()
 
 		// 如果成功或非重试错误，跳出循环
 		if execErr == nil || !isRetryableError(execErr) {
@@ -303,12 +314,27 @@ func (s *Script) getScriptSHA(ctx context.Context, name string, forceReload bool
 	return sha, nil
 }
 
+// swap 替换脚本的SHA和源码并返回之前的SHA，调用前新脚本必须已加载到Redis
+func (s *Script) swap(name, source, sha string) string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	old := s.cache[name]
+	s.cache[name] = sha
+	s.sources[name] = source
+	return old
+}
+
 // getScriptSource 获取脚本源码
 func (s *Script) getScriptSource(ctx context.Context, name string) (string, error) {
-	// 这里可以从文件系统、数据库或其他存储中获取脚本
-	// 为了简化，我们假设脚本已经通过Register方法注册
-	// 在实际应用中，可以实现从文件系统或数据库加载
-	return "", fmt.Errorf("script source not found: %s", name)
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	source, exists := s.sources[name]
+	if !exists {
+		return "", fmt.Errorf("script source not found: %s", name)
+	}
+	return source, nil
 }
 
 // loadScript 加载脚本到Redis
@@ -324,16 +350,28 @@ func (s *Script) loadScript(ctx context.Context, info *ScriptInfo) error {
 	return nil
 }
 
-// reloadScript 重新加载脚本
+// reloadScript 重新加载脚本，集群模式下会加载到所有主节点
 func (s *Script) reloadScript(ctx context.Context, name string) error {
-	// 这里可以实现重新加载脚本的逻辑
-	// 例如从文件系统重新读取脚本并加载到Redis
-	return fmt.Errorf("script reload not implemented: %s", name)
+	source, err := s.getScriptSource(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	sha, err := s.client.ScriptLoad(ctx, source).Result()
+	if err != nil {
+		return fmt.Errorf("failed to reload script %s: %w", name, err)
+	}
+
+	s.mutex.Lock()
+	s.cache[name] = sha
+	s.mutex.Unlock()
+
+	return nil
 }
 
-// isScriptNotFoundError 检查是否为脚本不存在错误
+// isScriptNotFoundError 检查是否为脚本不存在错误，不同Redis版本的错误描述不同，只比较错误前缀
 func isScriptNotFoundError(err error) bool {
-	return err != nil && err.Error() == "NOSCRIPT No matching script. Use EVAL."
+	return err != nil && strings.HasPrefix(err.Error(), "NOSCRIPT")
 }
 
 // isRetryableError 检查是否为可重试错误
@@ -2,6 +2,7 @@ package redis
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 )
@@ -162,8 +163,29 @@ func TestLuaScriptTemplates(t *testing.T) {
 		t.Errorf("Expected 1, got %v", result.Value)
 	}
 
+	// 测试限流脚本，窗口参数以秒为单位，获取数量默认为1
+	client.Del(ctx, "test:rate_limit")
+	rateLimitOpts := &ScriptOptions{
+		Keys:    []string{"test:rate_limit"},
+		Args:    []interface{}{2, 60},
+		Timeout: 5 * time.Second,
+	}
+	for i, expected := range []int64{1, 1, 0} {
+		result, err = script.Execute(ctx, "rate_limit", rateLimitOpts)
+		if err != nil {
+			t.Fatalf("Execute rate limit script failed: %v", err)
+		}
+		values := result.Value.([]interface{})
+		if values[0] != expected {
+			t.Errorf("Request %d: expected allowed=%d, got %v", i+1, expected, values)
+		}
+	}
+	if ttl, _ := client.TTL(ctx, "test:rate_limit"); ttl < 59*time.Second || ttl > 60*time.Second {
+		t.Errorf("Expected window of 60s, got %v", ttl)
+	}
+
 	// 清理
-	client.Del(ctx, "test:lock")
+	client.Del(ctx, "test:lock", "test:rate_limit")
 }
 
 func TestLuaScriptManager(t *testing.T) {
@@ -381,3 +403,57 @@ func TestLuaScriptLoadAndExists(t *testing.T) {
 		t.Error("Expected nonexistent script to not exist")
 	}
 }
+
+func TestLuaScriptReloadAfterFlush(t *testing.T) {
+	client := newTestClient(t)
+	defer client.Close()
+
+	ctx := context.Background()
+	script := client.NewScript()
+
+	err := script.Register(ctx, &ScriptInfo{
+		Name:   "test_reload_script",
+		Source: `return "reloaded " .. ARGV[1]`,
+	})
+	if err != nil {
+		t.Fatalf("Register script failed: %v", err)
+	}
+
+	// 模拟服务端重启或故障转移后脚本缓存丢失
+	if err := client.GetClient().ScriptFlush(ctx).Err(); err != nil {
+		t.Fatalf("ScriptFlush failed: %v", err)
+	}
+
+	opts := DefaultScriptOptions()
+	opts.Args = []interface{}{"ok"}
+	opts.RetryCount = 0
+	result, err := script.Execute(ctx, "test_reload_script", opts)
+	if err != nil {
+		t.Fatalf("Execute script failed: %v", err)
+	}
+	if result.Error != nil {
+		t.Fatalf("Expected script to be reloaded, got error: %v", result.Error)
+	}
+	if result.Value != "reloaded ok" {
+		t.Errorf("Expected 'reloaded ok', got %v", result.Value)
+	}
+
+	// 清空本地缓存后仍能根据注册的源码执行
+	script.ClearCache()
+	result, err = script.Execute(ctx, "test_reload_script", opts)
+	if err != nil || result.Error != nil {
+		t.Fatalf("Execute after ClearCache failed: %v %v", err, result.Error)
+	}
+}
+
+func TestIsScriptNotFoundError(t *testing.T) {
+	if !isScriptNotFoundError(errors.New("NOSCRIPT No matching script. Use EVAL.")) {
+		t.Error("Expected Redis 6 NOSCRIPT error to match")
+	}
+	if !isScriptNotFoundError(errors.New("NOSCRIPT No matching script. Please use EVAL.")) {
+		t.Error("Expected Redis 7 NOSCRIPT error to match")
+	}
+	if isScriptNotFoundError(errors.New("ERR unknown command")) {
+		t.Error("Expected other errors not to match")
+	}
+}
@@ -0,0 +1,128 @@
+package redis
+
+import (
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// keyPrefix 键前缀，数据结构封装在发送命令前为键加上前缀，在返回键名时去掉前缀
+type keyPrefix string
+
+// WithNamespace 返回在当前前缀后追加 "ns:" 的客户端视图，视图的所有数据结构封装自动为键加前缀。
+// 视图与原客户端共享连接，关闭任意一个都会关闭底层连接
+func (c *Client) WithNamespace(ns string) *Client {
+	return &Client{
+		client: c.client,
+		config: c.config,
+		prefix: c.prefix + keyPrefix(ns+":"),
+		guard:  c.guard,
+	}
+}
+
+// Namespace 返回当前客户端的键前缀
+func (c *Client) Namespace() string {
+	return string(c.prefix)
+}
+
+// Key 返回加上前缀后的完整键名，用于通过 GetUniversalClient 或事务回调中的 *redis.Tx 直接访问
+func (c *Client) Key(key string) string {
+	return c.prefix.key(key)
+}
+
+// key 为键加前缀
+func (p keyPrefix) key(key string) string {
+	return string(p) + key
+}
+
+// keys 为多个键加前缀
+func (p keyPrefix) keys(keys []string) []string {
+	if p == "" {
+		return keys
+	}
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = string(p) + key
+	}
+	return prefixed
+}
+
+// pairs 为 MSet 等命令的键值对中的键加前缀，支持 "k1", "v1" 形式和 map 形式
+func (p keyPrefix) pairs(pairs []interface{}) []interface{} {
+	if p == "" {
+		return pairs
+	}
+	if len(pairs) == 1 {
+		switch m := pairs[0].(type) {
+		case map[string]interface{}:
+			prefixed := make(map[string]interface{}, len(m))
+			for k, v := range m {
+				prefixed[p.key(k)] = v
+			}
+			return []interface{}{prefixed}
+		case map[string]string:
+			prefixed := make(map[string]string, len(m))
+			for k, v := range m {
+				prefixed[p.key(k)] = v
+			}
+			return []interface{}{prefixed}
+		case []string:
+			prefixed := make([]string, len(m))
+			copy(prefixed, m)
+			for i := 0; i < len(prefixed); i += 2 {
+				prefixed[i] = p.key(prefixed[i])
+			}
+			return []interface{}{prefixed}
+		}
+	}
+	prefixed := make([]interface{}, len(pairs))
+	copy(prefixed, pairs)
+	for i := 0; i < len(prefixed); i += 2 {
+		if key, ok := prefixed[i].(string); ok {
+			prefixed[i] = p.key(key)
+		}
+	}
+	return prefixed
+}
+
+// pattern 为 KEYS/SCAN 的匹配模式加前缀，前缀中的通配符会被转义
+func (p keyPrefix) pattern(pattern string) string {
+	if p == "" {
+		return pattern
+	}
+	var b strings.Builder
+	for _, r := range string(p) {
+		switch r {
+		case '*', '?', '[', ']', '\\':
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String() + pattern
+}
+
+// strip 去掉键的前缀
+func (p keyPrefix) strip(key string) string {
+	return strings.TrimPrefix(key, string(p))
+}
+
+// stripAll 去掉多个键的前缀
+func (p keyPrefix) stripAll(keys []string) []string {
+	if p == "" {
+		return keys
+	}
+	for i, key := range keys {
+		keys[i] = p.strip(key)
+	}
+	return keys
+}
+
+// zstore 返回键加上前缀的 ZStore 副本
+func (p keyPrefix) zstore(store *redis.ZStore) *redis.ZStore {
+	if p == "" || store == nil {
+		return store
+	}
+	prefixed := *store
+	prefixed.Keys = p.keys(store.Keys)
+	return &prefixed
+}
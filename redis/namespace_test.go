@@ -0,0 +1,178 @@
+package redis
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func TestKeyPrefix(t *testing.T) {
+	p := keyPrefix("svc:*:")
+	if got := p.key("a"); got != "svc:*:a" {
+		t.Errorf("Expected svc:*:a, got %s", got)
+	}
+	if got := p.pattern("user:*"); got != `svc:\*:user:*` {
+		t.Errorf("Expected escaped pattern, got %s", got)
+	}
+	if got := p.stripAll([]string{"svc:*:a", "svc:*:b"}); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("Unexpected stripped keys %v", got)
+	}
+
+	pairs := p.pairs([]interface{}{"a", 1, "b", 2})
+	if !reflect.DeepEqual(pairs, []interface{}{"svc:*:a", 1, "svc:*:b", 2}) {
+		t.Errorf("Unexpected pairs %v", pairs)
+	}
+	pairs = p.pairs([]interface{}{map[string]interface{}{"a": 1}})
+	if !reflect.DeepEqual(pairs, []interface{}{map[string]interface{}{"svc:*:a": 1}}) {
+		t.Errorf("Unexpected map pairs %v", pairs)
+	}
+
+	store := &redis.ZStore{Keys: []string{"a", "b"}, Weights: []float64{1, 2}}
+	prefixed := p.zstore(store)
+	if !reflect.DeepEqual(prefixed.Keys, []string{"svc:*:a", "svc:*:b"}) || store.Keys[0] != "a" {
+		t.Errorf("Unexpected zstore %+v, original %+v", prefixed, store)
+	}
+}
+
+func TestNamespace(t *testing.T) {
+	client := newTestClient(t)
+	defer client.Close()
+
+	ctx := context.Background()
+	tenantA := client.WithNamespace("test:ns:a")
+	tenantB := client.WithNamespace("test:ns:b")
+	if tenantA.Namespace() != "test:ns:a:" || tenantA.Key("k") != "test:ns:a:k" {
+		t.Errorf("Unexpected namespace %q", tenantA.Namespace())
+	}
+	defer func() {
+		keys, _ := client.Keys(ctx, "test:ns:*")
+		if len(keys) > 0 {
+			client.Del(ctx, keys...)
+		}
+	}()
+
+	// 不同命名空间的同名键互不影响
+	if err := tenantA.NewString().Set(ctx, "name", "a", 0); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if err := tenantB.NewString().Set(ctx, "name", "b", 0); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if value, _ := tenantA.NewString().Get(ctx, "name"); value != "a" {
+		t.Errorf("Expected a, got %s", value)
+	}
+	if value, _ := client.NewString().Get(ctx, "test:ns:b:name"); value != "b" {
+		t.Errorf("Expected raw key to hold b, got %s", value)
+	}
+
+	if err := tenantA.NewString().MSet(ctx, "x", 1, "y", 2); err != nil {
+		t.Fatalf("MSet failed: %v", err)
+	}
+	values, _ := tenantA.NewString().MGet(ctx, "x", "y")
+	if !reflect.DeepEqual(values, []interface{}{"1", "2"}) {
+		t.Errorf("Unexpected MGet result %v", values)
+	}
+
+	tenantA.NewHash().HSet(ctx, "hash", "f", "v")
+	tenantA.NewList().RPush(ctx, "list", "item")
+	tenantA.NewSet().SAdd(ctx, "set", "m")
+	tenantA.NewZSet().ZAdd(ctx, "zset", redis.Z{Score: 1, Member: "m"})
+
+	keys, err := tenantA.Keys(ctx, "*")
+	if err != nil {
+		t.Fatalf("Keys failed: %v", err)
+	}
+	sort.Strings(keys)
+	if !reflect.DeepEqual(keys, []string{"hash", "list", "name", "set", "x", "y", "zset"}) {
+		t.Errorf("Unexpected keys %v", keys)
+	}
+	if n, _ := tenantB.Exists(ctx, "hash", "list", "name"); n != 1 {
+		t.Errorf("Expected tenant b to see only its own key, got %d", n)
+	}
+
+	// 阻塞命令返回的键名不含前缀
+	popped, err := tenantA.NewList().BLPop(ctx, time.Second, "list")
+	if err != nil || !reflect.DeepEqual(popped, []string{"list", "item"}) {
+		t.Errorf("Unexpected BLPop result %v %v", popped, err)
+	}
+	zpopped, err := tenantA.NewZSet().BZPopMin(ctx, time.Second, "zset")
+	if err != nil || zpopped.Key != "zset" {
+		t.Errorf("Unexpected BZPopMin result %+v %v", zpopped, err)
+	}
+
+	// 管道
+	pipe := tenantA.NewPipeline()
+	pipe.Incr(ctx, "counter")
+	keysCmd := pipe.Keys(ctx, "count*")
+	if _, err := pipe.Exec(ctx); err != nil {
+		t.Fatalf("Pipeline Exec failed: %v", err)
+	}
+	if !reflect.DeepEqual(keysCmd.Val(), []string{"counter"}) {
+		t.Errorf("Expected pipeline keys without prefix, got %v", keysCmd.Val())
+	}
+
+	// 事务
+	tx := tenantA.NewTransaction()
+	tx.Multi(ctx)
+	tx.Set(ctx, "tx", "1", 0)
+	tx.Incr(ctx, "tx")
+	if _, err := tx.ExecPipeline(ctx); err != nil {
+		t.Fatalf("Transaction failed: %v", err)
+	}
+	if value, _ := client.NewString().Get(ctx, "test:ns:a:tx"); value != "2" {
+		t.Errorf("Expected 2, got %s", value)
+	}
+
+	// 脚本的 KEYS 参数
+	opts := DefaultScriptOptions()
+	opts.Keys = []string{"name"}
+	result, err := tenantB.NewScript().ExecuteString(ctx, "return {KEYS[1], redis.call('GET', KEYS[1])}", opts)
+	if err != nil || result.Error != nil {
+		t.Fatalf("ExecuteString failed: %v %v", err, result.Error)
+	}
+	if !reflect.DeepEqual(result.Value, []interface{}{"test:ns:b:name", "b"}) {
+		t.Errorf("Unexpected script result %v", result.Value)
+	}
+
+	// 分布式锁
+	lease, err := tenantA.NewLock(nil).TryAcquire(ctx, "lock", time.Second)
+	if err != nil {
+		t.Fatalf("TryAcquire failed: %v", err)
+	}
+	if lease.Key() != "lock" {
+		t.Errorf("Expected lease key without prefix, got %s", lease.Key())
+	}
+	if _, err := tenantB.NewLock(nil).TryAcquire(ctx, "lock", time.Second); err != nil {
+		t.Errorf("Expected lock in another namespace to be free, got %v", err)
+	}
+	lease.Release(ctx)
+}
+
+func TestNamespaceConfig(t *testing.T) {
+	config := DefaultConfig()
+	config.KeyPrefix = "test:cfg:"
+	client, err := New(config)
+	if err != nil {
+		t.Skipf("Skipping test: Redis not available: %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	defer client.Del(ctx, "key")
+
+	if err := client.NewString().Set(ctx, "key", "value", 0); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if value, _ := client.GetClient().Get(ctx, "test:cfg:key").Result(); value != "value" {
+		t.Errorf("Expected prefixed key, got %q", value)
+	}
+
+	// 命名空间在配置前缀之后追加
+	if got := client.WithNamespace("tenant").Key("key"); got != "test:cfg:tenant:key" {
+		t.Errorf("Expected test:cfg:tenant:key, got %s", got)
+	}
+}
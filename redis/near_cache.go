@@ -0,0 +1,320 @@
+package redis
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// NearCacheConfig 二级缓存配置
+type NearCacheConfig struct {
+	TypedCacheConfig `yaml:",inline"`
+
+	LocalCapacity int           `json:"local_capacity" yaml:"local_capacity"` // 本地缓存最大条目数
+	LocalTTL      time.Duration `json:"local_ttl" yaml:"local_ttl"`           // 本地缓存最长存活时间，也是丢失失效通知时的最大陈旧时间
+	Channel       string        `json:"channel" yaml:"channel"`               // 失效通知频道，默认由键前缀生成
+}
+
+// DefaultNearCacheConfig 默认二级缓存配置
+func DefaultNearCacheConfig() *NearCacheConfig {
+	return &NearCacheConfig{
+		TypedCacheConfig: *DefaultTypedCacheConfig(),
+		LocalCapacity:    10000,
+		LocalTTL:         time.Minute,
+	}
+}
+
+// NearCacheStats 二级缓存统计
+type NearCacheStats struct {
+	L1Hits        int64 `json:"l1_hits"`       // 本地缓存命中次数
+	L1Misses      int64 `json:"l1_misses"`     // 本地缓存未命中次数
+	L2Hits        int64 `json:"l2_hits"`       // Redis缓存命中次数
+	L2Misses      int64 `json:"l2_misses"`     // Redis缓存未命中次数
+	Loads         int64 `json:"loads"`         // 调用加载函数的次数
+	Invalidations int64 `json:"invalidations"` // 收到其他实例失效通知的键数
+	L1Size        int   `json:"l1_size"`       // 本地缓存当前条目数
+}
+
+// invalidation 失效通知消息
+type invalidation struct {
+	Origin string   `json:"origin"`
+	Keys   []string `json:"keys"`
+}
+
+// NearCache 本地LRU + Redis的二级缓存，写入和删除通过发布订阅通知所有实例淘汰本地副本
+type NearCache[T any] struct {
+	l2        *TypedCache[T]
+	l1        *localCache[T]
+	config    *NearCacheConfig
+	id        string
+	publisher *Publisher
+	sub       *Subscriber
+	cancel    context.CancelFunc
+	done      chan struct{}
+
+	l1Hits        int64
+	l1Misses      int64
+	l2Hits        int64
+	l2Misses      int64
+	loads         int64
+	invalidations int64
+}
+
+// NewNearCache 创建二级缓存并订阅失效通知，config为nil时使用默认配置
+func NewNearCache[T any](client *Client, config *NearCacheConfig) (*NearCache[T], error) {
+	if config == nil {
+		config = DefaultNearCacheConfig()
+	}
+	cfg := *config
+	defaults := DefaultNearCacheConfig()
+	if cfg.LocalCapacity <= 0 {
+		cfg.LocalCapacity = defaults.LocalCapacity
+	}
+	if cfg.LocalTTL <= 0 {
+		cfg.LocalTTL = defaults.LocalTTL
+	}
+	if cfg.Channel == "" {
+		cfg.Channel = "nearcache:invalidate:" + client.prefix.key(cfg.Prefix)
+	}
+
+	id, err := newLockToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate instance id: %w", err)
+	}
+
+	sub := client.NewSubscriber()
+	if err := sub.Subscribe(context.Background(), cfg.Channel); err != nil {
+		sub.Close()
+		return nil, fmt.Errorf("failed to subscribe invalidation channel: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &NearCache[T]{
+		l2:        NewTypedCache[T](client, &cfg.TypedCacheConfig),
+		l1:        newLocalCache[T](cfg.LocalCapacity),
+		config:    &cfg,
+		id:        id,
+		publisher: client.NewPublisher(),
+		sub:       sub,
+		cancel:    cancel,
+		done:      make(chan struct{}),
+	}
+	go func() {
+		defer close(c.done)
+		sub.Listen(ctx, c.handleInvalidation)
+	}()
+	return c, nil
+}
+
+// handleInvalidation 淘汰其他实例通知的键，忽略自己发出的通知
+func (c *NearCache[T]) handleInvalidation(msg *redis.Message) {
+	var inv invalidation
+	if err := json.Unmarshal([]byte(msg.Payload), &inv); err != nil || inv.Origin == c.id {
+		return
+	}
+	for _, key := range inv.Keys {
+		c.l1.delete(key)
+	}
+	atomic.AddInt64(&c.invalidations, int64(len(inv.Keys)))
+}
+
+// publish 广播失效通知
+func (c *NearCache[T]) publish(ctx context.Context, keys []string) error {
+	payload, err := json.Marshal(invalidation{Origin: c.id, Keys: keys})
+	if err != nil {
+		return err
+	}
+	if _, err := c.publisher.Publish(ctx, c.config.Channel, payload); err != nil {
+		return fmt.Errorf("failed to publish invalidation: %w", err)
+	}
+	return nil
+}
+
+// localTTL 本地缓存时间不超过LocalTTL和Redis中的TTL
+func (c *NearCache[T]) localTTL(ttl time.Duration) time.Duration {
+	if ttl > 0 && ttl < c.config.LocalTTL {
+		return ttl
+	}
+	return c.config.LocalTTL
+}
+
+// Get 依次查询本地缓存和Redis，不存在时返回 ErrCacheMiss，命中负缓存时返回 ErrNotFound
+func (c *NearCache[T]) Get(ctx context.Context, key string) (T, error) {
+	if value, notFound, ok := c.l1.get(key); ok {
+		atomic.AddInt64(&c.l1Hits, 1)
+		if notFound {
+			return value, ErrNotFound
+		}
+		return value, nil
+	}
+	atomic.AddInt64(&c.l1Misses, 1)
+
+	// 本地副本不能比Redis中的键活得更久
+	value, ttl, err := c.l2.getWithTTL(ctx, key)
+	switch {
+	case err == nil:
+		atomic.AddInt64(&c.l2Hits, 1)
+		c.l1.set(key, value, false, c.localTTL(ttl))
+	case errors.Is(err, ErrNotFound):
+		atomic.AddInt64(&c.l2Hits, 1)
+		c.l1.set(key, value, true, c.localTTL(ttl))
+	case errors.Is(err, ErrCacheMiss):
+		atomic.AddInt64(&c.l2Misses, 1)
+	}
+	return value, err
+}
+
+// Set 写入Redis和本地缓存，并通知其他实例淘汰本地副本
+func (c *NearCache[T]) Set(ctx context.Context, key string, value T, ttl time.Duration) error {
+	if err := c.l2.Set(ctx, key, value, ttl); err != nil {
+		return err
+	}
+	c.l1.set(key, value, false, c.localTTL(ttl))
+	return c.publish(ctx, []string{key})
+}
+
+// Delete 删除Redis和本地缓存，并通知其他实例淘汰本地副本
+func (c *NearCache[T]) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	for _, key := range keys {
+		c.l1.delete(key)
+	}
+	if err := c.l2.Delete(ctx, keys...); err != nil {
+		return err
+	}
+	return c.publish(ctx, keys)
+}
+
+// GetOrLoad 依次查询本地缓存和Redis，都未命中时调用loader加载并写入两级缓存
+func (c *NearCache[T]) GetOrLoad(ctx context.Context, key string, ttl time.Duration, loader func(ctx context.Context) (T, error)) (T, error) {
+	value, err := c.Get(ctx, key)
+	if err == nil || errors.Is(err, ErrNotFound) {
+		return value, err
+	}
+
+	ch := c.l2.group.DoChan(key, func() (interface{}, error) {
+		atomic.AddInt64(&c.loads, 1)
+		value, err := c.l2.load(ctx, key, ttl, loader)
+		if err == nil {
+			c.l1.set(key, value, false, c.localTTL(ttl))
+		} else if errors.Is(err, ErrNotFound) && c.config.NegativeTTL > 0 {
+			c.l1.set(key, value, true, c.localTTL(c.config.NegativeTTL))
+		}
+		return value, err
+	})
+	select {
+	case result := <-ch:
+		value, _ := result.Val.(T)
+		return value, result.Err
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+// Stats 返回各级缓存的命中统计
+func (c *NearCache[T]) Stats() NearCacheStats {
+	return NearCacheStats{
+		L1Hits:        atomic.LoadInt64(&c.l1Hits),
+		L1Misses:      atomic.LoadInt64(&c.l1Misses),
+		L2Hits:        atomic.LoadInt64(&c.l2Hits),
+		L2Misses:      atomic.LoadInt64(&c.l2Misses),
+		Loads:         atomic.LoadInt64(&c.loads),
+		Invalidations: atomic.LoadInt64(&c.invalidations),
+		L1Size:        c.l1.len(),
+	}
+}
+
+// Close 停止接收失效通知
+func (c *NearCache[T]) Close() error {
+	c.cancel()
+	<-c.done
+	return c.sub.Close()
+}
+
+// localCacheItem 本地缓存项
+type localCacheItem[T any] struct {
+	key       string
+	value     T
+	notFound  bool
+	expiresAt time.Time
+}
+
+// localCache 带过期时间的LRU本地缓存
+type localCache[T any] struct {
+	capacity int
+	items    map[string]*list.Element
+	order    *list.List
+	mutex    sync.Mutex
+}
+
+func newLocalCache[T any](capacity int) *localCache[T] {
+	return &localCache[T]{
+		capacity: capacity,
+		items:    make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+func (l *localCache[T]) get(key string) (T, bool, bool) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	var zero T
+	elem, ok := l.items[key]
+	if !ok {
+		return zero, false, false
+	}
+	item := elem.Value.(*localCacheItem[T])
+	if time.Now().After(item.expiresAt) {
+		l.order.Remove(elem)
+		delete(l.items, key)
+		return zero, false, false
+	}
+	l.order.MoveToFront(elem)
+	return item.value, item.notFound, true
+}
+
+func (l *localCache[T]) set(key string, value T, notFound bool, ttl time.Duration) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	expiresAt := time.Now().Add(ttl)
+	if elem, ok := l.items[key]; ok {
+		item := elem.Value.(*localCacheItem[T])
+		item.value, item.notFound, item.expiresAt = value, notFound, expiresAt
+		l.order.MoveToFront(elem)
+		return
+	}
+	l.items[key] = l.order.PushFront(&localCacheItem[T]{key: key, value: value, notFound: notFound, expiresAt: expiresAt})
+	for l.order.Len() > l.capacity {
+		oldest := l.order.Back()
+		l.order.Remove(oldest)
+		delete(l.items, oldest.Value.(*localCacheItem[T]).key)
+	}
+}
+
+func (l *localCache[T]) delete(key string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if elem, ok := l.items[key]; ok {
+		l.order.Remove(elem)
+		delete(l.items, key)
+	}
+}
+
+func (l *localCache[T]) len() int {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.order.Len()
+}
@@ -0,0 +1,162 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// newTestNearCache 创建共享同一前缀的二级缓存，模拟多个实例
+func newTestNearCache(t *testing.T, prefix string) *NearCache[string] {
+	t.Helper()
+	config := DefaultNearCacheConfig()
+	config.Prefix = prefix
+	config.Jitter = 0
+	cache, err := NewNearCache[string](newTestClient(t), config)
+	if err != nil {
+		t.Fatalf("NewNearCache failed: %v", err)
+	}
+	t.Cleanup(func() { cache.Close() })
+	return cache
+}
+
+// waitFor 轮询等待条件成立
+func waitFor(t *testing.T, cond func() bool) bool {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return true
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	return false
+}
+
+func TestNearCache(t *testing.T) {
+	ctx := context.Background()
+	a := newTestNearCache(t, "test:near:")
+	b := newTestNearCache(t, "test:near:")
+	a.Delete(ctx, "k")
+	defer a.Delete(ctx, "k")
+
+	if _, err := a.Get(ctx, "k"); err != ErrCacheMiss {
+		t.Fatalf("Expected ErrCacheMiss, got %v", err)
+	}
+
+	// A写入后B从Redis读取并缓存到本地
+	if err := a.Set(ctx, "k", "v1", time.Minute); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if value, err := b.Get(ctx, "k"); err != nil || value != "v1" {
+			t.Fatalf("Expected v1, got %q, %v", value, err)
+		}
+	}
+	stats := b.Stats()
+	if stats.L1Hits != 1 || stats.L1Misses != 1 || stats.L2Hits != 1 || stats.L1Size != 1 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+
+	// A更新后B的本地副本被淘汰
+	if err := a.Set(ctx, "k", "v2", time.Minute); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if !waitFor(t, func() bool { return b.Stats().L1Size == 0 }) {
+		t.Fatal("Local copy not invalidated after Set")
+	}
+	if value, _ := b.Get(ctx, "k"); value != "v2" {
+		t.Errorf("Expected v2, got %q", value)
+	}
+	if value, _ := a.Get(ctx, "k"); value != "v2" {
+		t.Errorf("Expected writer to keep its local copy, got %q", value)
+	}
+	if a.Stats().Invalidations != 0 {
+		t.Error("Writer should ignore its own invalidations")
+	}
+
+	// 删除同样会通知
+	if err := a.Delete(ctx, "k"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if !waitFor(t, func() bool { return b.Stats().L1Size == 0 }) {
+		t.Fatal("Local copy not invalidated after Delete")
+	}
+	if _, err := b.Get(ctx, "k"); err != ErrCacheMiss {
+		t.Errorf("Expected ErrCacheMiss after delete, got %v", err)
+	}
+}
+
+func TestNearCacheGetOrLoad(t *testing.T) {
+	ctx := context.Background()
+	cache := newTestNearCache(t, "test:near:load:")
+	cache.Delete(ctx, "user", "missing")
+	defer cache.Delete(ctx, "user", "missing")
+
+	loader := func(ctx context.Context) (string, error) { return "alice", nil }
+	for i := 0; i < 3; i++ {
+		if value, err := cache.GetOrLoad(ctx, "user", time.Minute, loader); err != nil || value != "alice" {
+			t.Fatalf("GetOrLoad returned %q, %v", value, err)
+		}
+	}
+	stats := cache.Stats()
+	if stats.Loads != 1 || stats.L2Misses != 1 || stats.L1Hits != 2 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+
+	// 负缓存同样进入本地缓存
+	notFound := func(ctx context.Context) (string, error) { return "", ErrNotFound }
+	for i := 0; i < 2; i++ {
+		if _, err := cache.GetOrLoad(ctx, "missing", time.Minute, notFound); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("Expected ErrNotFound, got %v", err)
+		}
+	}
+	if cache.Stats().Loads != 2 {
+		t.Errorf("Expected not-found to be cached, got %d loads", cache.Stats().Loads)
+	}
+}
+
+func TestNearCacheLocalTTLFollowsRedis(t *testing.T) {
+	ctx := context.Background()
+	a := newTestNearCache(t, "test:near:ttl:")
+	b := newTestNearCache(t, "test:near:ttl:")
+	a.Delete(ctx, "k")
+	defer a.Delete(ctx, "k")
+
+	// B从Redis读取时，本地副本的过期时间不超过键的剩余时间
+	if err := a.Set(ctx, "k", "v", 2*time.Second); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if value, err := b.Get(ctx, "k"); err != nil || value != "v" {
+		t.Fatalf("Expected v, got %q, %v", value, err)
+	}
+	b.l1.mutex.Lock()
+	expiresAt := b.l1.items["k"].Value.(*localCacheItem[string]).expiresAt
+	b.l1.mutex.Unlock()
+	if remaining := time.Until(expiresAt); remaining > 2*time.Second || remaining <= 0 {
+		t.Errorf("Expected local copy to expire with the Redis key, got %v", remaining)
+	}
+}
+
+func TestLocalCache(t *testing.T) {
+	cache := newLocalCache[int](2)
+	cache.set("a", 1, false, time.Minute)
+	cache.set("b", 2, false, time.Minute)
+	cache.get("a")
+	cache.set("c", 3, false, time.Minute)
+
+	// 淘汰最久未使用的b
+	if _, _, ok := cache.get("b"); ok {
+		t.Error("Expected b to be evicted")
+	}
+	if value, _, ok := cache.get("a"); !ok || value != 1 {
+		t.Errorf("Expected a=1, got %d, %v", value, ok)
+	}
+
+	cache.set("d", 4, false, time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	if _, _, ok := cache.get("d"); ok {
+		t.Error("Expected d to expire")
+	}
+}
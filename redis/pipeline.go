@@ -9,255 +9,274 @@ import (
 
 // Pipeline 管道操作
 type Pipeline struct {
-	pipe redis.Pipeliner
+	pipe     redis.Pipeliner
+	prefix   keyPrefix
+	keysCmds []*redis.StringSliceCmd // 需要在执行后去掉前缀的 KEYS 命令
 }
 
 // NewPipeline 创建管道实例
 func (c *Client) NewPipeline() *Pipeline {
-	return &Pipeline{pipe: c.client.Pipeline()}
+	return &Pipeline{pipe: c.client.Pipeline(), prefix: c.prefix}
 }
 
 // Exec 执行管道命令
 func (p *Pipeline) Exec(ctx context.Context) ([]redis.Cmder, error) {
-	return p.pipe.Exec(ctx)
+	cmds, err := p.pipe.Exec(ctx)
+	for _, cmd := range p.keysCmds {
+		if cmd.Err() == nil {
+			cmd.SetVal(p.prefix.stripAll(cmd.Val()))
+		}
+	}
+	p.keysCmds = nil
+	return cmds, err
 }
 
 // Discard 丢弃管道命令
 func (p *Pipeline) Discard() {
 	p.pipe.Discard()
+	p.keysCmds = nil
 }
 
 // String 操作
 func (p *Pipeline) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.StatusCmd {
-	return p.pipe.Set(ctx, key, value, expiration)
+	return p.pipe.Set(ctx, p.prefix.key(key), value, expiration)
 }
 
 func (p *Pipeline) Get(ctx context.Context, key string) *redis.StringCmd {
-	return p.pipe.Get(ctx, key)
+	return p.pipe.Get(ctx, p.prefix.key(key))
 }
 
 func (p *Pipeline) Del(ctx context.Context, keys ...string) *redis.IntCmd {
-	return p.pipe.Del(ctx, keys...)
+	return p.pipe.Del(ctx, p.prefix.keys(keys)...)
 }
 
 func (p *Pipeline) Exists(ctx context.Context, keys ...string) *redis.IntCmd {
-	return p.pipe.Exists(ctx, keys...)
+	return p.pipe.Exists(ctx, p.prefix.keys(keys)...)
 }
 
 func (p *Pipeline) Expire(ctx context.Context, key string, expiration time.Duration) *redis.BoolCmd {
-	return p.pipe.Expire(ctx, key, expiration)
+	return p.pipe.Expire(ctx, p.prefix.key(key), expiration)
 }
 
 func (p *Pipeline) TTL(ctx context.Context, key string) *redis.DurationCmd {
-	return p.pipe.TTL(ctx, key)
+	return p.pipe.TTL(ctx, p.prefix.key(key))
 }
 
 func (p *Pipeline) Incr(ctx context.Context, key string) *redis.IntCmd {
-	return p.pipe.Incr(ctx, key)
+	return p.pipe.Incr(ctx, p.prefix.key(key))
 }
 
 func (p *Pipeline) IncrBy(ctx context.Context, key string, value int64) *redis.IntCmd {
-	return p.pipe.IncrBy(ctx, key, value)
+	return p.pipe.IncrBy(ctx, p.prefix.key(key), value)
 }
 
 func (p *Pipeline) Decr(ctx context.Context, key string) *redis.IntCmd {
-	return p.pipe.Decr(ctx, key)
+	return p.pipe.Decr(ctx, p.prefix.key(key))
 }
 
 func (p *Pipeline) DecrBy(ctx context.Context, key string, value int64) *redis.IntCmd {
-	return p.pipe.DecrBy(ctx, key, value)
+	return p.pipe.DecrBy(ctx, p.prefix.key(key), value)
 }
 
 func (p *Pipeline) MGet(ctx context.Context, keys ...string) *redis.SliceCmd {
-	return p.pipe.MGet(ctx, keys...)
+	return p.pipe.MGet(ctx, p.prefix.keys(keys)...)
 }
 
 func (p *Pipeline) MSet(ctx context.Context, pairs ...interface{}) *redis.StatusCmd {
-	return p.pipe.MSet(ctx, pairs...)
+	return p.pipe.MSet(ctx, p.prefix.pairs(pairs)...)
 }
 
 // Hash 操作
 func (p *Pipeline) HSet(ctx context.Context, key string, values ...interface{}) *redis.IntCmd {
-	return p.pipe.HSet(ctx, key, values...)
+	return p.pipe.HSet(ctx, p.prefix.key(key), values...)
 }
 
 func (p *Pipeline) HGet(ctx context.Context, key, field string) *redis.StringCmd {
-	return p.pipe.HGet(ctx, key, field)
+	return p.pipe.HGet(ctx, p.prefix.key(key), field)
 }
 
 func (p *Pipeline) HGetAll(ctx context.Context, key string) *redis.MapStringStringCmd {
-	return p.pipe.HGetAll(ctx, key)
+	return p.pipe.HGetAll(ctx, p.prefix.key(key))
 }
 
 func (p *Pipeline) HDel(ctx context.Context, key string, fields ...string) *redis.IntCmd {
-	return p.pipe.HDel(ctx, key, fields...)
+	return p.pipe.HDel(ctx, p.prefix.key(key), fields...)
 }
 
 func (p *Pipeline) HExists(ctx context.Context, key, field string) *redis.BoolCmd {
-	return p.pipe.HExists(ctx, key, field)
+	return p.pipe.HExists(ctx, p.prefix.key(key), field)
 }
 
 func (p *Pipeline) HLen(ctx context.Context, key string) *redis.IntCmd {
-	return p.pipe.HLen(ctx, key)
+	return p.pipe.HLen(ctx, p.prefix.key(key))
 }
 
 func (p *Pipeline) HKeys(ctx context.Context, key string) *redis.StringSliceCmd {
-	return p.pipe.HKeys(ctx, key)
+	return p.pipe.HKeys(ctx, p.prefix.key(key))
 }
 
 func (p *Pipeline) HVals(ctx context.Context, key string) *redis.StringSliceCmd {
-	return p.pipe.HVals(ctx, key)
+	return p.pipe.HVals(ctx, p.prefix.key(key))
 }
 
 func (p *Pipeline) HMGet(ctx context.Context, key string, fields ...string) *redis.SliceCmd {
-	return p.pipe.HMGet(ctx, key, fields...)
+	return p.pipe.HMGet(ctx, p.prefix.key(key), fields...)
 }
 
 func (p *Pipeline) HMSet(ctx context.Context, key string, values ...interface{}) *redis.BoolCmd {
-	return p.pipe.HMSet(ctx, key, values...)
+	return p.pipe.HMSet(ctx, p.prefix.key(key), values...)
 }
 
 func (p *Pipeline) HIncrBy(ctx context.Context, key, field string, incr int64) *redis.IntCmd {
-	return p.pipe.HIncrBy(ctx, key, field, incr)
+	return p.pipe.HIncrBy(ctx, p.prefix.key(key), field, incr)
 }
 
 func (p *Pipeline) HIncrByFloat(ctx context.Context, key, field string, incr float64) *redis.FloatCmd {
-	return p.pipe.HIncrByFloat(ctx, key, field, incr)
+	return p.pipe.HIncrByFloat(ctx, p.prefix.key(key), field, incr)
 }
 
 // List 操作
 func (p *Pipeline) LPush(ctx context.Context, key string, values ...interface{}) *redis.IntCmd {
-	return p.pipe.LPush(ctx, key, values...)
+	return p.pipe.LPush(ctx, p.prefix.key(key), values...)
 }
 
 func (p *Pipeline) RPush(ctx context.Context, key string, values ...interface{}) *redis.IntCmd {
-	return p.pipe.RPush(ctx, key, values...)
+	return p.pipe.RPush(ctx, p.prefix.key(key), values...)
 }
 
 func (p *Pipeline) LPop(ctx context.Context, key string) *redis.StringCmd {
-	return p.pipe.LPop(ctx, key)
+	return p.pipe.LPop(ctx, p.prefix.key(key))
 }
 
 func (p *Pipeline) RPop(ctx context.Context, key string) *redis.StringCmd {
-	return p.pipe.RPop(ctx, key)
+	return p.pipe.RPop(ctx, p.prefix.key(key))
 }
 
 func (p *Pipeline) LLen(ctx context.Context, key string) *redis.IntCmd {
-	return p.pipe.LLen(ctx, key)
+	return p.pipe.LLen(ctx, p.prefix.key(key))
 }
 
 func (p *Pipeline) LRange(ctx context.Context, key string, start, stop int64) *redis.StringSliceCmd {
-	return p.pipe.LRange(ctx, key, start, stop)
+	return p.pipe.LRange(ctx, p.prefix.key(key), start, stop)
 }
 
 func (p *Pipeline) LIndex(ctx context.Context, key string, index int64) *redis.StringCmd {
-	return p.pipe.LIndex(ctx, key, index)
+	return p.pipe.LIndex(ctx, p.prefix.key(key), index)
 }
 
 func (p *Pipeline) LSet(ctx context.Context, key string, index int64, value interface{}) *redis.StatusCmd {
-	return p.pipe.LSet(ctx, key, index, value)
+	return p.pipe.LSet(ctx, p.prefix.key(key), index, value)
 }
 
 func (p *Pipeline) LRem(ctx context.Context, key string, count int64, value interface{}) *redis.IntCmd {
-	return p.pipe.LRem(ctx, key, count, value)
+	return p.pipe.LRem(ctx, p.prefix.key(key), count, value)
 }
 
 func (p *Pipeline) LTrim(ctx context.Context, key string, start, stop int64) *redis.StatusCmd {
-	return p.pipe.LTrim(ctx, key, start, stop)
+	return p.pipe.LTrim(ctx, p.prefix.key(key), start, stop)
 }
 
 // Set 操作
 func (p *Pipeline) SAdd(ctx context.Context, key string, members ...interface{}) *redis.IntCmd {
-	return p.pipe.SAdd(ctx, key, members...)
+	return p.pipe.SAdd(ctx, p.prefix.key(key), members...)
 }
 
 func (p *Pipeline) SRem(ctx context.Context, key string, members ...interface{}) *redis.IntCmd {
-	return p.pipe.SRem(ctx, key, members...)
+	return p.pipe.SRem(ctx, p.prefix.key(key), members...)
 }
 
 func (p *Pipeline) SPop(ctx context.Context, key string) *redis.StringCmd {
-	return p.pipe.SPop(ctx, key)
+	return p.pipe.SPop(ctx, p.prefix.key(key))
 }
 
 func (p *Pipeline) SCard(ctx context.Context, key string) *redis.IntCmd {
-	return p.pipe.SCard(ctx, key)
+	return p.pipe.SCard(ctx, p.prefix.key(key))
 }
 
 func (p *Pipeline) SIsMember(ctx context.Context, key string, member interface{}) *redis.BoolCmd {
-	return p.pipe.SIsMember(ctx, key, member)
+	return p.pipe.SIsMember(ctx, p.prefix.key(key), member)
 }
 
 func (p *Pipeline) SMembers(ctx context.Context, key string) *redis.StringSliceCmd {
-	return p.pipe.SMembers(ctx, key)
+	return p.pipe.SMembers(ctx, p.prefix.key(key))
 }
 
 func (p *Pipeline) SUnion(ctx context.Context, keys ...string) *redis.StringSliceCmd {
-	return p.pipe.SUnion(ctx, keys...)
+	return p.pipe.SUnion(ctx, p.prefix.keys(keys)...)
 }
 
 func (p *Pipeline) SInter(ctx context.Context, keys ...string) *redis.StringSliceCmd {
-	return p.pipe.SInter(ctx, keys...)
+	return p.pipe.SInter(ctx, p.prefix.keys(keys)...)
 }
 
 func (p *Pipeline) SDiff(ctx context.Context, keys ...string) *redis.StringSliceCmd {
-	return p.pipe.SDiff(ctx, keys...)
+	return p.pipe.SDiff(ctx, p.prefix.keys(keys)...)
 }
 
 // ZSet 操作
 func (p *Pipeline) ZAdd(ctx context.Context, key string, members ...redis.Z) *redis.IntCmd {
-	return p.pipe.ZAdd(ctx, key, members...)
+	return p.pipe.ZAdd(ctx, p.prefix.key(key), members...)
 }
 
 func (p *Pipeline) ZRem(ctx context.Context, key string, members ...interface{}) *redis.IntCmd {
-	return p.pipe.ZRem(ctx, key, members...)
+	return p.pipe.ZRem(ctx, p.prefix.key(key), members...)
 }
 
 func (p *Pipeline) ZCard(ctx context.Context, key string) *redis.IntCmd {
-	return p.pipe.ZCard(ctx, key)
+	return p.pipe.ZCard(ctx, p.prefix.key(key))
 }
 
 func (p *Pipeline) ZCount(ctx context.Context, key, min, max string) *redis.IntCmd {
-	return p.pipe.ZCount(ctx, key, min, max)
+	return p.pipe.ZCount(ctx, p.prefix.key(key), min, max)
 }
 
 func (p *Pipeline) ZRange(ctx context.Context, key string, start, stop int64) *redis.StringSliceCmd {
-	return p.pipe.ZRange(ctx, key, start, stop)
+	return p.pipe.ZRange(ctx, p.prefix.key(key), start, stop)
 }
 
 func (p *Pipeline) ZRangeWithScores(ctx context.Context, key string, start, stop int64) *redis.ZSliceCmd {
-	return p.pipe.ZRangeWithScores(ctx, key, start, stop)
+	return p.pipe.ZRangeWithScores(ctx, p.prefix.key(key), start, stop)
 }
 
 func (p *Pipeline) ZRangeByScore(ctx context.Context, key string, opt *redis.ZRangeBy) *redis.StringSliceCmd {
-	return p.pipe.ZRangeByScore(ctx, key, opt)
+	return p.pipe.ZRangeByScore(ctx, p.prefix.key(key), opt)
 }
 
 func (p *Pipeline) ZRangeByScoreWithScores(ctx context.Context, key string, opt *redis.ZRangeBy) *redis.ZSliceCmd {
-	return p.pipe.ZRangeByScoreWithScores(ctx, key, opt)
+	return p.pipe.ZRangeByScoreWithScores(ctx, p.prefix.key(key), opt)
 }
 
 func (p *Pipeline) ZRevRange(ctx context.Context, key string, start, stop int64) *redis.StringSliceCmd {
-	return p.pipe.ZRevRange(ctx, key, start, stop)
+	return p.pipe.ZRevRange(ctx, p.prefix.key(key), start, stop)
 }
 
 func (p *Pipeline) ZRevRangeWithScores(ctx context.Context, key string, start, stop int64) *redis.ZSliceCmd {
-	return p.pipe.ZRevRangeWithScores(ctx, key, start, stop)
+	return p.pipe.ZRevRangeWithScores(ctx, p.prefix.key(key), start, stop)
 }
 
 func (p *Pipeline) ZRank(ctx context.Context, key, member string) *redis.IntCmd {
-	return p.pipe.ZRank(ctx, key, member)
+	return p.pipe.ZRank(ctx, p.prefix.key(key), member)
 }
 
 func (p *Pipeline) ZRevRank(ctx context.Context, key, member string) *redis.IntCmd {
-	return p.pipe.ZRevRank(ctx, key, member)
+	return p.pipe.ZRevRank(ctx, p.prefix.key(key), member)
 }
 
 func (p *Pipeline) ZScore(ctx context.Context, key, member string) *redis.FloatCmd {
-	return p.pipe.ZScore(ctx, key, member)
+	return p.pipe.ZScore(ctx, p.prefix.key(key), member)
 }
 
 func (p *Pipeline) ZIncrBy(ctx context.Context, key string, increment float64, member string) *redis.FloatCmd {
-	return p.pipe.ZIncrBy(ctx, key, increment, member)
+	return p.pipe.ZIncrBy(ctx, p.prefix.key(key), increment, member)
+}
+
+// HyperLogLog 操作
+func (p *Pipeline) PFAdd(ctx context.Context, key string, elements ...interface{}) *redis.IntCmd {
+	return p.pipe.PFAdd(ctx, p.prefix.key(key), elements...)
+}
+
+func (p *Pipeline) PFCount(ctx context.Context, keys ...string) *redis.IntCmd {
+	return p.pipe.PFCount(ctx, p.prefix.keys(keys)...)
 }
 
 // 通用操作
@@ -266,7 +285,11 @@ func (p *Pipeline) Ping(ctx context.Context) *redis.StatusCmd {
 }
 
 func (p *Pipeline) Keys(ctx context.Context, pattern string) *redis.StringSliceCmd {
-	return p.pipe.Keys(ctx, pattern)
+	cmd := p.pipe.Keys(ctx, p.prefix.pattern(pattern))
+	if p.prefix != "" {
+		p.keysCmds = append(p.keysCmds, cmd)
+	}
+	return cmd
 }
 
 func (p *Pipeline) FlushDB(ctx context.Context) *redis.StatusCmd {
@@ -9,7 +9,7 @@ import (
 
 // PubSub 发布订阅操作
 type PubSub struct {
-	client *redis.Client
+	client redis.UniversalClient
 	pubsub *redis.PubSub
 }
 
@@ -148,7 +148,7 @@ func (ps *PubSub) PubSubShardNumSub(ctx context.Context, channels ...string) (ma
 
 // Publisher 发布者
 type Publisher struct {
-	client *redis.Client
+	client redis.UniversalClient
 }
 
 // NewPublisher 创建发布者
@@ -176,7 +176,7 @@ func (p *Publisher) PublishMany(ctx context.Context, channel string, messages ..
 
 // Subscriber 订阅者
 type Subscriber struct {
-	client *redis.Client
+	client redis.UniversalClient
 	pubsub *redis.PubSub
 }
 
@@ -197,7 +197,7 @@ func (s *Subscriber) PSubscribe(ctx context.Context, channels ...string) error {
 	return s.pubsub.Ping(ctx)
 }
 
-// Listen 监听消息
+// Listen 监听消息，订阅关闭时返回nil；需要断线重订阅、类型化消息和并发处理时使用 SupervisedSubscriber
 func (s *Subscriber) Listen(ctx context.Context, handler func(*redis.Message)) error {
 	if s.pubsub == nil {
 		return redis.Nil
@@ -206,10 +206,11 @@ func (s *Subscriber) Listen(ctx context.Context, handler func(*redis.Message)) e
 	ch := s.pubsub.Channel()
 	for {
 		select {
-		case msg := <-ch:
-			if msg != nil {
-				handler(msg)
+		case msg, ok := <-ch:
+			if !ok {
+				return nil
 			}
+			handler(msg)
 		case <-ctx.Done():
 			return ctx.Err()
 		}
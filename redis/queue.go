@@ -0,0 +1,507 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/daxiong0327/tool-kit/goroutine"
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	// ErrQueueEmpty 队列中没有待处理的任务
+	ErrQueueEmpty = errors.New("redis: queue empty")
+	// ErrJobNotHeld 任务已超时被重新投递，或已被确认
+	ErrJobNotHeld = errors.New("redis: job not held")
+)
+
+// 队列使用的键，均以 {name} 为哈希标签，保证在集群中位于同一槽位：
+//
+//	pending           待处理任务ID列表，右端出队
+//	processing:<消费者> 消费者正在处理的任务ID列表
+//	inflight          处理中任务的可见性截止时间（毫秒）
+//	owners            任务ID -> 所在的处理中列表
+//	attempts          任务ID -> 已投递次数
+//	jobs              任务ID -> 任务内容
+//	dead              超过最大投递次数的任务ID列表
+
+// queueEnqueueScript 保存任务内容并加入待处理列表
+var queueEnqueueScript = redis.NewScript(`
+redis.call('HSET', KEYS[1], ARGV[1], ARGV[2])
+return redis.call('LPUSH', KEYS[2], ARGV[1])`)
+
+// queueDequeueScript 将任务移入消费者的处理中列表并记录可见性截止时间
+var queueDequeueScript = redis.NewScript(`
+local id = redis.call('RPOPLPUSH', KEYS[1], KEYS[2])
+if not id then
+    return false
+end
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+redis.call('ZADD', KEYS[3], now + tonumber(ARGV[1]), id)
+redis.call('HSET', KEYS[4], id, KEYS[2])
+local attempts = redis.call('HINCRBY', KEYS[5], id, 1)
+local payload = redis.call('HGET', KEYS[6], id) or ''
+return {id, payload, attempts}`)
+
+// queueAckScript 确认任务完成并删除任务数据
+var queueAckScript = redis.NewScript(`
+if redis.call('LREM', KEYS[1], 1, ARGV[1]) == 0 then
+    return 0
+end
+redis.call('ZREM', KEYS[2], ARGV[1])
+redis.call('HDEL', KEYS[3], ARGV[1])
+redis.call('HDEL', KEYS[4], ARGV[1])
+redis.call('HDEL', KEYS[5], ARGV[1])
+return 1`)
+
+// queueNackScript 任务处理失败，重新入队或进入死信列表
+// 返回 0 未持有，1 重新入队，2 进入死信列表
+var queueNackScript = redis.NewScript(`
+if redis.call('LREM', KEYS[1], 1, ARGV[1]) == 0 then
+    return 0
+end
+redis.call('ZREM', KEYS[2], ARGV[1])
+redis.call('HDEL', KEYS[3], ARGV[1])
+local max = tonumber(ARGV[2])
+local attempts = tonumber(redis.call('HGET', KEYS[4], ARGV[1]) or '0')
+if max > 0 and attempts >= max then
+    redis.call('LPUSH', KEYS[6], ARGV[1])
+    return 2
+end
+redis.call('LPUSH', KEYS[5], ARGV[1])
+return 1`)
+
+// queueExtendScript 延长仍由该消费者持有的任务的可见性截止时间
+var queueExtendScript = redis.NewScript(`
+if redis.call('HGET', KEYS[2], ARGV[1]) ~= KEYS[3] then
+    return 0
+end
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+redis.call('ZADD', KEYS[1], 'XX', now + tonumber(ARGV[2]), ARGV[1])
+return 1`)
+
+// queueReapScript 将超过可见性截止时间的任务重新入队，超过最大投递次数的进入死信列表。
+// KEYS[6]起为任务所在的处理中列表，不在其中的任务留到下一次回收
+// 返回 {重新入队数, 死信数}
+var queueReapScript = redis.NewScript(`
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local declared = {}
+for i = 6, #KEYS do
+    declared[KEYS[i]] = true
+end
+local ids = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', now, 'LIMIT', 0, tonumber(ARGV[2]))
+local max = tonumber(ARGV[1])
+local requeued, dead = 0, 0
+for _, id in ipairs(ids) do
+    local processing = redis.call('HGET', KEYS[2], id)
+    if not processing or declared[processing] then
+        if processing then
+            redis.call('LREM', processing, 1, id)
+        end
+        redis.call('ZREM', KEYS[1], id)
+        redis.call('HDEL', KEYS[2], id)
+        local attempts = tonumber(redis.call('HGET', KEYS[3], id) or '0')
+        if max > 0 and attempts >= max then
+            redis.call('LPUSH', KEYS[5], id)
+            dead = dead + 1
+        else
+            -- 超时的任务优先于新任务被重新处理
+            redis.call('RPUSH', KEYS[4], id)
+            requeued = requeued + 1
+        end
+    end
+end
+return {requeued, dead}`)
+
+// queueRequeueDeadScript 将死信任务重新放回待处理列表并清零投递次数
+var queueRequeueDeadScript = redis.NewScript(`
+local moved = 0
+for i = 1, tonumber(ARGV[1]) do
+    local id = redis.call('RPOPLPUSH', KEYS[1], KEYS[2])
+    if not id then
+        break
+    end
+    redis.call('HDEL', KEYS[3], id)
+    moved = moved + 1
+end
+return moved`)
+
+// QueueConfig 队列配置
+type QueueConfig struct {
+	VisibilityTimeout time.Duration `json:"visibility_timeout" yaml:"visibility_timeout"` // 任务被取出后的可见性超时，超时未确认会被重新投递
+	MaxAttempts       int           `json:"max_attempts" yaml:"max_attempts"`             // 最大投递次数，超过后进入死信列表，0表示不限制
+}
+
+// DefaultQueueConfig 默认队列配置
+func DefaultQueueConfig() *QueueConfig {
+	return &QueueConfig{
+		VisibilityTimeout: 30 * time.Second,
+		MaxAttempts:       3,
+	}
+}
+
+// Queue 基于列表的可靠任务队列
+type Queue struct {
+	client redis.UniversalClient
+	prefix keyPrefix
+	name   string
+	config *QueueConfig
+
+	// retry 处理失败的任务，默认为 Nack
+	retry func(ctx context.Context, job *Job) (bool, error)
+}
+
+// Job 队列任务
+type Job struct {
+	ID       string // 任务ID
+	Payload  []byte // 任务内容
+	Attempts int    // 已投递次数，包括本次
+
+	processing string
+}
+
+// NewQueue 创建任务队列，config为nil时使用默认配置
+func (c *Client) NewQueue(name string, config *QueueConfig) *Queue {
+	if config == nil {
+		config = DefaultQueueConfig()
+	}
+	cfg := *config
+	if cfg.VisibilityTimeout <= 0 {
+		cfg.VisibilityTimeout = DefaultQueueConfig().VisibilityTimeout
+	}
+	q := &Queue{client: c.client, prefix: c.prefix, name: name, config: &cfg}
+	q.retry = q.Nack
+	return q
+}
+
+// key 返回队列的键
+func (q *Queue) key(suffix string) string {
+	return q.prefix.key("{" + q.name + "}:" + suffix)
+}
+
+// processingKey 返回消费者的处理中列表
+func (q *Queue) processingKey(consumer string) string {
+	return q.key("processing:" + consumer)
+}
+
+// Name 返回队列名称
+func (q *Queue) Name() string {
+	return q.name
+}
+
+// Enqueue 添加任务并返回任务ID
+func (q *Queue) Enqueue(ctx context.Context, payload []byte) (string, error) {
+	id, err := newLockToken()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate job id: %w", err)
+	}
+	if err := queueEnqueueScript.Run(ctx, q.client, []string{q.key("jobs"), q.key("pending")}, id, payload).Err(); err != nil {
+		return "", fmt.Errorf("failed to enqueue job: %w", err)
+	}
+	return id, nil
+}
+
+// Dequeue 取出一个任务并移入消费者的处理中列表，队列为空时返回 ErrQueueEmpty。
+// 任务需要在可见性超时内 Ack，否则会被 Reap 重新投递
+func (q *Queue) Dequeue(ctx context.Context, consumer string) (*Job, error) {
+	processing := q.processingKey(consumer)
+	keys := []string{q.key("pending"), processing, q.key("inflight"), q.key("owners"), q.key("attempts"), q.key("jobs")}
+	values, err := queueDequeueScript.Run(ctx, q.client, keys, q.config.VisibilityTimeout.Milliseconds()).Slice()
+	if err == redis.Nil {
+		return nil, ErrQueueEmpty
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to dequeue job: %w", err)
+	}
+	if len(values) != 3 {
+		return nil, fmt.Errorf("unexpected dequeue result: %v", values)
+	}
+
+	id, _ := values[0].(string)
+	payload, _ := values[1].(string)
+	attempts, _ := values[2].(int64)
+	return &Job{ID: id, Payload: []byte(payload), Attempts: int(attempts), processing: processing}, nil
+}
+
+// Ack 确认任务处理完成，任务已超时被重新投递时返回 ErrJobNotHeld
+func (q *Queue) Ack(ctx context.Context, job *Job) error {
+	keys := []string{job.processing, q.key("inflight"), q.key("owners"), q.key("attempts"), q.key("jobs")}
+	acked, err := queueAckScript.Run(ctx, q.client, keys, job.ID).Int64()
+	if err != nil {
+		return fmt.Errorf("failed to ack job: %w", err)
+	}
+	if acked == 0 {
+		return ErrJobNotHeld
+	}
+	return nil
+}
+
+// Nack 任务处理失败，重新入队；投递次数达到上限时进入死信列表并返回true
+func (q *Queue) Nack(ctx context.Context, job *Job) (bool, error) {
+	keys := []string{job.processing, q.key("inflight"), q.key("owners"), q.key("attempts"), q.key("pending"), q.key("dead")}
+	result, err := queueNackScript.Run(ctx, q.client, keys, job.ID, q.config.MaxAttempts).Int64()
+	if err != nil {
+		return false, fmt.Errorf("failed to nack job: %w", err)
+	}
+	if result == 0 {
+		return false, ErrJobNotHeld
+	}
+	return result == 2, nil
+}
+
+// Extend 将任务的可见性截止时间延长到现在之后的timeout，用于处理耗时较长的任务
+func (q *Queue) Extend(ctx context.Context, job *Job, timeout time.Duration) error {
+	keys := []string{q.key("inflight"), q.key("owners"), job.processing}
+	extended, err := queueExtendScript.Run(ctx, q.client, keys, job.ID, timeout.Milliseconds()).Int64()
+	if err != nil {
+		return fmt.Errorf("failed to extend job: %w", err)
+	}
+	if extended == 0 {
+		return ErrJobNotHeld
+	}
+	return nil
+}
+
+// Reap 将超过可见性超时的任务重新入队，返回重新入队和进入死信列表的任务数
+func (q *Queue) Reap(ctx context.Context) (int64, int64, error) {
+	const batch = 1000
+	now, err := q.client.Time(ctx).Result()
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to reap jobs: %w", err)
+	}
+	ids, err := q.client.ZRangeByScore(ctx, q.key("inflight"), &redis.ZRangeBy{
+		Min:   "-inf",
+		Max:   strconv.FormatInt(now.UnixMilli(), 10),
+		Count: batch,
+	}).Result()
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to reap jobs: %w", err)
+	}
+	if len(ids) == 0 {
+		return 0, 0, nil
+	}
+	owners, err := q.client.HMGet(ctx, q.key("owners"), ids...).Result()
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to reap jobs: %w", err)
+	}
+
+	// 脚本访问的处理中列表都需要在KEYS中声明
+	keys := []string{q.key("inflight"), q.key("owners"), q.key("attempts"), q.key("pending"), q.key("dead")}
+	declared := make(map[string]bool)
+	for _, owner := range owners {
+		if processing, ok := owner.(string); ok && !declared[processing] {
+			declared[processing] = true
+			keys = append(keys, processing)
+		}
+	}
+	values, err := queueReapScript.Run(ctx, q.client, keys, q.config.MaxAttempts, batch).Int64Slice()
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to reap jobs: %w", err)
+	}
+	if len(values) != 2 {
+		return 0, 0, fmt.Errorf("unexpected reap result: %v", values)
+	}
+	return values[0], values[1], nil
+}
+
+// Len 返回待处理的任务数
+func (q *Queue) Len(ctx context.Context) (int64, error) {
+	return q.client.LLen(ctx, q.key("pending")).Result()
+}
+
+// InFlight 返回处理中的任务数
+func (q *Queue) InFlight(ctx context.Context) (int64, error) {
+	return q.client.ZCard(ctx, q.key("inflight")).Result()
+}
+
+// DeadLen 返回死信任务数
+func (q *Queue) DeadLen(ctx context.Context) (int64, error) {
+	return q.client.LLen(ctx, q.key("dead")).Result()
+}
+
+// DeadLetters 返回最早进入死信列表的最多count个任务
+func (q *Queue) DeadLetters(ctx context.Context, count int64) ([]*Job, error) {
+	if count <= 0 {
+		return nil, nil
+	}
+	ids, err := q.client.LRange(ctx, q.key("dead"), -count, -1).Result()
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+	payloads, err := q.client.HMGet(ctx, q.key("jobs"), ids...).Result()
+	if err != nil {
+		return nil, err
+	}
+	attempts, err := q.client.HMGet(ctx, q.key("attempts"), ids...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make([]*Job, 0, len(ids))
+	for i := len(ids) - 1; i >= 0; i-- {
+		job := &Job{ID: ids[i]}
+		if payload, ok := payloads[i].(string); ok {
+			job.Payload = []byte(payload)
+		}
+		if n, ok := attempts[i].(string); ok {
+			fmt.Sscan(n, &job.Attempts)
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+// RequeueDead 将最多count个死信任务重新放回待处理列表，返回移动的任务数
+func (q *Queue) RequeueDead(ctx context.Context, count int64) (int64, error) {
+	keys := []string{q.key("dead"), q.key("pending"), q.key("attempts")}
+	return queueRequeueDeadScript.Run(ctx, q.client, keys, count).Int64()
+}
+
+// PurgeDead 删除所有死信任务
+func (q *Queue) PurgeDead(ctx context.Context) error {
+	ids, err := q.client.LRange(ctx, q.key("dead"), 0, -1).Result()
+	if err != nil || len(ids) == 0 {
+		return err
+	}
+	pipe := q.client.TxPipeline()
+	pipe.HDel(ctx, q.key("jobs"), ids...)
+	pipe.HDel(ctx, q.key("attempts"), ids...)
+	pipe.LTrim(ctx, q.key("dead"), 0, -int64(len(ids))-1)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// QueueHandler 任务处理函数，返回错误时任务被 Nack
+type QueueHandler func(ctx context.Context, job *Job) error
+
+// ConsumerConfig 消费者配置
+type ConsumerConfig struct {
+	Name         string          // 消费者名称，决定处理中列表，重启后应保持不变
+	Concurrency  int             // 同时处理的最大任务数
+	PollInterval time.Duration   // 队列为空时的轮询间隔
+	ReapInterval time.Duration   // 回收超时任务的间隔，0表示可见性超时的1/2
+	Pool         *goroutine.Pool // 执行任务的协程池，nil或协程池停止后为每个任务启动协程
+}
+
+// DefaultConsumerConfig 默认消费者配置
+func DefaultConsumerConfig(name string) *ConsumerConfig {
+	return &ConsumerConfig{
+		Name:         name,
+		Concurrency:  10,
+		PollInterval: 200 * time.Millisecond,
+	}
+}
+
+// Consume 持续取出任务并调用handler处理，直到ctx结束；返回前等待处理中的任务完成。
+// handler成功时 Ack，返回错误或panic时 Nack（延迟队列中按退避时间重新调度）
+func (q *Queue) Consume(ctx context.Context, config *ConsumerConfig, handler QueueHandler) error {
+	if config == nil || config.Name == "" {
+		return errors.New("consumer name is required")
+	}
+	cfg := *config
+	defaults := DefaultConsumerConfig(cfg.Name)
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = defaults.Concurrency
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = defaults.PollInterval
+	}
+	if cfg.ReapInterval <= 0 {
+		cfg.ReapInterval = q.config.VisibilityTimeout / 2
+	}
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	// 回收其他消费者超时的任务
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(cfg.ReapInterval)
+		defer ticker.Stop()
+		for {
+			q.Reap(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	slots := make(chan struct{}, cfg.Concurrency)
+	for {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		job, err := q.Dequeue(ctx, cfg.Name)
+		if err != nil {
+			<-slots
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(cfg.PollInterval):
+			}
+			continue
+		}
+
+		wg.Add(1)
+		var once sync.Once
+		started := make(chan struct{})
+		run := func() (err error) {
+			once.Do(func() {
+				close(started)
+				defer func() {
+					<-slots
+					wg.Done()
+				}()
+				err = q.process(ctx, job, handler)
+			})
+			return err
+		}
+		if cfg.Pool == nil {
+			go run()
+		} else if err := cfg.Pool.SubmitFunc(job.ID, run); err != nil {
+			// 协程池已满时在消费协程中执行，形成背压
+			run()
+		} else {
+			// 协程池在执行任务前停止时自行执行，否则返回前会一直等待
+			go func() {
+				select {
+				case <-started:
+				case <-cfg.Pool.Done():
+					run()
+				}
+			}()
+		}
+	}
+}
+
+// process 处理单个任务并确认结果，处理时间不超过可见性超时
+func (q *Queue) process(ctx context.Context, job *Job, handler QueueHandler) (err error) {
+	// 停止消费时让处理中的任务完成
+	jobCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), q.config.VisibilityTimeout)
+	defer cancel()
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job %s panicked: %v", job.ID, r)
+		}
+		if err != nil {
+			q.retry(context.WithoutCancel(ctx), job)
+			return
+		}
+		err = q.Ack(context.WithoutCancel(ctx), job)
+	}()
+	return handler(jobCtx, job)
+}
@@ -0,0 +1,230 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/daxiong0327/tool-kit/goroutine"
+)
+
+// newTestQueue 创建测试队列并清理残留数据
+func newTestQueue(t *testing.T, name string, config *QueueConfig) *Queue {
+	t.Helper()
+	client := newTestClient(t)
+	queue := client.NewQueue(name, config)
+	cleanup := func() {
+		keys, _ := client.Keys(context.Background(), "{"+name+"}:*")
+		if len(keys) > 0 {
+			client.Del(context.Background(), keys...)
+		}
+	}
+	cleanup()
+	t.Cleanup(cleanup)
+	return queue
+}
+
+func TestQueue(t *testing.T) {
+	ctx := context.Background()
+	queue := newTestQueue(t, "test:queue:basic", &QueueConfig{VisibilityTimeout: time.Minute, MaxAttempts: 2})
+
+	if _, err := queue.Dequeue(ctx, "c1"); err != ErrQueueEmpty {
+		t.Fatalf("Expected ErrQueueEmpty, got %v", err)
+	}
+
+	// 先进先出
+	first, _ := queue.Enqueue(ctx, []byte("a"))
+	queue.Enqueue(ctx, []byte("b"))
+	if n, _ := queue.Len(ctx); n != 2 {
+		t.Errorf("Expected 2 pending jobs, got %d", n)
+	}
+
+	job, err := queue.Dequeue(ctx, "c1")
+	if err != nil {
+		t.Fatalf("Dequeue failed: %v", err)
+	}
+	if job.ID != first || string(job.Payload) != "a" || job.Attempts != 1 {
+		t.Errorf("Unexpected job %+v", job)
+	}
+	if n, _ := queue.InFlight(ctx); n != 1 {
+		t.Errorf("Expected 1 in-flight job, got %d", n)
+	}
+
+	if err := queue.Ack(ctx, job); err != nil {
+		t.Errorf("Ack failed: %v", err)
+	}
+	if err := queue.Ack(ctx, job); err != ErrJobNotHeld {
+		t.Errorf("Expected ErrJobNotHeld on double ack, got %v", err)
+	}
+
+	// 失败后重新入队，达到最大投递次数进入死信列表
+	job, _ = queue.Dequeue(ctx, "c1")
+	if dead, err := queue.Nack(ctx, job); err != nil || dead {
+		t.Fatalf("Expected job to be requeued, got dead=%v err=%v", dead, err)
+	}
+	job, _ = queue.Dequeue(ctx, "c1")
+	if job.Attempts != 2 || string(job.Payload) != "b" {
+		t.Errorf("Unexpected redelivered job %+v", job)
+	}
+	if dead, err := queue.Nack(ctx, job); err != nil || !dead {
+		t.Fatalf("Expected job to be dead-lettered, got dead=%v err=%v", dead, err)
+	}
+
+	letters, err := queue.DeadLetters(ctx, 10)
+	if err != nil || len(letters) != 1 || string(letters[0].Payload) != "b" || letters[0].Attempts != 2 {
+		t.Fatalf("Unexpected dead letters %v, %v", letters, err)
+	}
+
+	// 死信重新入队后投递次数清零
+	if n, err := queue.RequeueDead(ctx, 10); err != nil || n != 1 {
+		t.Fatalf("RequeueDead returned %d, %v", n, err)
+	}
+	job, _ = queue.Dequeue(ctx, "c1")
+	if job == nil || job.Attempts != 1 {
+		t.Errorf("Expected requeued job with reset attempts, got %+v", job)
+	}
+}
+
+func TestQueueVisibilityTimeout(t *testing.T) {
+	ctx := context.Background()
+	queue := newTestQueue(t, "test:queue:visibility", &QueueConfig{VisibilityTimeout: 100 * time.Millisecond, MaxAttempts: 2})
+
+	queue.Enqueue(ctx, []byte("slow"))
+	job, _ := queue.Dequeue(ctx, "c1")
+
+	// 延长可见性后不会被回收
+	if err := queue.Extend(ctx, job, 500*time.Millisecond); err != nil {
+		t.Fatalf("Extend failed: %v", err)
+	}
+	time.Sleep(200 * time.Millisecond)
+	if requeued, _, _ := queue.Reap(ctx); requeued != 0 {
+		t.Errorf("Expected extended job not to be reaped, got %d", requeued)
+	}
+
+	// 超时后被重新投递给其他消费者
+	time.Sleep(400 * time.Millisecond)
+	// 脚本只修改在KEYS中声明的处理中列表
+	keys := []string{queue.key("inflight"), queue.key("owners"), queue.key("attempts"), queue.key("pending"), queue.key("dead")}
+	if values, err := queueReapScript.Run(ctx, queue.client, keys, 2, 1000).Int64Slice(); err != nil || values[0] != 0 {
+		t.Errorf("Expected undeclared processing list to be skipped, got %v, %v", values, err)
+	}
+	if requeued, dead, err := queue.Reap(ctx); err != nil || requeued != 1 || dead != 0 {
+		t.Fatalf("Reap returned %d, %d, %v", requeued, dead, err)
+	}
+	if err := queue.Ack(ctx, job); err != ErrJobNotHeld {
+		t.Errorf("Expected ErrJobNotHeld after reap, got %v", err)
+	}
+	redelivered, err := queue.Dequeue(ctx, "c2")
+	if err != nil || redelivered.ID != job.ID || redelivered.Attempts != 2 {
+		t.Fatalf("Unexpected redelivery %+v, %v", redelivered, err)
+	}
+
+	// 再次超时进入死信列表
+	time.Sleep(200 * time.Millisecond)
+	if _, dead, _ := queue.Reap(ctx); dead != 1 {
+		t.Errorf("Expected job to be dead-lettered, got %d", dead)
+	}
+	if n, _ := queue.DeadLen(ctx); n != 1 {
+		t.Errorf("Expected 1 dead job, got %d", n)
+	}
+	if err := queue.PurgeDead(ctx); err != nil {
+		t.Errorf("PurgeDead failed: %v", err)
+	}
+	if n, _ := queue.DeadLen(ctx); n != 0 {
+		t.Errorf("Expected dead list to be empty, got %d", n)
+	}
+}
+
+func TestQueueConsume(t *testing.T) {
+	queue := newTestQueue(t, "test:queue:consume", &QueueConfig{VisibilityTimeout: time.Second, MaxAttempts: 2})
+	ctx := context.Background()
+
+	for i := 0; i < 20; i++ {
+		queue.Enqueue(ctx, []byte("job"))
+	}
+	queue.Enqueue(ctx, []byte("fail"))
+	queue.Enqueue(ctx, []byte("panic"))
+
+	pool := goroutine.NewPool(&goroutine.PoolConfig{MaxWorkers: 4, QueueSize: 4})
+	defer pool.Stop()
+
+	var processed, running, maxRunning int32
+	var mu sync.Mutex
+	handler := func(ctx context.Context, job *Job) error {
+		n := atomic.AddInt32(&running, 1)
+		mu.Lock()
+		if n > maxRunning {
+			maxRunning = n
+		}
+		mu.Unlock()
+		defer atomic.AddInt32(&running, -1)
+		time.Sleep(5 * time.Millisecond)
+
+		switch string(job.Payload) {
+		case "fail":
+			return errors.New("failed")
+		case "panic":
+			panic("boom")
+		}
+		atomic.AddInt32(&processed, 1)
+		return nil
+	}
+
+	consumeCtx, cancel := context.WithCancel(ctx)
+	done := make(chan error, 1)
+	go func() {
+		done <- queue.Consume(consumeCtx, &ConsumerConfig{Name: "worker", Concurrency: 3, PollInterval: 10 * time.Millisecond, Pool: pool}, handler)
+	}()
+
+	if !waitFor(t, func() bool {
+		n, _ := queue.DeadLen(ctx)
+		return atomic.LoadInt32(&processed) == 20 && n == 2
+	}) {
+		t.Errorf("Expected 20 processed and 2 dead, got %d processed", atomic.LoadInt32(&processed))
+	}
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if maxRunning > 3 {
+		t.Errorf("Expected at most 3 concurrent jobs, got %d", maxRunning)
+	}
+	if n, _ := queue.InFlight(ctx); n != 0 {
+		t.Errorf("Expected no in-flight jobs, got %d", n)
+	}
+}
+
+func TestQueueConsumeStoppedPool(t *testing.T) {
+	queue := newTestQueue(t, "test:queue:stopped", &QueueConfig{VisibilityTimeout: time.Second, MaxAttempts: 2})
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		queue.Enqueue(ctx, []byte("job"))
+	}
+
+	// 协程池接受任务后不再执行，消费者需要自行处理
+	pool := goroutine.NewPool(&goroutine.PoolConfig{MaxWorkers: 1, QueueSize: 10})
+	pool.Stop()
+
+	var processed int32
+	consumeCtx, cancel := context.WithCancel(ctx)
+	done := make(chan error, 1)
+	go func() {
+		done <- queue.Consume(consumeCtx, &ConsumerConfig{Name: "worker", PollInterval: 10 * time.Millisecond, Pool: pool}, func(ctx context.Context, job *Job) error {
+			atomic.AddInt32(&processed, 1)
+			return nil
+		})
+	}()
+
+	if !waitFor(t, func() bool { return atomic.LoadInt32(&processed) == 3 }) {
+		t.Errorf("Expected 3 processed, got %d", atomic.LoadInt32(&processed))
+	}
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Consume did not return after the pool stopped")
+	}
+}
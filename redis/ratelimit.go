@@ -0,0 +1,321 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrRateLimited 请求超出限流配额
+var ErrRateLimited = errors.New("redis: rate limited")
+
+// RateLimitAlgorithm 限流算法
+type RateLimitAlgorithm string
+
+const (
+	// FixedWindow 固定窗口计数，窗口从第一次请求开始计时
+	FixedWindow RateLimitAlgorithm = "fixed_window"
+	// SlidingWindowLog 滑动窗口日志，精确记录每次请求的时间
+	SlidingWindowLog RateLimitAlgorithm = "sliding_window_log"
+	// SlidingWindowCounter 滑动窗口计数，按上一窗口的剩余比例加权估算
+	SlidingWindowCounter RateLimitAlgorithm = "sliding_window_counter"
+	// GCRA 通用信元速率算法，等价于令牌桶，支持突发
+	GCRA RateLimitAlgorithm = "gcra"
+)
+
+// 所有脚本使用Redis服务器时间（微秒），避免多实例之间的时钟偏差，
+// 返回 {是否允许, 剩余配额, 重置时间(微秒), 重试时间(微秒)}
+const rateLimitNowSource = `
+if redis.replicate_commands then redis.replicate_commands() end
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000000 + tonumber(t[2])
+local function num(x) return string.format('%.0f', x) end
+local function ms(x) return math.max(1, math.ceil(x / 1000)) end
+`
+
+// fixedWindowSource 固定窗口，同时作为 ScriptTemplates 的 rate_limit 模板。
+// 窗口以秒为单位，可以是小数；n省略时为1
+const fixedWindowSource = `
+local limit = tonumber(ARGV[1])
+local period = tonumber(ARGV[2]) * 1000000
+local n = tonumber(ARGV[3] or '1')
+local function num(x) return string.format('%.0f', x) end
+
+local current = tonumber(redis.call('GET', KEYS[1]) or '0')
+local ttl = redis.call('PTTL', KEYS[1])
+local reset = period
+if ttl > 0 then reset = ttl * 1000 end
+
+if current + n > limit then
+    return {0, math.max(0, limit - current), num(reset), num(reset)}
+end
+current = redis.call('INCRBY', KEYS[1], n)
+if ttl <= 0 then
+    redis.call('PEXPIRE', KEYS[1], math.max(1, math.ceil(period / 1000)))
+end
+return {1, limit - current, num(reset), 0}`
+
+// fixedWindowScript 固定窗口
+var fixedWindowScript = redis.NewScript(fixedWindowSource)
+
+// slidingWindowLogScript 滑动窗口日志
+var slidingWindowLogScript = redis.NewScript(rateLimitNowSource + `
+local limit = tonumber(ARGV[1])
+local period = tonumber(ARGV[2])
+local n = tonumber(ARGV[3])
+local token = ARGV[4]
+
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', num(now - period))
+local count = redis.call('ZCARD', KEYS[1])
+
+if count + n > limit then
+    -- 等待足够多的最早请求移出窗口
+    local entry = redis.call('ZRANGE', KEYS[1], count + n - limit - 1, count + n - limit - 1, 'WITHSCORES')
+    local retry = tonumber(entry[2]) + period - now
+    local last = redis.call('ZRANGE', KEYS[1], -1, -1, 'WITHSCORES')
+    local reset = tonumber(last[2]) + period - now
+    return {0, limit - count, num(reset), num(retry)}
+end
+
+for i = 1, n do
+    redis.call('ZADD', KEYS[1], num(now), token .. ':' .. i)
+end
+redis.call('PEXPIRE', KEYS[1], ms(period))
+return {1, limit - count - n, num(period), 0}`)
+
+// slidingWindowCounterScript 滑动窗口计数，当前与上一窗口的计数保存在同一个哈希中
+var slidingWindowCounterScript = redis.NewScript(rateLimitNowSource + `
+local limit = tonumber(ARGV[1])
+local period = tonumber(ARGV[2])
+local n = tonumber(ARGV[3])
+
+local window = math.floor(now / period)
+local elapsed = now - window * period
+local cur = tonumber(redis.call('HGET', KEYS[1], num(window)) or '0')
+local prev = tonumber(redis.call('HGET', KEYS[1], num(window - 1)) or '0')
+local count = prev * (period - elapsed) / period + cur
+
+-- 当前窗口有计数时，需要等到下一窗口结束才能完全恢复
+local reset = period - elapsed
+if cur > 0 then reset = reset + period end
+
+if count + n > limit then
+    local retry
+    if cur + n <= limit then
+        -- 上一窗口的权重衰减到足够小
+        retry = (period - elapsed) - (limit - cur - n) * period / prev
+    else
+        -- 进入下一窗口后，当前窗口成为上一窗口继续衰减
+        retry = (period - elapsed) + math.max(0, period - (limit - n) * period / cur)
+    end
+    return {0, math.max(0, math.floor(limit - count)), num(reset), num(math.ceil(retry))}
+end
+
+redis.call('HINCRBY', KEYS[1], num(window), n)
+for _, field in ipairs(redis.call('HKEYS', KEYS[1])) do
+    if tonumber(field) < window - 1 then
+        redis.call('HDEL', KEYS[1], field)
+    end
+end
+redis.call('PEXPIRE', KEYS[1], ms(2 * period))
+return {1, math.max(0, math.floor(limit - count - n)), num(2 * period - elapsed), 0}`)
+
+// gcraScript GCRA，保存理论到达时间（TAT）
+var gcraScript = redis.NewScript(rateLimitNowSource + `
+local burst = tonumber(ARGV[1])
+local period = tonumber(ARGV[2])
+local n = tonumber(ARGV[3])
+local rate = tonumber(ARGV[4])
+
+local emission = period / rate
+local tolerance = emission * burst
+
+local tat = tonumber(redis.call('GET', KEYS[1]) or '0')
+if tat < now then tat = now end
+
+local new_tat = tat + emission * n
+local diff = now - (new_tat - tolerance)
+if diff < 0 then
+    local remaining = math.floor((now - (tat - tolerance)) / emission)
+    return {0, math.max(0, remaining), num(tat - now), num(math.ceil(-diff))}
+end
+
+redis.call('SET', KEYS[1], num(new_tat), 'PX', ms(new_tat - now))
+return {1, math.floor(diff / emission), num(new_tat - now), 0}`)
+
+// RateLimiterConfig 限流器配置
+type RateLimiterConfig struct {
+	Algorithm RateLimitAlgorithm `json:"algorithm" yaml:"algorithm"` // 限流算法，默认GCRA
+	Limit     int64              `json:"limit" yaml:"limit"`         // 每个周期允许的请求数
+	Period    time.Duration      `json:"period" yaml:"period"`       // 周期
+	Burst     int64              `json:"burst" yaml:"burst"`         // GCRA的桶容量，0表示等于Limit
+	Prefix    string             `json:"prefix" yaml:"prefix"`       // 键前缀
+}
+
+// DefaultRateLimiterConfig 默认限流器配置，每秒100次
+func DefaultRateLimiterConfig() *RateLimiterConfig {
+	return &RateLimiterConfig{
+		Algorithm: GCRA,
+		Limit:     100,
+		Period:    time.Second,
+		Prefix:    "ratelimit:",
+	}
+}
+
+// RateLimitResult 限流结果
+type RateLimitResult struct {
+	Allowed    bool          // 是否允许
+	Limit      int64         // 配额上限
+	Remaining  int64         // 剩余配额
+	ResetAfter time.Duration // 配额完全恢复的时间
+	RetryAfter time.Duration // 被拒绝时需要等待的时间，允许时为0
+}
+
+// RateLimiter 基于Redis的分布式限流器，多个实例共享同一份配额
+type RateLimiter struct {
+	client redis.UniversalClient
+	config *RateLimiterConfig
+}
+
+// NewRateLimiter 创建限流器，config为nil时使用默认配置
+func (c *Client) NewRateLimiter(config *RateLimiterConfig) (*RateLimiter, error) {
+	if config == nil {
+		config = DefaultRateLimiterConfig()
+	}
+	cfg := *config
+	if cfg.Algorithm == "" {
+		cfg.Algorithm = GCRA
+	}
+	switch cfg.Algorithm {
+	case FixedWindow, SlidingWindowLog, SlidingWindowCounter, GCRA:
+	default:
+		return nil, fmt.Errorf("unknown rate limit algorithm: %s", cfg.Algorithm)
+	}
+	if cfg.Limit <= 0 {
+		return nil, fmt.Errorf("rate limit must be positive, got %d", cfg.Limit)
+	}
+	if cfg.Period < time.Millisecond {
+		return nil, fmt.Errorf("rate limit period must be at least 1ms, got %v", cfg.Period)
+	}
+	if cfg.Burst < 0 {
+		return nil, fmt.Errorf("rate limit burst must not be negative, got %d", cfg.Burst)
+	}
+	if cfg.Burst == 0 {
+		cfg.Burst = cfg.Limit
+	}
+	cfg.Prefix = c.prefix.key(cfg.Prefix)
+	return &RateLimiter{client: c.client, config: &cfg}, nil
+}
+
+// capacity 单次可以获取的最大配额
+func (r *RateLimiter) capacity() int64 {
+	if r.config.Algorithm == GCRA {
+		return r.config.Burst
+	}
+	return r.config.Limit
+}
+
+// Allow 获取一个配额
+func (r *RateLimiter) Allow(ctx context.Context, key string) (*RateLimitResult, error) {
+	return r.AllowN(ctx, key, 1)
+}
+
+// AllowN 原子地获取n个配额，配额不足时不消耗任何配额
+func (r *RateLimiter) AllowN(ctx context.Context, key string, n int64) (*RateLimitResult, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("rate limit n must be positive, got %d", n)
+	}
+	if n > r.capacity() {
+		return nil, fmt.Errorf("rate limit n %d exceeds capacity %d", n, r.capacity())
+	}
+
+	keys := []string{r.config.Prefix + key}
+	limit, period := r.config.Limit, r.config.Period.Microseconds()
+	var cmd *redis.Cmd
+	switch r.config.Algorithm {
+	case FixedWindow:
+		cmd = fixedWindowScript.Run(ctx, r.client, keys, limit, r.config.Period.Seconds(), n)
+	case SlidingWindowLog:
+		token, err := newLockToken()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate request token: %w", err)
+		}
+		cmd = slidingWindowLogScript.Run(ctx, r.client, keys, limit, period, n, token)
+	case SlidingWindowCounter:
+		cmd = slidingWindowCounterScript.Run(ctx, r.client, keys, limit, period, n)
+	default:
+		cmd = gcraScript.Run(ctx, r.client, keys, r.config.Burst, period, n, limit)
+	}
+
+	values, err := cmd.Slice()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run rate limit script: %w", err)
+	}
+	return parseRateLimitResult(values, r.capacity())
+}
+
+// parseRateLimitResult 解析脚本返回值
+func parseRateLimitResult(values []interface{}, limit int64) (*RateLimitResult, error) {
+	if len(values) != 4 {
+		return nil, fmt.Errorf("unexpected rate limit result: %v", values)
+	}
+	nums := make([]int64, len(values))
+	for i, v := range values {
+		switch v := v.(type) {
+		case int64:
+			nums[i] = v
+		case string:
+			if _, err := fmt.Sscan(v, &nums[i]); err != nil {
+				return nil, fmt.Errorf("unexpected rate limit result: %v", values)
+			}
+		default:
+			return nil, fmt.Errorf("unexpected rate limit result: %v", values)
+		}
+	}
+	return &RateLimitResult{
+		Allowed:    nums[0] == 1,
+		Limit:      limit,
+		Remaining:  nums[1],
+		ResetAfter: time.Duration(nums[2]) * time.Microsecond,
+		RetryAfter: time.Duration(nums[3]) * time.Microsecond,
+	}, nil
+}
+
+// Wait 阻塞直到获取一个配额或ctx结束
+func (r *RateLimiter) Wait(ctx context.Context, key string) (*RateLimitResult, error) {
+	return r.WaitN(ctx, key, 1)
+}
+
+// WaitN 阻塞直到获取n个配额，需要等待的时间超过ctx截止时间时立即返回
+func (r *RateLimiter) WaitN(ctx context.Context, key string, n int64) (*RateLimitResult, error) {
+	for {
+		result, err := r.AllowN(ctx, key, n)
+		if err != nil || result.Allowed {
+			return result, err
+		}
+
+		wait := result.RetryAfter
+		if wait <= 0 {
+			wait = time.Millisecond
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			return result, fmt.Errorf("%w: %w", ErrRateLimited, context.DeadlineExceeded)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return result, fmt.Errorf("%w: %w", ErrRateLimited, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// Reset 清除键的限流状态
+func (r *RateLimiter) Reset(ctx context.Context, key string) error {
+	return r.client.Del(ctx, r.config.Prefix+key).Err()
+}
@@ -0,0 +1,183 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	for _, algorithm := range []RateLimitAlgorithm{FixedWindow, SlidingWindowLog, SlidingWindowCounter, GCRA} {
+		t.Run(string(algorithm), func(t *testing.T) {
+			limiter, err := client.NewRateLimiter(&RateLimiterConfig{
+				Algorithm: algorithm,
+				Limit:     5,
+				Period:    500 * time.Millisecond,
+				Prefix:    "test:ratelimit:",
+			})
+			if err != nil {
+				t.Fatalf("NewRateLimiter failed: %v", err)
+			}
+			key := string(algorithm)
+			limiter.Reset(ctx, key)
+
+			// 配额内全部允许，剩余配额递减
+			for i := int64(0); i < 5; i++ {
+				result, err := limiter.Allow(ctx, key)
+				if err != nil {
+					t.Fatalf("Allow failed: %v", err)
+				}
+				if !result.Allowed {
+					t.Fatalf("Request %d should be allowed", i)
+				}
+				if result.Remaining != 4-i {
+					t.Errorf("Expected remaining %d, got %d", 4-i, result.Remaining)
+				}
+				if result.ResetAfter <= 0 || result.ResetAfter > time.Second {
+					t.Errorf("Unexpected reset after %v", result.ResetAfter)
+				}
+			}
+
+			// 超出配额被拒绝，并给出重试时间
+			result, err := limiter.Allow(ctx, key)
+			if err != nil {
+				t.Fatalf("Allow failed: %v", err)
+			}
+			if result.Allowed || result.Remaining != 0 {
+				t.Errorf("Expected request to be rejected, got %+v", result)
+			}
+			if result.RetryAfter <= 0 || result.RetryAfter > time.Second {
+				t.Errorf("Unexpected retry after %v", result.RetryAfter)
+			}
+
+			// Wait 在配额恢复后返回
+			start := time.Now()
+			result, err = limiter.Wait(ctx, key)
+			if err != nil || !result.Allowed {
+				t.Fatalf("Wait failed: %+v, %v", result, err)
+			}
+			if elapsed := time.Since(start); elapsed > 1500*time.Millisecond {
+				t.Errorf("Wait took too long: %v", elapsed)
+			}
+		})
+	}
+}
+
+func TestRateLimiterAllowN(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+	limiter, err := client.NewRateLimiter(&RateLimiterConfig{Algorithm: SlidingWindowLog, Limit: 10, Period: time.Minute})
+	if err != nil {
+		t.Fatalf("NewRateLimiter failed: %v", err)
+	}
+	key := "test:allown"
+	defer limiter.Reset(ctx, key)
+	limiter.Reset(ctx, key)
+
+	result, err := limiter.AllowN(ctx, key, 7)
+	if err != nil || !result.Allowed || result.Remaining != 3 {
+		t.Fatalf("Expected 7 allowed with 3 remaining, got %+v, %v", result, err)
+	}
+
+	// 配额不足时不消耗配额
+	result, err = limiter.AllowN(ctx, key, 4)
+	if err != nil || result.Allowed || result.Remaining != 3 {
+		t.Fatalf("Expected rejection with 3 remaining, got %+v, %v", result, err)
+	}
+	result, err = limiter.AllowN(ctx, key, 3)
+	if err != nil || !result.Allowed || result.Remaining != 0 {
+		t.Fatalf("Expected 3 allowed, got %+v, %v", result, err)
+	}
+
+	if _, err := limiter.AllowN(ctx, key, 11); err == nil {
+		t.Error("Expected error when n exceeds capacity")
+	}
+	if _, err := limiter.AllowN(ctx, key, 0); err == nil {
+		t.Error("Expected error for non-positive n")
+	}
+}
+
+func TestRateLimiterGCRABurst(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+	// 每秒10个，允许突发3个
+	limiter, err := client.NewRateLimiter(&RateLimiterConfig{Limit: 10, Period: time.Second, Burst: 3})
+	if err != nil {
+		t.Fatalf("NewRateLimiter failed: %v", err)
+	}
+	key := "test:gcra:burst"
+	defer limiter.Reset(ctx, key)
+	limiter.Reset(ctx, key)
+
+	allowed := 0
+	for i := 0; i < 5; i++ {
+		result, err := limiter.Allow(ctx, key)
+		if err != nil {
+			t.Fatalf("Allow failed: %v", err)
+		}
+		if result.Allowed {
+			allowed++
+		} else if result.RetryAfter > 100*time.Millisecond {
+			t.Errorf("Retry after should not exceed emission interval, got %v", result.RetryAfter)
+		}
+	}
+	if allowed != 3 {
+		t.Errorf("Expected burst of 3, got %d", allowed)
+	}
+}
+
+func TestRateLimiterWaitDeadline(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+	limiter, err := client.NewRateLimiter(&RateLimiterConfig{Algorithm: FixedWindow, Limit: 1, Period: time.Minute})
+	if err != nil {
+		t.Fatalf("NewRateLimiter failed: %v", err)
+	}
+	key := "test:wait:deadline"
+	defer limiter.Reset(ctx, key)
+	limiter.Reset(ctx, key)
+
+	if _, err := limiter.Allow(ctx, key); err != nil {
+		t.Fatalf("Allow failed: %v", err)
+	}
+
+	// 需要等待的时间超过截止时间，立即返回
+	timeoutCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	start := time.Now()
+	_, err = limiter.Wait(timeoutCtx, key)
+	if !errors.Is(err, ErrRateLimited) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected rate limited deadline error, got %v", err)
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Errorf("Wait should fail fast, took %v", time.Since(start))
+	}
+}
+
+func TestNewRateLimiterValidation(t *testing.T) {
+	client := newTestClient(t)
+
+	invalid := []*RateLimiterConfig{
+		{Algorithm: "leaky", Limit: 1, Period: time.Second},
+		{Limit: 0, Period: time.Second},
+		{Limit: 1, Period: 0},
+		{Limit: 1, Period: time.Second, Burst: -1},
+	}
+	for _, config := range invalid {
+		if _, err := client.NewRateLimiter(config); err == nil {
+			t.Errorf("Expected error for config %+v", config)
+		}
+	}
+
+	limiter, err := client.NewRateLimiter(nil)
+	if err != nil {
+		t.Fatalf("NewRateLimiter with nil config failed: %v", err)
+	}
+	if limiter.config.Algorithm != GCRA || limiter.config.Burst != limiter.config.Limit {
+		t.Errorf("Unexpected default config %+v", limiter.config)
+	}
+}
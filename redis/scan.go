@@ -0,0 +1,265 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrCommandDisabled 命令被 Config.GuardDangerousCommands 禁止
+var ErrCommandDisabled = errors.New("redis: command disabled by guard")
+
+// dangerousCommands 会阻塞服务端或清空数据的命令
+var dangerousCommands = []string{"keys", "flushdb", "flushall"}
+
+// KeyIterator 基于 SCAN 的键迭代器，集群模式下依次遍历所有主节点。
+// SCAN 保证遍历期间一直存在的键至少返回一次，但同一个键可能返回多次
+type KeyIterator struct {
+	client  *Client
+	match   string
+	count   int64
+	keyType string
+
+	nodes   []*redis.Client
+	node    int
+	cursor  uint64
+	started bool // 当前节点是否已发出过SCAN
+	loaded  bool // 是否已获取节点列表
+
+	keys []string
+	key  string
+	err  error
+}
+
+// Scan 返回匹配match的键迭代器，count为每次SCAN的数量提示（0使用服务端默认值），
+// keyType不为空时只返回该类型的键（string、list、set、zset、hash、stream）。
+// 只遍历当前前缀下的键，返回的键名不含前缀
+//
+//	iter := client.Scan(ctx, "user:*", 100, "")
+//	for iter.Next(ctx) {
+//		fmt.Println(iter.Key())
+//	}
+//	if err := iter.Err(); err != nil { ... }
+func (c *Client) Scan(ctx context.Context, match string, count int64, keyType string) *KeyIterator {
+	if match == "" {
+		match = "*"
+	}
+	return &KeyIterator{
+		client:  c,
+		match:   c.prefix.pattern(match),
+		count:   count,
+		keyType: keyType,
+	}
+}
+
+// Next 前进到下一个键，没有更多键或出错时返回false
+func (it *KeyIterator) Next(ctx context.Context) bool {
+	for {
+		if len(it.keys) > 0 {
+			it.key = it.keys[0]
+			it.keys = it.keys[1:]
+			return true
+		}
+		if it.err != nil {
+			return false
+		}
+		if !it.loaded {
+			it.nodes, it.err = it.client.masterNodes(ctx)
+			it.loaded = true
+			continue
+		}
+		if it.node >= len(it.nodes) {
+			return false
+		}
+		if it.started && it.cursor == 0 {
+			// 当前节点遍历完成
+			it.node++
+			it.started = false
+			continue
+		}
+
+		node := it.nodes[it.node]
+		var keys []string
+		var cursor uint64
+		var err error
+		if it.keyType != "" {
+			keys, cursor, err = node.ScanType(ctx, it.cursor, it.match, it.count, it.keyType).Result()
+		} else {
+			keys, cursor, err = node.Scan(ctx, it.cursor, it.match, it.count).Result()
+		}
+		if err != nil {
+			it.err = err
+			return false
+		}
+		it.keys = keys
+		it.cursor = cursor
+		it.started = true
+	}
+}
+
+// Key 返回当前键，不含前缀
+func (it *KeyIterator) Key() string {
+	return it.client.prefix.strip(it.key)
+}
+
+// Err 返回迭代过程中的错误
+func (it *KeyIterator) Err() error {
+	return it.err
+}
+
+// masterNodes 返回所有主节点，按地址排序使集群遍历顺序稳定
+func (c *Client) masterNodes(ctx context.Context) ([]*redis.Client, error) {
+	var mutex sync.Mutex
+	var nodes []*redis.Client
+	err := c.forEachMaster(ctx, func(ctx context.Context, client *redis.Client) error {
+		mutex.Lock()
+		nodes = append(nodes, client)
+		mutex.Unlock()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Options().Addr < nodes[j].Options().Addr
+	})
+	return nodes, nil
+}
+
+// DeleteConfig 批量删除配置
+type DeleteConfig struct {
+	BatchSize     int           `json:"batch_size" yaml:"batch_size"`         // 每批删除的键数量，同时作为SCAN的COUNT
+	BatchInterval time.Duration `json:"batch_interval" yaml:"batch_interval"` // 批次之间的间隔，用于限制删除速率
+	Type          string        `json:"type" yaml:"type"`                     // 只删除指定类型的键，为空时不限制
+}
+
+// DefaultDeleteConfig 默认批量删除配置
+func DefaultDeleteConfig() *DeleteConfig {
+	return &DeleteConfig{
+		BatchSize:     500,
+		BatchInterval: 10 * time.Millisecond,
+	}
+}
+
+// DeleteByPattern 使用 SCAN 遍历匹配pattern的键并分批 UNLINK，config为nil时使用默认配置，返回删除的键数量。
+// 出错或ctx结束时返回已删除的数量和错误
+func (c *Client) DeleteByPattern(ctx context.Context, pattern string, config *DeleteConfig) (int64, error) {
+	if config == nil {
+		config = DefaultDeleteConfig()
+	}
+	cfg := *config
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = DefaultDeleteConfig().BatchSize
+	}
+
+	var deleted int64
+	batch := make([]string, 0, cfg.BatchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		// 逐键UNLINK，避免集群模式下的跨槽错误
+		cmds, err := c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for _, key := range batch {
+				pipe.Unlink(ctx, key)
+			}
+			return nil
+		})
+		for _, cmd := range cmds {
+			if n, cmdErr := cmd.(*redis.IntCmd).Result(); cmdErr == nil {
+				deleted += n
+			}
+		}
+		batch = batch[:0]
+		return err
+	}
+
+	iter := c.Scan(ctx, pattern, int64(cfg.BatchSize), cfg.Type)
+	for iter.Next(ctx) {
+		batch = append(batch, c.prefix.key(iter.Key()))
+		if len(batch) < cfg.BatchSize {
+			continue
+		}
+		if err := flush(); err != nil {
+			return deleted, fmt.Errorf("failed to unlink keys: %w", err)
+		}
+		if cfg.BatchInterval > 0 {
+			timer := time.NewTimer(cfg.BatchInterval)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return deleted, ctx.Err()
+			case <-timer.C:
+			}
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return deleted, fmt.Errorf("failed to scan keys: %w", err)
+	}
+	if err := flush(); err != nil {
+		return deleted, fmt.Errorf("failed to unlink keys: %w", err)
+	}
+	return deleted, nil
+}
+
+// commandGuard 拦截未被允许的危险命令
+type commandGuard struct {
+	disabled map[string]bool
+}
+
+// newCommandGuard 创建命令守卫，allowed中的命令不会被拦截
+func newCommandGuard(allowed []string) *commandGuard {
+	g := &commandGuard{disabled: make(map[string]bool)}
+	for _, name := range dangerousCommands {
+		g.disabled[name] = true
+	}
+	for _, name := range allowed {
+		delete(g.disabled, strings.ToLower(name))
+	}
+	return g
+}
+
+// check 检查命令是否被允许
+func (g *commandGuard) check(name string) error {
+	if g != nil && g.disabled[strings.ToLower(name)] {
+		return fmt.Errorf("%w: %s", ErrCommandDisabled, strings.ToUpper(name))
+	}
+	return nil
+}
+
+// DialHook 实现 redis.Hook
+func (g *commandGuard) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+// ProcessHook 实现 redis.Hook
+func (g *commandGuard) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if err := g.check(cmd.Name()); err != nil {
+			cmd.SetErr(err)
+			return err
+		}
+		return next(ctx, cmd)
+	}
+}
+
+// ProcessPipelineHook 实现 redis.Hook，管道中包含被禁止的命令时整个管道都不会发送
+func (g *commandGuard) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		for _, cmd := range cmds {
+			if err := g.check(cmd.Name()); err != nil {
+				for _, c := range cmds {
+					c.SetErr(err)
+				}
+				return err
+			}
+		}
+		return next(ctx, cmds)
+	}
+}
@@ -0,0 +1,120 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"testing"
+)
+
+func TestScan(t *testing.T) {
+	client := newTestClient(t)
+	defer client.Close()
+
+	ctx := context.Background()
+	ns := client.WithNamespace("test:scan")
+	defer ns.DeleteByPattern(ctx, "*", nil)
+
+	for i := 0; i < 25; i++ {
+		ns.NewString().Set(ctx, fmt.Sprintf("str:%d", i), i, 0)
+	}
+	ns.NewList().RPush(ctx, "list:1", "a")
+	ns.NewHash().HSet(ctx, "hash:1", "f", "v")
+
+	seen := map[string]bool{}
+	iter := ns.Scan(ctx, "str:*", 5, "")
+	for iter.Next(ctx) {
+		seen[iter.Key()] = true
+	}
+	if err := iter.Err(); err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	if len(seen) != 25 || !seen["str:0"] || !seen["str:24"] {
+		t.Errorf("Expected 25 unprefixed keys, got %d: %v", len(seen), seen)
+	}
+
+	var keys []string
+	iter = ns.Scan(ctx, "", 0, "list")
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Key())
+	}
+	if iter.Err() != nil || len(keys) != 1 || keys[0] != "list:1" {
+		t.Errorf("Expected [list:1], got %v %v", keys, iter.Err())
+	}
+}
+
+func TestDeleteByPattern(t *testing.T) {
+	client := newTestClient(t)
+	defer client.Close()
+
+	ctx := context.Background()
+	ns := client.WithNamespace("test:delpattern")
+	defer ns.DeleteByPattern(ctx, "*", nil)
+
+	for i := 0; i < 30; i++ {
+		ns.NewString().Set(ctx, fmt.Sprintf("session:%d", i), "v", 0)
+	}
+	ns.NewString().Set(ctx, "config", "keep", 0)
+	ns.NewSet().SAdd(ctx, "session:set", "m")
+
+	deleted, err := ns.DeleteByPattern(ctx, "session:*", &DeleteConfig{BatchSize: 7, Type: "string"})
+	if err != nil {
+		t.Fatalf("DeleteByPattern failed: %v", err)
+	}
+	if deleted != 30 {
+		t.Errorf("Expected 30 deleted, got %d", deleted)
+	}
+
+	keys, _ := ns.Keys(ctx, "*")
+	sort.Strings(keys)
+	if len(keys) != 2 || keys[0] != "config" || keys[1] != "session:set" {
+		t.Errorf("Expected [config session:set] to remain, got %v", keys)
+	}
+}
+
+func TestCommandGuard(t *testing.T) {
+	config := DefaultConfig()
+	config.GuardDangerousCommands = true
+	config.AllowedCommands = []string{"flushall"}
+	client, err := New(config)
+	if err != nil {
+		t.Skipf("Skipping test: Redis not available: %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	if _, err := client.Keys(ctx, "*"); !errors.Is(err, ErrCommandDisabled) {
+		t.Errorf("Expected ErrCommandDisabled for Keys, got %v", err)
+	}
+	if err := client.FlushDB(ctx); !errors.Is(err, ErrCommandDisabled) {
+		t.Errorf("Expected ErrCommandDisabled for FlushDB, got %v", err)
+	}
+	if err := client.GetClient().FlushDB(ctx).Err(); !errors.Is(err, ErrCommandDisabled) {
+		t.Errorf("Expected raw FLUSHDB to be blocked, got %v", err)
+	}
+	if err := client.WithNamespace("tenant").FlushDB(ctx); !errors.Is(err, ErrCommandDisabled) {
+		t.Errorf("Expected namespace view to share the guard, got %v", err)
+	}
+
+	pipe := client.NewPipeline()
+	setCmd := pipe.Set(ctx, "test:guard", "v", 0)
+	pipe.Keys(ctx, "*")
+	if _, err := pipe.Exec(ctx); !errors.Is(err, ErrCommandDisabled) {
+		t.Errorf("Expected pipeline with KEYS to be blocked, got %v", err)
+	}
+	if !errors.Is(setCmd.Err(), ErrCommandDisabled) {
+		t.Errorf("Expected the whole pipeline to be rejected, got %v", setCmd.Err())
+	}
+
+	// 允许列表中的命令不受影响，SCAN始终可用
+	if err := newCommandGuard(config.AllowedCommands).check("FLUSHALL"); err != nil {
+		t.Errorf("Expected FLUSHALL to be allowed, got %v", err)
+	}
+	iter := client.Scan(ctx, "test:guard:*", 0, "")
+	for iter.Next(ctx) {
+	}
+	if err := iter.Err(); err != nil {
+		t.Errorf("Expected Scan to work with guard, got %v", err)
+	}
+}
@@ -3,15 +3,32 @@ package redis
 import (
 	"context"
 	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 )
 
+// maxScriptSamples 每个脚本最多保留的执行记录数，执行记录只用于计算百分位
+const maxScriptSamples = 10000
+
+// maxScriptBuckets 每个脚本最多保留的计数桶数，桶宽度至少为1秒
+const maxScriptBuckets = 3600
+
 // ScriptManager 脚本管理器
 type ScriptManager struct {
 	script      *Script
 	scripts     map[string]*ScriptInfo
 	stats       map[string]*ScriptStats
+	samples     map[string][]scriptSample // 最近的执行记录，用于计算时间窗口内的百分位
+	dropped     map[string]time.Time      // 因数量上限丢弃的最新一条执行记录的时间
+	buckets     map[string][]scriptBucket // 按时间分桶的执行计数，用于计算时间窗口内的次数和比率
+	retention   time.Duration             // 执行记录保留时间，取所有告警规则时间窗口的最大值
 	mutex       sync.RWMutex
 	monitor     *ScriptMonitor
 	fileWatcher *ScriptFileWatcher
@@ -35,24 +52,63 @@ type ScriptStats struct {
 	Reloads       int64         `json:"reloads"`        // 重新加载次数
 }
 
+// scriptSample 单次执行记录
+type scriptSample struct {
+	time     time.Time
+	duration time.Duration
+	failed   bool
+	cacheHit bool
+}
+
+// scriptBucket 一段时间内的执行计数，不受执行记录数量上限影响
+type scriptBucket struct {
+	start       time.Time
+	executions  int64
+	errors      int64
+	cacheHits   int64
+	cacheMisses int64
+	totalTime   time.Duration
+	maxTime     time.Duration
+}
+
 // ScriptMonitor 脚本监控器
 type ScriptMonitor struct {
 	manager    *ScriptManager
 	interval   time.Duration
 	stopChan   chan struct{}
 	alertRules []*AlertRule
+	notifiers  []AlertNotifier
+	logger     Logger
 	mutex      sync.RWMutex
 }
 
+// 告警条件，时间类条件的阈值单位为纳秒，可以写成 float64(100 * time.Millisecond)；
+// 除此之外还支持任意百分位延迟，例如 "p90"、"p99.9"
+const (
+	AlertErrorRate      = "error_rate"      // 错误率（百分比）
+	AlertSuccessRate    = "success_rate"    // 成功率（百分比），默认运算符为 "<"
+	AlertExecutionTime  = "execution_time"  // 平均执行时间
+	AlertMaxTime        = "max_time"        // 最大执行时间
+	AlertExecutionCount = "execution_count" // 执行次数
+	AlertCacheMissRate  = "cache_miss_rate" // 缓存未命中率（百分比）
+	AlertP95            = "p95"             // 95分位执行时间
+	AlertP99            = "p99"             // 99分位执行时间
+)
+
 // AlertRule 告警规则
 type AlertRule struct {
 	Name        string        `json:"name"`         // 规则名称
 	ScriptName  string        `json:"script_name"`  // 脚本名称
-	Condition   string        `json:"condition"`    // 条件表达式
+	Condition   string        `json:"condition"`    // 告警条件，见 AlertErrorRate 等常量
+	Operator    string        `json:"operator"`     // 比较运算符：>、>=、<、<=、==、!=，默认 >（success_rate 默认 <）
 	Threshold   float64       `json:"threshold"`    // 阈值
-	Duration    time.Duration `json:"duration"`     // 持续时间
+	Window      time.Duration `json:"window"`       // 统计最近多长时间内的执行，0表示全部历史（百分位使用保留的全部执行记录）；百分位最多基于最近10000次执行
+	Duration    time.Duration `json:"duration"`     // 告警持续期间重复通知的间隔，0表示只在触发和恢复时通知
 	Enabled     bool          `json:"enabled"`      // 是否启用
 	LastTrigger time.Time     `json:"last_trigger"` // 最后触发时间
+
+	firing   bool      // 当前是否处于告警状态
+	startsAt time.Time // 本次告警开始时间
 }
 
 // ScriptFileWatcher 脚本文件监控器
@@ -61,15 +117,57 @@ type ScriptFileWatcher struct {
 	watchPaths []string
 	interval   time.Duration
 	stopChan   chan struct{}
+	files      map[string]*scriptFile // 已处理的脚本文件，键为文件路径
+	handlers   []ScriptReloadHandler
 	mutex      sync.RWMutex
 }
 
+// ScriptReloadEventType 脚本文件事件类型
+type ScriptReloadEventType string
+
+const (
+	// ScriptFileLoaded 首次发现并加载脚本文件
+	ScriptFileLoaded ScriptReloadEventType = "loaded"
+	// ScriptFileReloaded 脚本内容变化后重新加载
+	ScriptFileReloaded ScriptReloadEventType = "reloaded"
+	// ScriptFileFailed 脚本加载失败，继续使用之前的版本
+	ScriptFileFailed ScriptReloadEventType = "failed"
+	// ScriptFileRemoved 脚本文件被删除，已加载的脚本仍然可用
+	ScriptFileRemoved ScriptReloadEventType = "removed"
+)
+
+// ScriptReloadEvent 脚本文件重新加载事件
+type ScriptReloadEvent struct {
+	Type   ScriptReloadEventType `json:"type"`    // 事件类型
+	Name   string                `json:"name"`    // 脚本名称
+	Path   string                `json:"path"`    // 文件路径
+	OldSHA string                `json:"old_sha"` // 之前的脚本SHA，首次加载时为空
+	NewSHA string                `json:"new_sha"` // 当前生效的脚本SHA
+	Error  error                 `json:"-"`       // 加载失败的原因
+	Time   time.Time             `json:"time"`    // 事件时间
+}
+
+// ScriptReloadHandler 脚本文件事件处理函数
+type ScriptReloadHandler func(event *ScriptReloadEvent)
+
+// scriptFile 脚本文件状态
+type scriptFile struct {
+	name    string
+	modTime time.Time
+	size    int64
+	hash    string
+}
+
 // NewScriptManager 创建脚本管理器
 func (s *Script) NewScriptManager() *ScriptManager {
 	manager := &ScriptManager{
-		script:  s,
-		scripts: make(map[string]*ScriptInfo),
-		stats:   make(map[string]*ScriptStats),
+		script:    s,
+		scripts:   make(map[string]*ScriptInfo),
+		stats:     make(map[string]*ScriptStats),
+		samples:   make(map[string][]scriptSample),
+		dropped:   make(map[string]time.Time),
+		buckets:   make(map[string][]scriptBucket),
+		retention: 5 * time.Minute,
 	}
 
 	// 创建监控器
@@ -77,6 +175,7 @@ func (s *Script) NewScriptManager() *ScriptManager {
 		manager:  manager,
 		interval: 30 * time.Second,
 		stopChan: make(chan struct{}),
+		logger:   &DefaultLogger{},
 	}
 
 	// 创建文件监控器
@@ -85,6 +184,7 @@ func (s *Script) NewScriptManager() *ScriptManager {
 		watchPaths: []string{},
 		interval:   10 * time.Second,
 		stopChan:   make(chan struct{}),
+		files:      make(map[string]*scriptFile),
 	}
 
 	return manager
@@ -173,6 +273,9 @@ func (sm *ScriptManager) ResetStats(name string) {
 		stats.CacheMisses = 0
 		stats.Reloads = 0
 	}
+	delete(sm.samples, name)
+	delete(sm.dropped, name)
+	delete(sm.buckets, name)
 }
 
 // StartMonitor 启动监控
@@ -185,7 +288,16 @@ func (sm *ScriptManager) StopMonitor() {
 	sm.monitor.stop()
 }
 
-// StartFileWatcher 启动文件监控
+// StartFileWatcher 启动文件监控，watchPaths可以是目录或.lua文件，目录会递归查找所有.lua文件。
+// 启动后立即加载一次，之后按间隔检查文件变化并重新加载。
+//
+// 脚本名称和元数据来自文件开头的注释，未指定名称时使用文件名：
+//
+//	-- @name: rate_limit
+//	-- @description: 固定窗口限流
+//	-- @keys: key
+//	-- @args: limit, window
+//	-- @timeout: 5s
 func (sm *ScriptManager) StartFileWatcher(watchPaths []string) {
 	sm.fileWatcher.setWatchPaths(watchPaths)
 	go sm.fileWatcher.start()
@@ -196,9 +308,58 @@ func (sm *ScriptManager) StopFileWatcher() {
 	sm.fileWatcher.stop()
 }
 
+// SetFileWatchInterval 设置文件检查间隔，需要在 StartFileWatcher 之前调用
+func (sm *ScriptManager) SetFileWatchInterval(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	sm.fileWatcher.mutex.Lock()
+	defer sm.fileWatcher.mutex.Unlock()
+	sm.fileWatcher.interval = interval
+}
+
+// OnScriptReload 注册脚本文件事件处理函数，处理函数在监控协程中同步调用
+func (sm *ScriptManager) OnScriptReload(handler ScriptReloadHandler) {
+	sm.fileWatcher.mutex.Lock()
+	defer sm.fileWatcher.mutex.Unlock()
+	sm.fileWatcher.handlers = append(sm.fileWatcher.handlers, handler)
+}
+
+// ReloadScriptFiles 立即检查监控路径下的脚本文件并重新加载变化的脚本，返回本次产生的事件
+func (sm *ScriptManager) ReloadScriptFiles(watchPaths ...string) []*ScriptReloadEvent {
+	if len(watchPaths) > 0 {
+		sm.fileWatcher.setWatchPaths(watchPaths)
+	}
+	return sm.fileWatcher.checkFiles()
+}
+
+// SetMonitorInterval 设置告警检查间隔，需要在 StartMonitor 之前调用
+func (sm *ScriptManager) SetMonitorInterval(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	sm.monitor.mutex.Lock()
+	defer sm.monitor.mutex.Unlock()
+	sm.monitor.interval = interval
+}
+
+// AddAlertNotifier 添加告警通知器，未添加任何通知器时告警写入默认日志
+func (sm *ScriptManager) AddAlertNotifier(notifier AlertNotifier) {
+	sm.monitor.mutex.Lock()
+	defer sm.monitor.mutex.Unlock()
+	sm.monitor.notifiers = append(sm.monitor.notifiers, notifier)
+}
+
 // AddAlertRule 添加告警规则
 func (sm *ScriptManager) AddAlertRule(rule *AlertRule) {
 	sm.monitor.addAlertRule(rule)
+
+	// 保留足够的执行记录以计算规则的时间窗口
+	sm.mutex.Lock()
+	if rule.Window > sm.retention {
+		sm.retention = rule.Window
+	}
+	sm.mutex.Unlock()
 }
 
 // RemoveAlertRule 移除告警规则
@@ -206,13 +367,47 @@ func (sm *ScriptManager) RemoveAlertRule(name string) {
 	sm.monitor.removeAlertRule(name)
 }
 
-// GetAlertRules 获取告警规则
+// GetAlertRules 获取告警规则的副本
 func (sm *ScriptManager) GetAlertRules() []*AlertRule {
 	return sm.monitor.getAlertRules()
 }
 
 // 私有方法
 
+// swapScript 先将脚本加载到Redis，成功后原子替换名称对应的SHA和源码；加载失败时保留之前的版本
+func (sm *ScriptManager) swapScript(ctx context.Context, info *ScriptInfo) (string, error) {
+	sha, err := sm.script.Load(ctx, info.Source)
+	if err != nil {
+		return "", err
+	}
+
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	now := time.Now()
+	info.SHA = sha
+	info.CreatedAt = now
+	info.UpdatedAt = now
+	if old, exists := sm.scripts[info.Name]; exists {
+		info.CreatedAt = old.CreatedAt
+	}
+	sm.scripts[info.Name] = info
+
+	oldSHA := sm.script.swap(info.Name, info.Source, sha)
+
+	stats, exists := sm.stats[info.Name]
+	if !exists {
+		sm.stats[info.Name] = &ScriptStats{
+			Name:          info.Name,
+			MinTime:       time.Hour,
+			LastExecution: now,
+		}
+	} else if oldSHA != sha {
+		stats.Reloads++
+	}
+	return oldSHA, nil
+}
+
 // updateStats 更新统计信息
 func (sm *ScriptManager) updateStats(name string, result *ScriptResult, err error, duration time.Duration) {
 	sm.mutex.Lock()
@@ -255,18 +450,137 @@ func (sm *ScriptManager) updateStats(name string, result *ScriptResult, err erro
 	}
 
 	// 更新缓存统计
-	if result != nil && result.SHA != "" {
+	cacheHit := result != nil && result.SHA != ""
+	if cacheHit {
 		stats.CacheHits++
 	} else {
 		stats.CacheMisses++
 	}
+
+	// 记录本次执行，丢弃超出保留时间和数量的记录
+	now := time.Now()
+	samples := append(sm.samples[name], scriptSample{time: now, duration: duration, failed: err != nil, cacheHit: cacheHit})
+	expired := 0
+	for expired < len(samples) && now.Sub(samples[expired].time) > sm.retention {
+		expired++
+	}
+	if len(samples)-expired > maxScriptSamples {
+		expired = len(samples) - maxScriptSamples
+		sm.dropped[name] = samples[expired-1].time
+	}
+	sm.samples[name] = samples[expired:]
+
+	sm.updateBucket(name, now, duration, err != nil, cacheHit)
+}
+
+// bucketWidth 返回计数桶的宽度，保证保留时间内的桶数不超过 maxScriptBuckets
+func (sm *ScriptManager) bucketWidth() time.Duration {
+	width := sm.retention / maxScriptBuckets
+	if width < time.Second {
+		width = time.Second
+	}
+	return width
+}
+
+// updateBucket 将一次执行计入当前的计数桶，调用方需持有锁
+func (sm *ScriptManager) updateBucket(name string, now time.Time, duration time.Duration, failed, cacheHit bool) {
+	width := sm.bucketWidth()
+	buckets := sm.buckets[name]
+	if len(buckets) == 0 || now.Sub(buckets[len(buckets)-1].start) >= width {
+		buckets = append(buckets, scriptBucket{start: now.Truncate(width)})
+	}
+	bucket := &buckets[len(buckets)-1]
+	bucket.executions++
+	bucket.totalTime += duration
+	if duration > bucket.maxTime {
+		bucket.maxTime = duration
+	}
+	if failed {
+		bucket.errors++
+	}
+	if cacheHit {
+		bucket.cacheHits++
+	} else {
+		bucket.cacheMisses++
+	}
+
+	expired := 0
+	for expired < len(buckets) && now.Sub(buckets[expired].start) > sm.retention+width {
+		expired++
+	}
+	sm.buckets[name] = buckets[expired:]
+}
+
+// scriptMetrics 一段时间内的执行指标
+type scriptMetrics struct {
+	executions  int64
+	errors      int64
+	cacheHits   int64
+	cacheMisses int64
+	totalTime   time.Duration
+	maxTime     time.Duration
+	durations   []time.Duration
+	truncated   bool // 执行记录因数量上限未能覆盖整个时间窗口，百分位不可信
+}
+
+// metrics 返回最近window内的执行指标，window为0时使用全部历史统计，百分位使用保留的全部执行记录。
+// 时间窗口内的次数和比率按计数桶统计，精度为一个桶的宽度
+func (sm *ScriptManager) metrics(name string, window time.Duration) (*scriptMetrics, bool) {
+	sm.mutex.RLock()
+	defer sm.mutex.RUnlock()
+
+	stats, exists := sm.stats[name]
+	if !exists {
+		return nil, false
+	}
+
+	m := &scriptMetrics{}
+	since := time.Now().Add(-window)
+	for _, sample := range sm.samples[name] {
+		if window > 0 && sample.time.Before(since) {
+			continue
+		}
+		m.durations = append(m.durations, sample.duration)
+	}
+
+	if window > 0 {
+		if dropped, ok := sm.dropped[name]; ok && !dropped.Before(since) {
+			m.truncated = true
+		}
+		width := sm.bucketWidth()
+		for _, bucket := range sm.buckets[name] {
+			if !bucket.start.Add(width).After(since) {
+				continue
+			}
+			m.executions += bucket.executions
+			m.errors += bucket.errors
+			m.cacheHits += bucket.cacheHits
+			m.cacheMisses += bucket.cacheMisses
+			m.totalTime += bucket.totalTime
+			if bucket.maxTime > m.maxTime {
+				m.maxTime = bucket.maxTime
+			}
+		}
+	} else {
+		m.executions = stats.Executions
+		m.errors = stats.Errors
+		m.cacheHits = stats.CacheHits
+		m.cacheMisses = stats.CacheMisses
+		m.totalTime = stats.TotalTime
+		m.maxTime = stats.MaxTime
+	}
+	return m, true
 }
 
 // ScriptMonitor 方法
 
 // start 启动监控
 func (sm *ScriptMonitor) start() {
-	ticker := time.NewTicker(sm.interval)
+	sm.mutex.RLock()
+	interval := sm.interval
+	sm.mutex.RUnlock()
+
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
 	for {
@@ -305,17 +619,20 @@ func (sm *ScriptMonitor) removeAlertRule(name string) {
 	}
 }
 
-// getAlertRules 获取告警规则
+// getAlertRules 获取告警规则的副本，告警状态由监控协程更新，不能直接共享规则
 func (sm *ScriptMonitor) getAlertRules() []*AlertRule {
 	sm.mutex.RLock()
 	defer sm.mutex.RUnlock()
 
 	rules := make([]*AlertRule, len(sm.alertRules))
-	copy(rules, sm.alertRules)
+	for i, rule := range sm.alertRules {
+		snapshot := *rule
+		rules[i] = &snapshot
+	}
 	return rules
 }
 
-// checkAlerts 检查告警
+// checkAlerts 检查告警，条件满足时发送告警，之后条件不再满足时发送恢复通知
 func (sm *ScriptMonitor) checkAlerts() {
 	sm.mutex.RLock()
 	rules := make([]*AlertRule, len(sm.alertRules))
@@ -323,57 +640,193 @@ func (sm *ScriptMonitor) checkAlerts() {
 	sm.mutex.RUnlock()
 
 	for _, rule := range rules {
-		if !rule.Enabled {
+		sm.mutex.RLock()
+		snapshot := *rule
+		sm.mutex.RUnlock()
+		if !snapshot.Enabled {
 			continue
 		}
 
-		stats, exists := sm.manager.GetScriptStats(rule.ScriptName)
+		metrics, exists := sm.manager.metrics(snapshot.ScriptName, snapshot.Window)
 		if !exists {
 			continue
 		}
+		if metrics.truncated && isPercentileCondition(snapshot.Condition) {
+			// 保留的执行记录不足以覆盖时间窗口，跳过本次检查并保持告警状态
+			sm.logger.Errorf("alert rule %s: window %s exceeds the latest %d executions of script %s, %s cannot be evaluated",
+				snapshot.Name, snapshot.Window, maxScriptSamples, snapshot.ScriptName, snapshot.Condition)
+			continue
+		}
 
 		// 检查告警条件
-		if sm.evaluateCondition(rule, stats) {
-			sm.triggerAlert(rule, stats)
+		value, ok := metricValue(snapshot.Condition, metrics)
+		firing := ok && compareThreshold(value, alertOperator(&snapshot), snapshot.Threshold)
+		if alert := sm.updateAlertState(rule, firing, value); alert != nil {
+			sm.notify(alert)
+		}
+	}
+}
+
+// updateAlertState 更新规则的告警状态，需要通知时返回告警内容
+func (sm *ScriptMonitor) updateAlertState(rule *AlertRule, firing bool, value float64) *Alert {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	now := time.Now()
+	var status AlertStatus
+	switch {
+	case firing && !rule.firing:
+		rule.firing = true
+		rule.startsAt = now
+		status = AlertFiring
+	case firing && rule.Duration > 0 && now.Sub(rule.LastTrigger) >= rule.Duration:
+		status = AlertFiring
+	case !firing && rule.firing:
+		rule.firing = false
+		status = AlertResolved
+	default:
+		return nil
+	}
+	if status == AlertFiring {
+		rule.LastTrigger = now
+	}
+
+	alert := &Alert{
+		Rule:       rule.Name,
+		ScriptName: rule.ScriptName,
+		Status:     status,
+		Condition:  rule.Condition,
+		Operator:   alertOperator(rule),
+		Threshold:  rule.Threshold,
+		Value:      value,
+		Window:     rule.Window,
+		StartsAt:   rule.startsAt,
+		Time:       now,
+	}
+	alert.Message = fmt.Sprintf("%s - Script: %s, Condition: %s %s %.2f, Value: %.2f",
+		rule.Name, rule.ScriptName, rule.Condition, alert.Operator, rule.Threshold, value)
+	return alert
+}
+
+// notify 发送告警或恢复通知
+func (sm *ScriptMonitor) notify(alert *Alert) {
+	sm.mutex.RLock()
+	notifiers := make([]AlertNotifier, len(sm.notifiers))
+	copy(notifiers, sm.notifiers)
+	sm.mutex.RUnlock()
+	if len(notifiers) == 0 {
+		notifiers = append(notifiers, NewLoggerNotifier(sm.logger))
+	}
+
+	for _, notifier := range notifiers {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		if err := notifier.Notify(ctx, alert); err != nil {
+			sm.logger.Errorf("failed to send alert %s: %v", alert.Rule, err)
 		}
+		cancel()
 	}
 }
 
-// evaluateCondition 评估告警条件
-func (sm *ScriptMonitor) evaluateCondition(rule *AlertRule, stats *ScriptStats) bool {
-	switch rule.Condition {
-	case "error_rate":
-		return stats.SuccessRate < rule.Threshold
-	case "execution_time":
-		return float64(stats.AverageTime) > rule.Threshold
-	case "execution_count":
-		return float64(stats.Executions) > rule.Threshold
-	case "cache_miss_rate":
-		total := stats.CacheHits + stats.CacheMisses
+// alertOperator 返回规则的比较运算符，未设置时成功率使用 <，其余使用 >
+func alertOperator(rule *AlertRule) string {
+	if rule.Operator != "" {
+		return rule.Operator
+	}
+	if rule.Condition == AlertSuccessRate {
+		return "<"
+	}
+	return ">"
+}
+
+// metricValue 计算告警条件对应的指标值，没有执行记录时比率和延迟无法计算
+func metricValue(condition string, m *scriptMetrics) (float64, bool) {
+	switch condition {
+	case AlertExecutionCount:
+		return float64(m.executions), true
+	case AlertErrorRate:
+		if m.executions == 0 {
+			return 0, false
+		}
+		return float64(m.errors) / float64(m.executions) * 100, true
+	case AlertSuccessRate:
+		if m.executions == 0 {
+			return 0, false
+		}
+		return float64(m.executions-m.errors) / float64(m.executions) * 100, true
+	case AlertExecutionTime:
+		if m.executions == 0 {
+			return 0, false
+		}
+		return float64(m.totalTime / time.Duration(m.executions)), true
+	case AlertMaxTime:
+		if m.executions == 0 {
+			return 0, false
+		}
+		return float64(m.maxTime), true
+	case AlertCacheMissRate:
+		total := m.cacheHits + m.cacheMisses
 		if total == 0 {
-			return false
+			return 0, false
 		}
-		missRate := float64(stats.CacheMisses) / float64(total) * 100
-		return missRate > rule.Threshold
-	default:
-		return false
+		return float64(m.cacheMisses) / float64(total) * 100, true
+	}
+
+	p, ok := percentileCondition(condition)
+	if !ok || len(m.durations) == 0 {
+		return 0, false
 	}
+	return float64(percentile(m.durations, p)), true
 }
 
-// triggerAlert 触发告警
-func (sm *ScriptMonitor) triggerAlert(rule *AlertRule, stats *ScriptStats) {
-	// 检查是否在冷却期内
-	if time.Since(rule.LastTrigger) < rule.Duration {
-		return
+// percentileCondition 解析 "p95"、"p99.9" 形式的百分位条件
+func percentileCondition(condition string) (float64, bool) {
+	if !strings.HasPrefix(condition, "p") {
+		return 0, false
+	}
+	p, err := strconv.ParseFloat(condition[1:], 64)
+	if err != nil || p <= 0 || p > 100 {
+		return 0, false
 	}
+	return p, true
+}
+
+// isPercentileCondition 判断是否为百分位条件
+func isPercentileCondition(condition string) bool {
+	_, ok := percentileCondition(condition)
+	return ok
+}
+
+// percentile 按最近秩法计算百分位
+func percentile(durations []time.Duration, p float64) time.Duration {
+	sorted := make([]time.Duration, len(durations))
+	copy(sorted, durations)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
 
-	// 触发告警
-	rule.LastTrigger = time.Now()
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
 
-	// 这里可以实现告警通知逻辑
-	// 例如：发送邮件、短信、Slack通知等
-	fmt.Printf("ALERT: %s - Script: %s, Condition: %s, Threshold: %.2f\n",
-		rule.Name, rule.ScriptName, rule.Condition, rule.Threshold)
+// compareThreshold 按运算符比较指标值和阈值，未知运算符视为不满足
+func compareThreshold(value float64, operator string, threshold float64) bool {
+	switch operator {
+	case ">":
+		return value > threshold
+	case ">=":
+		return value >= threshold
+	case "<":
+		return value < threshold
+	case "<=":
+		return value <= threshold
+	case "==":
+		return value == threshold
+	case "!=":
+		return value != threshold
+	default:
+		return false
+	}
 }
 
 // ScriptFileWatcher 方法
@@ -387,7 +840,13 @@ func (sfw *ScriptFileWatcher) setWatchPaths(paths []string) {
 
 // start 启动文件监控
 func (sfw *ScriptFileWatcher) start() {
-	ticker := time.NewTicker(sfw.interval)
+	sfw.checkFiles()
+
+	sfw.mutex.RLock()
+	interval := sfw.interval
+	sfw.mutex.RUnlock()
+
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
 	for {
@@ -405,18 +864,197 @@ func (sfw *ScriptFileWatcher) stop() {
 	close(sfw.stopChan)
 }
 
-// checkFiles 检查文件变化
-func (sfw *ScriptFileWatcher) checkFiles() {
+// checkFiles 检查文件变化，加载新增和修改的脚本
+func (sfw *ScriptFileWatcher) checkFiles() []*ScriptReloadEvent {
 	sfw.mutex.RLock()
 	paths := make([]string, len(sfw.watchPaths))
 	copy(paths, sfw.watchPaths)
 	sfw.mutex.RUnlock()
 
-	// 这里可以实现文件变化检测逻辑
-	// 例如：检查文件修改时间、内容哈希等
-	// 当检测到变化时，重新加载脚本
-	for _, path := range paths {
-		// 实现文件监控逻辑
-		_ = path
+	var events []*ScriptReloadEvent
+	found := make(map[string]bool)
+	for _, root := range paths {
+		for _, path := range findScriptFiles(root) {
+			if found[path] {
+				continue
+			}
+			found[path] = true
+			if event := sfw.checkFile(path); event != nil {
+				events = append(events, event)
+			}
+		}
+	}
+
+	sfw.mutex.Lock()
+	for path, file := range sfw.files {
+		if !found[path] {
+			delete(sfw.files, path)
+			events = append(events, &ScriptReloadEvent{
+				Type:   ScriptFileRemoved,
+				Name:   file.name,
+				Path:   path,
+				OldSHA: file.hash,
+				Time:   time.Now(),
+			})
+		}
+	}
+	handlers := make([]ScriptReloadHandler, len(sfw.handlers))
+	copy(handlers, sfw.handlers)
+	sfw.mutex.Unlock()
+
+	for _, event := range events {
+		for _, handler := range handlers {
+			handler(event)
+		}
+	}
+	return events
+}
+
+// checkFile 检查单个文件，修改时间和大小都未变化时跳过，内容哈希未变化时只更新文件状态
+func (sfw *ScriptFileWatcher) checkFile(path string) *ScriptReloadEvent {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return nil
+	}
+
+	sfw.mutex.RLock()
+	previous := sfw.files[path]
+	sfw.mutex.RUnlock()
+
+	if previous != nil && previous.modTime.Equal(stat.ModTime()) && previous.size == stat.Size() {
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	source := string(data)
+	hash := sfw.manager.script.calculateSHA(source)
+	file := &scriptFile{modTime: stat.ModTime(), size: stat.Size(), hash: hash}
+
+	if previous != nil && previous.hash == hash {
+		file.name = previous.name
+		sfw.mutex.Lock()
+		sfw.files[path] = file
+		sfw.mutex.Unlock()
+		return nil
+	}
+
+	info := parseScriptFile(path, source)
+	file.name = info.Name
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	oldSHA, err := sfw.manager.swapScript(ctx, info)
+
+	event := &ScriptReloadEvent{
+		Type:   ScriptFileLoaded,
+		Name:   info.Name,
+		Path:   path,
+		OldSHA: oldSHA,
+		NewSHA: hash,
+		Time:   time.Now(),
+	}
+	if previous != nil {
+		event.Type = ScriptFileReloaded
+	}
+	if err != nil {
+		event.Type = ScriptFileFailed
+		event.Error = err
+		event.OldSHA = sfw.manager.script.GetCache()[info.Name]
+		event.NewSHA = event.OldSHA
+		// 网络错误在下次检查时重试，脚本编译错误等到文件再次修改后才重新加载
+		if isRetryableError(err) {
+			return event
+		}
+	}
+
+	sfw.mutex.Lock()
+	sfw.files[path] = file
+	sfw.mutex.Unlock()
+	return event
+}
+
+// findScriptFiles 返回路径下的所有.lua文件，路径本身是文件时直接返回
+func findScriptFiles(root string) []string {
+	stat, err := os.Stat(root)
+	if err != nil {
+		return nil
+	}
+	if !stat.IsDir() {
+		if filepath.Ext(root) == ".lua" {
+			return []string{root}
+		}
+		return nil
+	}
+
+	var files []string
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if !d.IsDir() && filepath.Ext(path) == ".lua" {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files
+}
+
+// parseScriptFile 从文件开头的注释中解析脚本元数据，格式为 "-- @key: value"，遇到第一行代码时结束
+func parseScriptFile(path, source string) *ScriptInfo {
+	info := &ScriptInfo{
+		Name:   strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)),
+		Source: source,
+		Keys:   []string{},
+		Args:   []string{},
+	}
+
+	for _, line := range strings.Split(source, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, "--") {
+			break
+		}
+		line = strings.TrimSpace(strings.TrimPrefix(line, "--"))
+		if !strings.HasPrefix(line, "@") {
+			continue
+		}
+		key, value, ok := strings.Cut(line[1:], ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(key) {
+		case "name":
+			if value != "" {
+				info.Name = value
+			}
+		case "description":
+			info.Description = value
+		case "keys":
+			info.Keys = splitScriptList(value)
+		case "args":
+			info.Args = splitScriptList(value)
+		case "timeout":
+			if timeout, err := time.ParseDuration(value); err == nil {
+				info.Timeout = timeout
+			}
+		}
+	}
+	return info
+}
+
+// splitScriptList 拆分逗号分隔的列表
+func splitScriptList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
 	}
+	return items
 }
@@ -0,0 +1,466 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestParseScriptFile(t *testing.T) {
+	source := `-- @name: rate_limit
+-- @description: 固定窗口限流
+-- 普通注释
+-- @keys: key
+-- @args: limit, window
+-- @timeout: 2s
+local current = redis.call('INCR', KEYS[1])
+-- @name: ignored
+return current`
+
+	info := parseScriptFile("/scripts/limit.lua", source)
+	if info.Name != "rate_limit" {
+		t.Errorf("Expected name rate_limit, got %s", info.Name)
+	}
+	if info.Description != "固定窗口限流" {
+		t.Errorf("Unexpected description %q", info.Description)
+	}
+	if len(info.Keys) != 1 || info.Keys[0] != "key" {
+		t.Errorf("Unexpected keys %v", info.Keys)
+	}
+	if len(info.Args) != 2 || info.Args[0] != "limit" || info.Args[1] != "window" {
+		t.Errorf("Unexpected args %v", info.Args)
+	}
+	if info.Timeout != 2*time.Second {
+		t.Errorf("Expected timeout 2s, got %v", info.Timeout)
+	}
+	if info.Source != source {
+		t.Error("Expected source to be kept")
+	}
+
+	// 未指定名称时使用文件名
+	info = parseScriptFile("/scripts/hello.lua", "return 1")
+	if info.Name != "hello" {
+		t.Errorf("Expected name hello, got %s", info.Name)
+	}
+}
+
+func TestScriptFileReload(t *testing.T) {
+	client := newTestClient(t)
+	defer client.Close()
+
+	ctx := context.Background()
+	manager := client.NewScript().NewScriptManager()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "greet.lua")
+	writeScript := func(source string) {
+		if err := os.WriteFile(path, []byte(source), 0o644); err != nil {
+			t.Fatalf("WriteFile failed: %v", err)
+		}
+	}
+	execute := func() interface{} {
+		opts := DefaultScriptOptions()
+		opts.Args = []interface{}{"world"}
+		result, err := manager.ExecuteScript(ctx, "test_greet", opts)
+		if err != nil || result.Error != nil {
+			t.Fatalf("Execute failed: %v %v", err, result.Error)
+		}
+		return result.Value
+	}
+
+	writeScript("-- @name: test_greet\nreturn 'hello ' .. ARGV[1]")
+	events := manager.ReloadScriptFiles(dir)
+	if len(events) != 1 || events[0].Type != ScriptFileLoaded || events[0].Name != "test_greet" {
+		t.Fatalf("Expected loaded event, got %+v", events)
+	}
+	if value := execute(); value != "hello world" {
+		t.Errorf("Expected 'hello world', got %v", value)
+	}
+
+	// 文件未变化时不产生事件
+	if events := manager.ReloadScriptFiles(); len(events) != 0 {
+		t.Errorf("Expected no events, got %+v", events)
+	}
+
+	// 修改内容后重新加载
+	writeScript("-- @name: test_greet\nreturn 'hi there ' .. ARGV[1]")
+	events = manager.ReloadScriptFiles()
+	if len(events) != 1 || events[0].Type != ScriptFileReloaded {
+		t.Fatalf("Expected reloaded event, got %+v", events)
+	}
+	if events[0].OldSHA == "" || events[0].OldSHA == events[0].NewSHA {
+		t.Errorf("Expected SHA to change, got %s -> %s", events[0].OldSHA, events[0].NewSHA)
+	}
+	if value := execute(); value != "hi there world" {
+		t.Errorf("Expected 'hi there world', got %v", value)
+	}
+	if stats, _ := manager.GetScriptStats("test_greet"); stats.Reloads != 1 {
+		t.Errorf("Expected 1 reload, got %d", stats.Reloads)
+	}
+
+	// 编译失败时保留之前的版本
+	writeScript("-- @name: test_greet\nreturn 'broken' ..")
+	events = manager.ReloadScriptFiles()
+	if len(events) != 1 || events[0].Type != ScriptFileFailed || events[0].Error == nil {
+		t.Fatalf("Expected failed event, got %+v", events)
+	}
+	if value := execute(); value != "hi there world" {
+		t.Errorf("Expected previous version to be kept, got %v", value)
+	}
+	// 失败的内容不会重复加载
+	if events := manager.ReloadScriptFiles(); len(events) != 0 {
+		t.Errorf("Expected no events for unchanged broken file, got %+v", events)
+	}
+
+	// 删除文件后脚本仍然可用
+	os.Remove(path)
+	events = manager.ReloadScriptFiles()
+	if len(events) != 1 || events[0].Type != ScriptFileRemoved {
+		t.Fatalf("Expected removed event, got %+v", events)
+	}
+	if value := execute(); value != "hi there world" {
+		t.Errorf("Expected script to stay loaded, got %v", value)
+	}
+}
+
+func TestScriptFileWatcher(t *testing.T) {
+	client := newTestClient(t)
+	defer client.Close()
+
+	manager := client.NewScript().NewScriptManager()
+	manager.SetFileWatchInterval(20 * time.Millisecond)
+
+	var mutex sync.Mutex
+	var events []*ScriptReloadEvent
+	manager.OnScriptReload(func(event *ScriptReloadEvent) {
+		mutex.Lock()
+		events = append(events, event)
+		mutex.Unlock()
+	})
+	countEvents := func(eventType ScriptReloadEventType) int {
+		mutex.Lock()
+		defer mutex.Unlock()
+		count := 0
+		for _, event := range events {
+			if event.Type == eventType {
+				count++
+			}
+		}
+		return count
+	}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test_watch.lua")
+	if err := os.WriteFile(path, []byte("return 1"), 0o644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	manager.StartFileWatcher([]string{dir})
+	defer manager.StopFileWatcher()
+
+	waitFor(t, func() bool { return countEvents(ScriptFileLoaded) == 1 })
+	if _, exists := manager.GetScriptInfo("test_watch"); !exists {
+		t.Fatal("Expected script to be registered from file")
+	}
+
+	if err := os.WriteFile(path, []byte("return 22"), 0o644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	waitFor(t, func() bool { return countEvents(ScriptFileReloaded) == 1 })
+
+	result, err := manager.ExecuteScript(context.Background(), "test_watch", nil)
+	if err != nil || result.Error != nil {
+		t.Fatalf("Execute failed: %v %v", err, result.Error)
+	}
+	if result.Value != int64(22) {
+		t.Errorf("Expected 22, got %v", result.Value)
+	}
+}
+
+func newTestScriptManager() *ScriptManager {
+	return (&Script{cache: make(map[string]string), sources: make(map[string]string)}).NewScriptManager()
+}
+
+func TestScriptAlertFiringAndRecovery(t *testing.T) {
+	manager := newTestScriptManager()
+	notifier := NewChannelNotifier(10)
+	manager.AddAlertNotifier(notifier)
+	manager.AddAlertRule(&AlertRule{
+		Name:       "high_error_rate",
+		ScriptName: "alert_script",
+		Condition:  AlertErrorRate,
+		Threshold:  20,
+		Enabled:    true,
+	})
+
+	manager.updateStats("alert_script", nil, errors.New("failed"), time.Millisecond)
+	manager.updateStats("alert_script", &ScriptResult{SHA: "sha"}, nil, time.Millisecond)
+	manager.monitor.checkAlerts()
+
+	select {
+	case alert := <-notifier.C():
+		if alert.Status != AlertFiring || alert.Value != 50 || alert.Operator != ">" {
+			t.Errorf("Unexpected alert %+v", alert)
+		}
+	default:
+		t.Fatal("Expected firing alert")
+	}
+
+	// 告警持续期间不重复通知
+	manager.monitor.checkAlerts()
+	if len(notifier.C()) != 0 {
+		t.Errorf("Expected no repeated alert, got %d", len(notifier.C()))
+	}
+
+	// 错误率降到阈值以下时发送恢复通知
+	for i := 0; i < 8; i++ {
+		manager.updateStats("alert_script", &ScriptResult{SHA: "sha"}, nil, time.Millisecond)
+	}
+	manager.monitor.checkAlerts()
+	select {
+	case alert := <-notifier.C():
+		if alert.Status != AlertResolved || alert.Value != 10 || alert.StartsAt.IsZero() {
+			t.Errorf("Unexpected alert %+v", alert)
+		}
+	default:
+		t.Fatal("Expected resolved alert")
+	}
+}
+
+func TestScriptAlertWindowPercentile(t *testing.T) {
+	manager := newTestScriptManager()
+	notifier := NewChannelNotifier(10)
+	manager.AddAlertNotifier(notifier)
+	manager.AddAlertRule(&AlertRule{
+		Name:       "slow_p95",
+		ScriptName: "slow_script",
+		Condition:  AlertP95,
+		Operator:   ">=",
+		Threshold:  float64(95 * time.Millisecond),
+		Window:     time.Minute,
+		Enabled:    true,
+	})
+
+	for i := 1; i <= 100; i++ {
+		manager.updateStats("slow_script", nil, nil, time.Duration(i)*time.Millisecond)
+	}
+	manager.monitor.checkAlerts()
+	select {
+	case alert := <-notifier.C():
+		if alert.Value != float64(95*time.Millisecond) {
+			t.Errorf("Expected p95 95ms, got %v", time.Duration(alert.Value))
+		}
+	default:
+		t.Fatal("Expected firing alert")
+	}
+
+	// 窗口之外的慢执行不参与计算
+	manager.mutex.Lock()
+	samples := manager.samples["slow_script"]
+	for i := range samples {
+		samples[i].time = samples[i].time.Add(-2 * time.Minute)
+	}
+	manager.mutex.Unlock()
+	for i := 0; i < 10; i++ {
+		manager.updateStats("slow_script", nil, nil, time.Millisecond)
+	}
+	manager.monitor.checkAlerts()
+	select {
+	case alert := <-notifier.C():
+		if alert.Status != AlertResolved || alert.Value != float64(time.Millisecond) {
+			t.Errorf("Unexpected alert %+v", alert)
+		}
+	default:
+		t.Fatal("Expected resolved alert")
+	}
+}
+
+// errorLogger 记录错误日志的测试日志器
+type errorLogger struct {
+	DefaultLogger
+	mu     sync.Mutex
+	errors []string
+}
+
+func (l *errorLogger) Errorf(format string, args ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.errors = append(l.errors, fmt.Sprintf(format, args...))
+}
+
+func TestScriptAlertWindowBeyondSampleLimit(t *testing.T) {
+	manager := newTestScriptManager()
+	logger := &errorLogger{}
+	manager.monitor.logger = logger
+	notifier := NewChannelNotifier(10)
+	manager.AddAlertNotifier(notifier)
+	manager.AddAlertRule(&AlertRule{
+		Name:       "busy",
+		ScriptName: "busy_script",
+		Condition:  AlertExecutionCount,
+		Threshold:  maxScriptSamples,
+		Window:     time.Minute,
+		Enabled:    true,
+	})
+	manager.AddAlertRule(&AlertRule{
+		Name:       "busy_p99",
+		ScriptName: "busy_script",
+		Condition:  AlertP99,
+		Threshold:  0,
+		Window:     time.Minute,
+		Enabled:    true,
+	})
+
+	// 执行次数超过执行记录上限时，窗口内的计数仍然准确
+	executions := maxScriptSamples + 50
+	for i := 0; i < executions; i++ {
+		var err error
+		if i < 100 {
+			err = errors.New("failed")
+		}
+		manager.updateStats("busy_script", nil, err, time.Millisecond)
+	}
+	metrics, _ := manager.metrics("busy_script", time.Minute)
+	if metrics.executions != int64(executions) || metrics.errors != 100 {
+		t.Errorf("Expected %d executions and 100 errors, got %d and %d", executions, metrics.executions, metrics.errors)
+	}
+	if !metrics.truncated {
+		t.Error("Expected samples not to cover the window")
+	}
+
+	manager.monitor.checkAlerts()
+	select {
+	case alert := <-notifier.C():
+		if alert.Rule != "busy" || alert.Value != float64(executions) {
+			t.Errorf("Unexpected alert %+v", alert)
+		}
+	default:
+		t.Fatal("Expected execution count alert")
+	}
+
+	// 百分位无法覆盖窗口时不发送通知，而是记录错误
+	if len(notifier.C()) != 0 {
+		t.Errorf("Expected no percentile alert, got %+v", <-notifier.C())
+	}
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	if len(logger.errors) != 1 || !strings.Contains(logger.errors[0], "busy_p99") {
+		t.Errorf("Expected window coverage error, got %v", logger.errors)
+	}
+}
+
+func TestScriptAlertRulesConcurrentAccess(t *testing.T) {
+	manager := newTestScriptManager()
+	manager.AddAlertNotifier(NewChannelNotifier(100))
+	manager.AddAlertRule(&AlertRule{
+		Name:       "always",
+		ScriptName: "race_script",
+		Condition:  AlertExecutionCount,
+		Threshold:  0,
+		Duration:   time.Nanosecond,
+		Enabled:    true,
+	})
+	manager.updateStats("race_script", nil, nil, time.Millisecond)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 50; i++ {
+			manager.monitor.checkAlerts()
+		}
+	}()
+	for i := 0; i < 50; i++ {
+		for _, rule := range manager.GetAlertRules() {
+			_ = rule.LastTrigger
+		}
+	}
+	<-done
+
+	rules := manager.GetAlertRules()
+	if len(rules) != 1 || rules[0].LastTrigger.IsZero() {
+		t.Errorf("Expected triggered rule, got %+v", rules)
+	}
+}
+
+func TestMetricValue(t *testing.T) {
+	m := &scriptMetrics{executions: 4, errors: 1, cacheHits: 3, cacheMisses: 1, totalTime: 8 * time.Millisecond}
+	tests := []struct {
+		condition string
+		want      float64
+	}{
+		{AlertErrorRate, 25},
+		{AlertSuccessRate, 75},
+		{AlertExecutionCount, 4},
+		{AlertCacheMissRate, 25},
+		{AlertExecutionTime, float64(2 * time.Millisecond)},
+	}
+	for _, tt := range tests {
+		value, ok := metricValue(tt.condition, m)
+		if !ok || value != tt.want {
+			t.Errorf("%s: expected %v, got %v (ok=%v)", tt.condition, tt.want, value, ok)
+		}
+	}
+
+	if _, ok := metricValue(AlertErrorRate, &scriptMetrics{}); ok {
+		t.Error("Expected error rate to be undefined without executions")
+	}
+	if _, ok := metricValue("unknown", m); ok {
+		t.Error("Expected unknown condition to be undefined")
+	}
+
+	m.durations = []time.Duration{4 * time.Millisecond, 1 * time.Millisecond, 3 * time.Millisecond, 2 * time.Millisecond}
+	if value, ok := metricValue("p50", m); !ok || value != float64(2*time.Millisecond) {
+		t.Errorf("Expected p50 2ms, got %v", time.Duration(value))
+	}
+	if value, ok := metricValue("p99.9", m); !ok || value != float64(4*time.Millisecond) {
+		t.Errorf("Expected p99.9 4ms, got %v", time.Duration(value))
+	}
+
+	if alertOperator(&AlertRule{Condition: AlertSuccessRate}) != "<" {
+		t.Error("Expected success rate to default to <")
+	}
+	if compareThreshold(1, "=>", 0) {
+		t.Error("Expected unknown operator not to match")
+	}
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	received := make(chan *Alert, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var alert Alert
+		if err := json.NewDecoder(r.Body).Decode(&alert); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		received <- &alert
+	}))
+	defer server.Close()
+
+	notifier := NewWebhookNotifier(server.URL)
+	alert := &Alert{Rule: "rule", ScriptName: "script", Status: AlertFiring, Value: 42}
+	if err := notifier.Notify(context.Background(), alert); err == nil {
+		t.Error("Expected error for unauthorized webhook")
+	}
+
+	notifier.Headers["Authorization"] = "Bearer token"
+	if err := notifier.Notify(context.Background(), alert); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+	got := <-received
+	if got.Rule != "rule" || got.Status != AlertFiring || got.Value != 42 {
+		t.Errorf("Unexpected alert %+v", got)
+	}
+}
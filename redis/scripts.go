@@ -90,30 +90,15 @@ end`,
 	}
 }
 
-// GetRateLimitScript 限流脚本
+// GetRateLimitScript 固定窗口限流脚本，与 RateLimiter 的 FixedWindow 算法共用同一份源码。
+// 返回 {是否允许, 剩余配额, 重置时间(微秒), 重试时间(微秒)}
 func (st *ScriptTemplates) GetRateLimitScript() *ScriptInfo {
 	return &ScriptInfo{
-		Name: "rate_limit",
-		Source: `local key = KEYS[1]
-local limit = tonumber(ARGV[1])
-local window = tonumber(ARGV[2])
-
-local current = redis.call('GET', key)
-if current == false then
-    redis.call('SET', key, 1, 'EX', window)
-    return {1, limit - 1}
-end
-
-current = tonumber(current)
-if current < limit then
-    redis.call('INCR', key)
-    return {1, limit - current - 1}
-else
-    return {0, 0}
-end`,
+		Name:        "rate_limit",
+		Source:      fixedWindowSource,
 		Keys:        []string{"rate_limit_key"},
-		Args:        []string{"limit", "window_seconds"},
-		Description: "限流脚本",
+		Args:        []string{"limit", "window_seconds", "n"},
+		Description: "固定窗口限流脚本",
 		Timeout:     5 * time.Second,
 	}
 }
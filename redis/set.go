@@ -8,72 +8,73 @@ import (
 
 // Set 集合操作
 type Set struct {
-	client *redis.Client
+	client redis.UniversalClient
+	prefix keyPrefix
 }
 
 // NewSet 创建集合操作实例
 func (c *Client) NewSet() *Set {
-	return &Set{client: c.client}
+	return &Set{client: c.client, prefix: c.prefix}
 }
 
 // SAdd 添加元素到集合
 func (s *Set) SAdd(ctx context.Context, key string, members ...interface{}) (int64, error) {
-	return s.client.SAdd(ctx, key, members...).Result()
+	return s.client.SAdd(ctx, s.prefix.key(key), members...).Result()
 }
 
 // SRem 从集合中移除元素
 func (s *Set) SRem(ctx context.Context, key string, members ...interface{}) (int64, error) {
-	return s.client.SRem(ctx, key, members...).Result()
+	return s.client.SRem(ctx, s.prefix.key(key), members...).Result()
 }
 
 // SPop 随机弹出元素
 func (s *Set) SPop(ctx context.Context, key string) (string, error) {
-	return s.client.SPop(ctx, key).Result()
+	return s.client.SPop(ctx, s.prefix.key(key)).Result()
 }
 
 // SPopN 随机弹出多个元素
 func (s *Set) SPopN(ctx context.Context, key string, count int64) ([]string, error) {
-	return s.client.SPopN(ctx, key, count).Result()
+	return s.client.SPopN(ctx, s.prefix.key(key), count).Result()
 }
 
 // SRandMember 随机获取元素
 func (s *Set) SRandMember(ctx context.Context, key string) (string, error) {
-	return s.client.SRandMember(ctx, key).Result()
+	return s.client.SRandMember(ctx, s.prefix.key(key)).Result()
 }
 
 // SRandMemberN 随机获取多个元素
 func (s *Set) SRandMemberN(ctx context.Context, key string, count int64) ([]string, error) {
-	return s.client.SRandMemberN(ctx, key, count).Result()
+	return s.client.SRandMemberN(ctx, s.prefix.key(key), count).Result()
 }
 
 // SMove 移动元素到另一个集合
 func (s *Set) SMove(ctx context.Context, source, destination string, member interface{}) (bool, error) {
-	return s.client.SMove(ctx, source, destination, member).Result()
+	return s.client.SMove(ctx, s.prefix.key(source), s.prefix.key(destination), member).Result()
 }
 
 // SCard 获取集合大小
 func (s *Set) SCard(ctx context.Context, key string) (int64, error) {
-	return s.client.SCard(ctx, key).Result()
+	return s.client.SCard(ctx, s.prefix.key(key)).Result()
 }
 
 // SIsMember 检查元素是否在集合中
 func (s *Set) SIsMember(ctx context.Context, key string, member interface{}) (bool, error) {
-	return s.client.SIsMember(ctx, key, member).Result()
+	return s.client.SIsMember(ctx, s.prefix.key(key), member).Result()
 }
 
 // SMIsMember 批量检查元素是否在集合中
 func (s *Set) SMIsMember(ctx context.Context, key string, members ...interface{}) ([]bool, error) {
-	return s.client.SMIsMember(ctx, key, members...).Result()
+	return s.client.SMIsMember(ctx, s.prefix.key(key), members...).Result()
 }
 
 // SMembers 获取所有成员
 func (s *Set) SMembers(ctx context.Context, key string) ([]string, error) {
-	return s.client.SMembers(ctx, key).Result()
+	return s.client.SMembers(ctx, s.prefix.key(key)).Result()
 }
 
 // SScan 扫描集合成员
 func (s *Set) SScan(ctx context.Context, key string, cursor uint64, match string, count int64) ([]string, uint64, error) {
-	return s.client.SScan(ctx, key, cursor, match, count).Result()
+	return s.client.SScan(ctx, s.prefix.key(key), cursor, match, count).Result()
 }
 
 // SScanAll 扫描所有集合成员
@@ -99,35 +100,35 @@ func (s *Set) SScanAll(ctx context.Context, key string, match string, count int6
 
 // SUnion 并集
 func (s *Set) SUnion(ctx context.Context, keys ...string) ([]string, error) {
-	return s.client.SUnion(ctx, keys...).Result()
+	return s.client.SUnion(ctx, s.prefix.keys(keys)...).Result()
 }
 
 // SUnionStore 并集并存储
 func (s *Set) SUnionStore(ctx context.Context, destination string, keys ...string) (int64, error) {
-	return s.client.SUnionStore(ctx, destination, keys...).Result()
+	return s.client.SUnionStore(ctx, s.prefix.key(destination), s.prefix.keys(keys)...).Result()
 }
 
 // SInter 交集
 func (s *Set) SInter(ctx context.Context, keys ...string) ([]string, error) {
-	return s.client.SInter(ctx, keys...).Result()
+	return s.client.SInter(ctx, s.prefix.keys(keys)...).Result()
 }
 
 // SInterStore 交集并存储
 func (s *Set) SInterStore(ctx context.Context, destination string, keys ...string) (int64, error) {
-	return s.client.SInterStore(ctx, destination, keys...).Result()
+	return s.client.SInterStore(ctx, s.prefix.key(destination), s.prefix.keys(keys)...).Result()
 }
 
 // SDiff 差集
 func (s *Set) SDiff(ctx context.Context, keys ...string) ([]string, error) {
-	return s.client.SDiff(ctx, keys...).Result()
+	return s.client.SDiff(ctx, s.prefix.keys(keys)...).Result()
 }
 
 // SDiffStore 差集并存储
 func (s *Set) SDiffStore(ctx context.Context, destination string, keys ...string) (int64, error) {
-	return s.client.SDiffStore(ctx, destination, keys...).Result()
+	return s.client.SDiffStore(ctx, s.prefix.key(destination), s.prefix.keys(keys)...).Result()
 }
 
 // SInterCard 交集基数
 func (s *Set) SInterCard(ctx context.Context, limit int64, keys ...string) (int64, error) {
-	return s.client.SInterCard(ctx, limit, keys...).Result()
+	return s.client.SInterCard(ctx, limit, s.prefix.keys(keys)...).Result()
 }
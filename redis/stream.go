@@ -0,0 +1,535 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Stream 流操作
+type Stream struct {
+	client redis.UniversalClient
+	prefix keyPrefix
+}
+
+// NewStream 创建流操作实例
+func (c *Client) NewStream() *Stream {
+	return &Stream{client: c.client, prefix: c.prefix}
+}
+
+// XAdd 添加消息
+func (s *Stream) XAdd(ctx context.Context, args *redis.XAddArgs) (string, error) {
+	if s.prefix != "" {
+		prefixed := *args
+		prefixed.Stream = s.prefix.key(args.Stream)
+		args = &prefixed
+	}
+	return s.client.XAdd(ctx, args).Result()
+}
+
+// Add 以自动生成的ID添加消息
+func (s *Stream) Add(ctx context.Context, stream string, values map[string]interface{}) (string, error) {
+	return s.client.XAdd(ctx, &redis.XAddArgs{Stream: s.prefix.key(stream), Values: values}).Result()
+}
+
+// XLen 获取消息数量
+func (s *Stream) XLen(ctx context.Context, stream string) (int64, error) {
+	return s.client.XLen(ctx, s.prefix.key(stream)).Result()
+}
+
+// XRange 按ID范围获取消息
+func (s *Stream) XRange(ctx context.Context, stream, start, stop string) ([]redis.XMessage, error) {
+	return s.client.XRange(ctx, s.prefix.key(stream), start, stop).Result()
+}
+
+// XRangeN 按ID范围获取最多count条消息
+func (s *Stream) XRangeN(ctx context.Context, stream, start, stop string, count int64) ([]redis.XMessage, error) {
+	return s.client.XRangeN(ctx, s.prefix.key(stream), start, stop, count).Result()
+}
+
+// XRevRange 按ID范围逆序获取消息
+func (s *Stream) XRevRange(ctx context.Context, stream, start, stop string) ([]redis.XMessage, error) {
+	return s.client.XRevRange(ctx, s.prefix.key(stream), start, stop).Result()
+}
+
+// XDel 删除消息
+func (s *Stream) XDel(ctx context.Context, stream string, ids ...string) (int64, error) {
+	return s.client.XDel(ctx, s.prefix.key(stream), ids...).Result()
+}
+
+// XRead 读取消息
+func (s *Stream) XRead(ctx context.Context, args *redis.XReadArgs) ([]redis.XStream, error) {
+	if s.prefix != "" {
+		prefixed := *args
+		prefixed.Streams = s.prefixStreams(args.Streams)
+		args = &prefixed
+	}
+	streams, err := s.client.XRead(ctx, args).Result()
+	return s.stripStreams(streams), err
+}
+
+// XGroupCreate 创建消费者组
+func (s *Stream) XGroupCreate(ctx context.Context, stream, group, start string) error {
+	return s.client.XGroupCreate(ctx, s.prefix.key(stream), group, start).Err()
+}
+
+// XGroupCreateMkStream 创建消费者组，流不存在时自动创建
+func (s *Stream) XGroupCreateMkStream(ctx context.Context, stream, group, start string) error {
+	return s.client.XGroupCreateMkStream(ctx, s.prefix.key(stream), group, start).Err()
+}
+
+// XGroupSetID 设置消费者组的最后投递ID
+func (s *Stream) XGroupSetID(ctx context.Context, stream, group, start string) error {
+	return s.client.XGroupSetID(ctx, s.prefix.key(stream), group, start).Err()
+}
+
+// XGroupDestroy 删除消费者组
+func (s *Stream) XGroupDestroy(ctx context.Context, stream, group string) (int64, error) {
+	return s.client.XGroupDestroy(ctx, s.prefix.key(stream), group).Result()
+}
+
+// XGroupDelConsumer 删除消费者，返回其未确认的消息数
+func (s *Stream) XGroupDelConsumer(ctx context.Context, stream, group, consumer string) (int64, error) {
+	return s.client.XGroupDelConsumer(ctx, s.prefix.key(stream), group, consumer).Result()
+}
+
+// XReadGroup 以消费者组读取消息
+func (s *Stream) XReadGroup(ctx context.Context, args *redis.XReadGroupArgs) ([]redis.XStream, error) {
+	if s.prefix != "" {
+		prefixed := *args
+		prefixed.Streams = s.prefixStreams(args.Streams)
+		args = &prefixed
+	}
+	streams, err := s.client.XReadGroup(ctx, args).Result()
+	return s.stripStreams(streams), err
+}
+
+// XAck 确认消息
+func (s *Stream) XAck(ctx context.Context, stream, group string, ids ...string) (int64, error) {
+	return s.client.XAck(ctx, s.prefix.key(stream), group, ids...).Result()
+}
+
+// XPending 获取消费者组未确认消息的概要
+func (s *Stream) XPending(ctx context.Context, stream, group string) (*redis.XPending, error) {
+	return s.client.XPending(ctx, s.prefix.key(stream), group).Result()
+}
+
+// XPendingExt 获取未确认消息的详情
+func (s *Stream) XPendingExt(ctx context.Context, args *redis.XPendingExtArgs) ([]redis.XPendingExt, error) {
+	if s.prefix != "" {
+		prefixed := *args
+		prefixed.Stream = s.prefix.key(args.Stream)
+		args = &prefixed
+	}
+	return s.client.XPendingExt(ctx, args).Result()
+}
+
+// XClaim 转移未确认消息的所有权
+func (s *Stream) XClaim(ctx context.Context, args *redis.XClaimArgs) ([]redis.XMessage, error) {
+	return s.client.XClaim(ctx, s.claimArgs(args)).Result()
+}
+
+// XClaimJustID 转移未确认消息的所有权，只返回ID
+func (s *Stream) XClaimJustID(ctx context.Context, args *redis.XClaimArgs) ([]string, error) {
+	return s.client.XClaimJustID(ctx, s.claimArgs(args)).Result()
+}
+
+// XAutoClaim 扫描并转移空闲时间超过MinIdle的消息，返回消息和下一次扫描的起始ID
+func (s *Stream) XAutoClaim(ctx context.Context, args *redis.XAutoClaimArgs) ([]redis.XMessage, string, error) {
+	if s.prefix != "" {
+		prefixed := *args
+		prefixed.Stream = s.prefix.key(args.Stream)
+		args = &prefixed
+	}
+	return s.client.XAutoClaim(ctx, args).Result()
+}
+
+// XTrimMaxLen 精确修剪到最多maxLen条消息
+func (s *Stream) XTrimMaxLen(ctx context.Context, stream string, maxLen int64) (int64, error) {
+	return s.client.XTrimMaxLen(ctx, s.prefix.key(stream), maxLen).Result()
+}
+
+// XTrimMaxLenApprox 近似修剪到约maxLen条消息，limit限制单次删除数量，性能更好
+func (s *Stream) XTrimMaxLenApprox(ctx context.Context, stream string, maxLen, limit int64) (int64, error) {
+	return s.client.XTrimMaxLenApprox(ctx, s.prefix.key(stream), maxLen, limit).Result()
+}
+
+// XTrimMinID 删除ID小于minID的消息
+func (s *Stream) XTrimMinID(ctx context.Context, stream, minID string) (int64, error) {
+	return s.client.XTrimMinID(ctx, s.prefix.key(stream), minID).Result()
+}
+
+// XTrimMinIDApprox 近似删除ID小于minID的消息
+func (s *Stream) XTrimMinIDApprox(ctx context.Context, stream, minID string, limit int64) (int64, error) {
+	return s.client.XTrimMinIDApprox(ctx, s.prefix.key(stream), minID, limit).Result()
+}
+
+// XTrimOlderThan 删除早于指定时间的消息，依赖自动生成的时间戳ID
+func (s *Stream) XTrimOlderThan(ctx context.Context, stream string, age time.Duration) (int64, error) {
+	minID := fmt.Sprintf("%d-0", time.Now().Add(-age).UnixMilli())
+	return s.client.XTrimMinID(ctx, s.prefix.key(stream), minID).Result()
+}
+
+// XInfoStream 获取流信息
+func (s *Stream) XInfoStream(ctx context.Context, stream string) (*redis.XInfoStream, error) {
+	return s.client.XInfoStream(ctx, s.prefix.key(stream)).Result()
+}
+
+// XInfoGroups 获取消费者组信息
+func (s *Stream) XInfoGroups(ctx context.Context, stream string) ([]redis.XInfoGroup, error) {
+	return s.client.XInfoGroups(ctx, s.prefix.key(stream)).Result()
+}
+
+// XInfoConsumers 获取消费者信息
+func (s *Stream) XInfoConsumers(ctx context.Context, stream, group string) ([]redis.XInfoConsumer, error) {
+	return s.client.XInfoConsumers(ctx, s.prefix.key(stream), group).Result()
+}
+
+// prefixStreams 为 XREAD 参数中的流名加前缀，参数前一半是流名，后一半是ID
+func (s *Stream) prefixStreams(streams []string) []string {
+	prefixed := make([]string, len(streams))
+	copy(prefixed, streams)
+	for i := 0; i < len(prefixed)/2; i++ {
+		prefixed[i] = s.prefix.key(prefixed[i])
+	}
+	return prefixed
+}
+
+// stripStreams 去掉读取结果中流名的前缀
+func (s *Stream) stripStreams(streams []redis.XStream) []redis.XStream {
+	for i := range streams {
+		streams[i].Stream = s.prefix.strip(streams[i].Stream)
+	}
+	return streams
+}
+
+// claimArgs 返回流名加上前缀的 XCLAIM 参数
+func (s *Stream) claimArgs(args *redis.XClaimArgs) *redis.XClaimArgs {
+	if s.prefix == "" {
+		return args
+	}
+	prefixed := *args
+	prefixed.Stream = s.prefix.key(args.Stream)
+	return &prefixed
+}
+
+// StreamHandler 消息处理函数，返回nil时消息被确认，返回错误时消息保留在待确认列表中等待重试
+type StreamHandler func(ctx context.Context, msg redis.XMessage) error
+
+// ConsumerGroupConfig 消费者组配置
+type ConsumerGroupConfig struct {
+	Stream   string // 流
+	Group    string // 消费者组，不存在时自动创建
+	Consumer string // 消费者名称，重启后应保持不变以恢复未确认的消息
+	StartID  string // 创建消费者组时的起始ID，默认"$"只消费新消息，"0"从头消费
+
+	Concurrency   int           // 同时处理的最大消息数
+	BatchSize     int64         // 每次读取的最大消息数
+	Block         time.Duration // 无消息时的阻塞等待时间
+	ClaimInterval time.Duration // 检查其他消费者空闲消息的间隔
+	MinIdle       time.Duration // 消息未确认超过该时间后可被转移到当前消费者
+	MaxDeliveries int64         // 最大投递次数，超过后写入死信流并确认，0表示不限制
+	DeadLetter    string        // 死信流，默认为 Stream + ":dead"
+}
+
+// DefaultConsumerGroupConfig 默认消费者组配置
+func DefaultConsumerGroupConfig(stream, group, consumer string) *ConsumerGroupConfig {
+	return &ConsumerGroupConfig{
+		Stream:        stream,
+		Group:         group,
+		Consumer:      consumer,
+		StartID:       "$",
+		Concurrency:   10,
+		BatchSize:     10,
+		Block:         2 * time.Second,
+		ClaimInterval: 30 * time.Second,
+		MinIdle:       time.Minute,
+	}
+}
+
+// consumerGroup 消费者组的运行状态
+type consumerGroup struct {
+	stream  *Stream
+	config  *ConsumerGroupConfig
+	handler StreamHandler
+	ctx     context.Context
+	slots   chan struct{}
+	wg      sync.WaitGroup
+
+	// inflight 当前消费者已派发但尚未处理完成的消息ID
+	inflight map[string]struct{}
+	mutex    sync.Mutex
+}
+
+// ConsumeGroup 以消费者组消费消息，直到ctx结束；返回前等待处理中的消息完成。
+// 启动时先处理当前消费者未确认的消息，运行中定期转移其他消费者长时间未确认的消息
+func (s *Stream) ConsumeGroup(ctx context.Context, config *ConsumerGroupConfig, handler StreamHandler) error {
+	if config == nil || config.Stream == "" || config.Group == "" || config.Consumer == "" {
+		return errors.New("stream, group and consumer are required")
+	}
+	cfg := *config
+	defaults := DefaultConsumerGroupConfig(cfg.Stream, cfg.Group, cfg.Consumer)
+	if cfg.StartID == "" {
+		cfg.StartID = defaults.StartID
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = defaults.Concurrency
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaults.BatchSize
+	}
+	if cfg.Block <= 0 {
+		cfg.Block = defaults.Block
+	}
+	if cfg.ClaimInterval <= 0 {
+		cfg.ClaimInterval = defaults.ClaimInterval
+	}
+	if cfg.MinIdle <= 0 {
+		cfg.MinIdle = defaults.MinIdle
+	}
+	if cfg.DeadLetter == "" {
+		cfg.DeadLetter = cfg.Stream + ":dead"
+	}
+
+	err := s.XGroupCreateMkStream(ctx, cfg.Stream, cfg.Group, cfg.StartID)
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return fmt.Errorf("failed to create consumer group: %w", err)
+	}
+
+	g := &consumerGroup{
+		stream:  s,
+		config:  &cfg,
+		handler: handler,
+		ctx:     ctx,
+		slots:   make(chan struct{}, cfg.Concurrency),
+
+		inflight: make(map[string]struct{}),
+	}
+	defer g.wg.Wait()
+
+	// 恢复上次退出时未确认的消息
+	if err := g.recover(); err != nil {
+		return err
+	}
+
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		ticker := time.NewTicker(cfg.ClaimInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				g.claim()
+			}
+		}
+	}()
+
+	for {
+		streams, err := s.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    cfg.Group,
+			Consumer: cfg.Consumer,
+			Streams:  []string{cfg.Stream, ">"},
+			Count:    cfg.BatchSize,
+			Block:    cfg.Block,
+		})
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil && err != redis.Nil {
+			// 连接错误时稍后重试
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+		for _, stream := range streams {
+			g.track(stream.Messages)
+			for _, msg := range stream.Messages {
+				if !g.dispatch(msg) {
+					return ctx.Err()
+				}
+			}
+		}
+	}
+}
+
+// recover 处理当前消费者未确认的消息
+func (g *consumerGroup) recover() error {
+	start := "0"
+	for {
+		streams, err := g.stream.XReadGroup(g.ctx, &redis.XReadGroupArgs{
+			Group:    g.config.Group,
+			Consumer: g.config.Consumer,
+			Streams:  []string{g.config.Stream, start},
+			Count:    g.config.BatchSize,
+		})
+		if err == redis.Nil {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read pending messages: %w", err)
+		}
+		if len(streams) == 0 || len(streams[0].Messages) == 0 {
+			return nil
+		}
+		g.track(streams[0].Messages)
+		for _, msg := range streams[0].Messages {
+			start = msg.ID
+			// 消息已被删除时只剩ID，直接确认
+			if msg.Values == nil {
+				g.stream.XAck(g.ctx, g.config.Stream, g.config.Group, msg.ID)
+				g.untrack(msg.ID)
+				continue
+			}
+			if !g.dispatch(msg) {
+				return nil
+			}
+		}
+	}
+}
+
+// claim 转移其他消费者空闲时间超过MinIdle的消息，超过最大投递次数的写入死信流
+func (g *consumerGroup) claim() {
+	pending, err := g.stream.XPendingExt(g.ctx, &redis.XPendingExtArgs{
+		Stream: g.config.Stream,
+		Group:  g.config.Group,
+		Idle:   g.config.MinIdle,
+		Start:  "-",
+		End:    "+",
+		Count:  g.config.BatchSize,
+	})
+	if err != nil || len(pending) == 0 {
+		return
+	}
+
+	var ids, dead []string
+	for _, p := range pending {
+		// 当前消费者仍在处理的消息空闲时间同样会增长，不能再次派发或写入死信流
+		if p.Consumer == g.config.Consumer && g.isInFlight(p.ID) {
+			continue
+		}
+		if g.config.MaxDeliveries > 0 && p.RetryCount >= g.config.MaxDeliveries {
+			dead = append(dead, p.ID)
+		} else {
+			ids = append(ids, p.ID)
+		}
+	}
+	if len(dead) > 0 {
+		g.deadLetter(dead)
+	}
+	if len(ids) == 0 {
+		return
+	}
+
+	// XCLAIM 会再次检查空闲时间，避免与其他消费者重复转移
+	msgs, err := g.stream.XClaim(g.ctx, &redis.XClaimArgs{
+		Stream:   g.config.Stream,
+		Group:    g.config.Group,
+		Consumer: g.config.Consumer,
+		MinIdle:  g.config.MinIdle,
+		Messages: ids,
+	})
+	if err != nil {
+		return
+	}
+	g.track(msgs)
+	for _, msg := range msgs {
+		if !g.dispatch(msg) {
+			return
+		}
+	}
+}
+
+// deadLetter 将消息写入死信流并确认
+func (g *consumerGroup) deadLetter(ids []string) {
+	msgs, err := g.stream.XClaim(g.ctx, &redis.XClaimArgs{
+		Stream:   g.config.Stream,
+		Group:    g.config.Group,
+		Consumer: g.config.Consumer,
+		MinIdle:  g.config.MinIdle,
+		Messages: ids,
+	})
+	if err != nil {
+		return
+	}
+	for _, msg := range msgs {
+		values := make(map[string]interface{}, len(msg.Values)+1)
+		for k, v := range msg.Values {
+			values[k] = v
+		}
+		values["_source_id"] = msg.ID
+		if _, err := g.stream.Add(g.ctx, g.config.DeadLetter, values); err != nil {
+			return
+		}
+		g.stream.XAck(g.ctx, g.config.Stream, g.config.Group, msg.ID)
+	}
+}
+
+// isInFlight 消息是否已派发且尚未处理完成
+func (g *consumerGroup) isInFlight(id string) bool {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	_, ok := g.inflight[id]
+	return ok
+}
+
+// track 标记已读取到当前消费者、即将派发的消息
+func (g *consumerGroup) track(msgs []redis.XMessage) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	for _, msg := range msgs {
+		g.inflight[msg.ID] = struct{}{}
+	}
+}
+
+// untrack 消息处理完成后清除标记
+func (g *consumerGroup) untrack(id string) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	delete(g.inflight, id)
+}
+
+// dispatch 在空闲槽位中处理消息，ctx结束时返回false。
+// 调用前需要先 track 消息，等待槽位期间消息同样不能被转移
+func (g *consumerGroup) dispatch(msg redis.XMessage) bool {
+	select {
+	case g.slots <- struct{}{}:
+	case <-g.ctx.Done():
+		return false
+	}
+
+	g.wg.Add(1)
+	go func() {
+		defer func() {
+			g.untrack(msg.ID)
+			<-g.slots
+			g.wg.Done()
+		}()
+		g.process(msg)
+	}()
+	return true
+}
+
+// process 调用处理函数，成功时确认消息
+func (g *consumerGroup) process(msg redis.XMessage) {
+	// 停止消费时让处理中的消息完成
+	ctx := context.WithoutCancel(g.ctx)
+	defer func() {
+		// panic的消息保留在待确认列表中，稍后被重新投递
+		recover()
+	}()
+	if err := g.handler(ctx, msg); err != nil {
+		return
+	}
+	g.stream.XAck(ctx, g.config.Stream, g.config.Group, msg.ID)
+}
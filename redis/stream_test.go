@@ -0,0 +1,246 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func TestStream(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+	s := client.NewStream()
+	key := "test:stream:basic"
+	client.Del(ctx, key)
+	defer client.Del(ctx, key)
+
+	for i := 0; i < 5; i++ {
+		if _, err := s.Add(ctx, key, map[string]interface{}{"n": i}); err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+	}
+	if n, _ := s.XLen(ctx, key); n != 5 {
+		t.Errorf("Expected 5 messages, got %d", n)
+	}
+
+	streams, err := s.XRead(ctx, &redis.XReadArgs{Streams: []string{key, "0"}, Count: 2})
+	if err != nil || len(streams) != 1 || len(streams[0].Messages) != 2 {
+		t.Fatalf("Unexpected XRead result %v, %v", streams, err)
+	}
+	if streams[0].Messages[0].Values["n"] != "0" {
+		t.Errorf("Unexpected first message %v", streams[0].Messages[0])
+	}
+
+	// 消费者组读取、待确认与确认
+	if err := s.XGroupCreate(ctx, key, "g", "0"); err != nil {
+		t.Fatalf("XGroupCreate failed: %v", err)
+	}
+	streams, err = s.XReadGroup(ctx, &redis.XReadGroupArgs{Group: "g", Consumer: "c1", Streams: []string{key, ">"}, Count: 3})
+	if err != nil || len(streams[0].Messages) != 3 {
+		t.Fatalf("Unexpected XReadGroup result %v, %v", streams, err)
+	}
+	pending, err := s.XPending(ctx, key, "g")
+	if err != nil || pending.Count != 3 {
+		t.Fatalf("Expected 3 pending, got %+v, %v", pending, err)
+	}
+	if n, _ := s.XAck(ctx, key, "g", streams[0].Messages[0].ID); n != 1 {
+		t.Errorf("Expected 1 acked, got %d", n)
+	}
+
+	// 转移给其他消费者
+	msgs, err := s.XClaim(ctx, &redis.XClaimArgs{Stream: key, Group: "g", Consumer: "c2", Messages: []string{streams[0].Messages[1].ID}})
+	if err != nil || len(msgs) != 1 {
+		t.Fatalf("Unexpected XClaim result %v, %v", msgs, err)
+	}
+	msgs, _, err = s.XAutoClaim(ctx, &redis.XAutoClaimArgs{Stream: key, Group: "g", Consumer: "c2", Start: "0", Count: 10})
+	if err != nil || len(msgs) != 2 {
+		t.Fatalf("Unexpected XAutoClaim result %v, %v", msgs, err)
+	}
+	ext, err := s.XPendingExt(ctx, &redis.XPendingExtArgs{Stream: key, Group: "g", Start: "-", End: "+", Count: 10, Consumer: "c2"})
+	if err != nil || len(ext) != 2 {
+		t.Fatalf("Expected 2 pending for c2, got %v, %v", ext, err)
+	}
+
+	// 修剪
+	if _, err := s.XTrimMaxLen(ctx, key, 2); err != nil {
+		t.Fatalf("XTrimMaxLen failed: %v", err)
+	}
+	if n, _ := s.XLen(ctx, key); n != 2 {
+		t.Errorf("Expected 2 messages after trim, got %d", n)
+	}
+	if _, err := s.XTrimOlderThan(ctx, key, -time.Hour); err != nil {
+		t.Fatalf("XTrimOlderThan failed: %v", err)
+	}
+	if n, _ := s.XLen(ctx, key); n != 0 {
+		t.Errorf("Expected empty stream, got %d", n)
+	}
+}
+
+// collectMessages 记录处理过的消息
+type collectMessages struct {
+	mu   sync.Mutex
+	seen map[string]int
+}
+
+func (c *collectMessages) add(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.seen[id]++
+}
+
+func (c *collectMessages) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.seen)
+}
+
+func TestConsumeGroup(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+	s := client.NewStream()
+	key := "test:stream:group"
+	client.Del(ctx, key, key+":dead")
+	defer client.Del(ctx, key, key+":dead")
+
+	// 已崩溃的消费者读取后未确认的消息
+	s.XGroupCreateMkStream(ctx, key, "workers", "0")
+	for i := 0; i < 3; i++ {
+		s.Add(ctx, key, map[string]interface{}{"type": "orphan"})
+	}
+	s.XReadGroup(ctx, &redis.XReadGroupArgs{Group: "workers", Consumer: "crashed", Streams: []string{key, ">"}})
+
+	// 当前消费者上次退出时未确认的消息
+	s.Add(ctx, key, map[string]interface{}{"type": "own"})
+	s.XReadGroup(ctx, &redis.XReadGroupArgs{Group: "workers", Consumer: "c1", Streams: []string{key, ">"}})
+
+	for i := 0; i < 10; i++ {
+		s.Add(ctx, key, map[string]interface{}{"type": "new"})
+	}
+	s.Add(ctx, key, map[string]interface{}{"type": "poison"})
+
+	processed := &collectMessages{seen: make(map[string]int)}
+	var running, maxRunning int32
+	handler := func(ctx context.Context, msg redis.XMessage) error {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			m := atomic.LoadInt32(&maxRunning)
+			if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+				break
+			}
+		}
+		if msg.Values["type"] == "poison" {
+			return errors.New("cannot process")
+		}
+		processed.add(msg.ID)
+		return nil
+	}
+
+	consumeCtx, cancel := context.WithCancel(ctx)
+	done := make(chan error, 1)
+	go func() {
+		done <- s.ConsumeGroup(consumeCtx, &ConsumerGroupConfig{
+			Stream:        key,
+			Group:         "workers",
+			Consumer:      "c1",
+			Concurrency:   2,
+			Block:         50 * time.Millisecond,
+			ClaimInterval: 50 * time.Millisecond,
+			MinIdle:       100 * time.Millisecond,
+			MaxDeliveries: 2,
+		}, handler)
+	}()
+
+	// 自己的、新的以及从崩溃消费者转移的消息都被处理，失败的消息进入死信流
+	if !waitFor(t, func() bool {
+		n, _ := s.XLen(ctx, key+":dead")
+		return processed.len() == 14 && n == 1
+	}) {
+		n, _ := s.XLen(ctx, key+":dead")
+		t.Errorf("Expected 14 processed and 1 dead, got %d processed and %d dead", processed.len(), n)
+	}
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+
+	if maxRunning > 2 {
+		t.Errorf("Expected at most 2 concurrent handlers, got %d", maxRunning)
+	}
+	pending, _ := s.XPending(ctx, key, "workers")
+	if pending.Count != 0 {
+		t.Errorf("Expected no pending messages, got %d", pending.Count)
+	}
+	dead, _ := s.XRange(ctx, key+":dead", "-", "+")
+	if len(dead) != 1 || dead[0].Values["type"] != "poison" || dead[0].Values["_source_id"] == nil {
+		t.Errorf("Unexpected dead letters %v", dead)
+	}
+}
+
+func TestConsumeGroupSkipsInFlight(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+	s := client.NewStream()
+	key := "test:stream:inflight"
+	client.Del(ctx, key, key+":dead")
+	defer client.Del(ctx, key, key+":dead")
+
+	s.XGroupCreateMkStream(ctx, key, "workers", "0")
+	for i := 0; i < 3; i++ {
+		s.Add(ctx, key, map[string]interface{}{"type": "slow"})
+	}
+
+	// 处理时间超过MinIdle，正在处理和等待槽位的消息都不能被自己再次转移
+	processed := &collectMessages{seen: make(map[string]int)}
+	handler := func(ctx context.Context, msg redis.XMessage) error {
+		time.Sleep(150 * time.Millisecond)
+		processed.add(msg.ID)
+		return nil
+	}
+
+	consumeCtx, cancel := context.WithCancel(ctx)
+	done := make(chan error, 1)
+	go func() {
+		done <- s.ConsumeGroup(consumeCtx, &ConsumerGroupConfig{
+			Stream:        key,
+			Group:         "workers",
+			Consumer:      "c1",
+			Concurrency:   1,
+			Block:         50 * time.Millisecond,
+			ClaimInterval: 10 * time.Millisecond,
+			MinIdle:       30 * time.Millisecond,
+			MaxDeliveries: 1,
+		}, handler)
+	}()
+
+	if !waitFor(t, func() bool { return processed.len() == 3 }) {
+		t.Errorf("Expected 3 processed, got %d", processed.len())
+	}
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+	<-done
+
+	processed.mu.Lock()
+	for id, n := range processed.seen {
+		if n != 1 {
+			t.Errorf("Expected message %s to be processed once, got %d", id, n)
+		}
+	}
+	processed.mu.Unlock()
+	if n, _ := s.XLen(ctx, key+":dead"); n != 0 {
+		t.Errorf("Expected no dead letters, got %d", n)
+	}
+}
+
+func TestConsumeGroupValidation(t *testing.T) {
+	client := newTestClient(t)
+	err := client.NewStream().ConsumeGroup(context.Background(), &ConsumerGroupConfig{Stream: "s"}, nil)
+	if err == nil {
+		t.Error("Expected error for missing group and consumer")
+	}
+}
@@ -0,0 +1,446 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/daxiong0327/tool-kit/goroutine"
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrMessageDecode 消息负载解码失败
+var ErrMessageDecode = errors.New("redis: failed to decode message")
+
+// MessageHandler 消息处理函数
+type MessageHandler func(ctx context.Context, msg *redis.Message) error
+
+// TypedMessage 解码后的消息
+type TypedMessage[T any] struct {
+	Channel string // 消息所在频道
+	Pattern string // 匹配的模式，频道订阅时为空
+	Payload T      // 解码后的负载
+}
+
+// SubscriberConfig 自动重连订阅者配置
+type SubscriberConfig struct {
+	Codec               Codec                   // 类型化处理函数使用的编解码器，默认JSON
+	Concurrency         int                     // 同时执行的处理函数数量上限，1表示按接收顺序处理
+	BufferSize          int                     // 等待处理的消息缓冲区大小，缓冲区满时丢弃新消息
+	SlowThreshold       time.Duration           // 处理时间超过该值计为慢消费并记录警告，0表示不检测
+	HealthCheckInterval time.Duration           // 连接空闲时发送PING的间隔，两个间隔内没有任何响应则重连
+	ReconnectDelay      time.Duration           // 初始重连间隔
+	MaxReconnectDelay   time.Duration           // 最大重连间隔
+	Executor            *goroutine.SafeExecutor // 执行处理函数的执行器，负责panic恢复，nil时自动创建
+	Logger              Logger                  // 日志记录器，nil时使用 DefaultLogger
+}
+
+// DefaultSubscriberConfig 默认自动重连订阅者配置
+func DefaultSubscriberConfig() *SubscriberConfig {
+	return &SubscriberConfig{
+		Codec:               JSONCodec,
+		Concurrency:         10,
+		BufferSize:          1000,
+		SlowThreshold:       time.Second,
+		HealthCheckInterval: 5 * time.Second,
+		ReconnectDelay:      100 * time.Millisecond,
+		MaxReconnectDelay:   5 * time.Second,
+	}
+}
+
+// SubscriberStats 订阅者统计
+type SubscriberStats struct {
+	Received     int64 `json:"received"`      // 收到的消息数
+	Handled      int64 `json:"handled"`       // 处理成功的消息数
+	Failed       int64 `json:"failed"`        // 处理函数返回错误的消息数，包括解码失败
+	DecodeErrors int64 `json:"decode_errors"` // 解码失败的消息数
+	Panics       int64 `json:"panics"`        // 处理函数panic的次数
+	Dropped      int64 `json:"dropped"`       // 缓冲区满或没有处理函数而丢弃的消息数
+	Slow         int64 `json:"slow"`          // 处理时间超过 SlowThreshold 的消息数
+	Reconnects   int64 `json:"reconnects"`    // 连接断开后重新订阅的次数
+	Pending      int   `json:"pending"`       // 缓冲区中等待处理的消息数
+}
+
+// SupervisedSubscriber 自动重连的订阅者，连接断开后按退避间隔重连并重新订阅所有频道和模式，
+// 消息按频道（或模式）分发给处理函数，处理函数在 SafeExecutor 中执行并限制并发数
+type SupervisedSubscriber struct {
+	client   redis.UniversalClient
+	config   *SubscriberConfig
+	executor *goroutine.SafeExecutor
+	logger   Logger
+
+	mu       sync.Mutex
+	channels map[string]MessageHandler
+	patterns map[string]MessageHandler
+	pubsub   *redis.PubSub // 当前连接，未连接时为nil
+	running  bool
+
+	queue chan *redis.Message
+
+	received     int64
+	handled      int64
+	failed       int64
+	decodeErrors int64
+	panics       int64
+	dropped      int64
+	slow         int64
+	reconnects   int64
+}
+
+// NewSupervisedSubscriber 创建自动重连的订阅者，config为nil时使用默认配置
+func (c *Client) NewSupervisedSubscriber(config *SubscriberConfig) *SupervisedSubscriber {
+	if config == nil {
+		config = DefaultSubscriberConfig()
+	}
+	cfg := *config
+	defaults := DefaultSubscriberConfig()
+	if cfg.Codec == nil {
+		cfg.Codec = defaults.Codec
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = defaults.Concurrency
+	}
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = defaults.BufferSize
+	}
+	if cfg.HealthCheckInterval <= 0 {
+		cfg.HealthCheckInterval = defaults.HealthCheckInterval
+	}
+	if cfg.ReconnectDelay <= 0 {
+		cfg.ReconnectDelay = defaults.ReconnectDelay
+	}
+	if cfg.MaxReconnectDelay < cfg.ReconnectDelay {
+		cfg.MaxReconnectDelay = cfg.ReconnectDelay
+	}
+
+	s := &SupervisedSubscriber{
+		client:   c.client,
+		config:   &cfg,
+		executor: cfg.Executor,
+		logger:   cfg.Logger,
+		channels: make(map[string]MessageHandler),
+		patterns: make(map[string]MessageHandler),
+		queue:    make(chan *redis.Message, cfg.BufferSize),
+	}
+	if s.executor == nil {
+		s.executor = goroutine.NewSafeExecutor()
+	}
+	if s.logger == nil {
+		s.logger = &DefaultLogger{}
+	}
+	return s
+}
+
+// Handle 订阅频道并注册处理函数，同一频道重复注册时替换处理函数；运行中调用会立即在当前连接上发送订阅命令，
+// 服务端完成订阅前发布的消息收不到
+func (s *SupervisedSubscriber) Handle(channel string, handler MessageHandler) error {
+	return s.register(s.channels, channel, handler, false)
+}
+
+// HandlePattern 订阅模式并注册处理函数，消息按匹配的模式分发
+func (s *SupervisedSubscriber) HandlePattern(pattern string, handler MessageHandler) error {
+	return s.register(s.patterns, pattern, handler, true)
+}
+
+// HandleTyped 订阅频道，消息负载按订阅者的编解码器解码为T后交给handler，解码失败计入 DecodeErrors
+func HandleTyped[T any](s *SupervisedSubscriber, channel string, handler func(ctx context.Context, msg *TypedMessage[T]) error) error {
+	return s.Handle(channel, typedHandler(s, handler))
+}
+
+// HandleTypedPattern 订阅模式，消息负载解码为T后交给handler
+func HandleTypedPattern[T any](s *SupervisedSubscriber, pattern string, handler func(ctx context.Context, msg *TypedMessage[T]) error) error {
+	return s.HandlePattern(pattern, typedHandler(s, handler))
+}
+
+// typedHandler 将类型化处理函数包装为 MessageHandler
+func typedHandler[T any](s *SupervisedSubscriber, handler func(ctx context.Context, msg *TypedMessage[T]) error) MessageHandler {
+	return func(ctx context.Context, msg *redis.Message) error {
+		typed := &TypedMessage[T]{Channel: msg.Channel, Pattern: msg.Pattern}
+		if err := s.config.Codec.Unmarshal([]byte(msg.Payload), &typed.Payload); err != nil {
+			atomic.AddInt64(&s.decodeErrors, 1)
+			return fmt.Errorf("%w on channel %s: %w", ErrMessageDecode, msg.Channel, err)
+		}
+		return handler(ctx, typed)
+	}
+}
+
+// register 注册处理函数，运行中时在当前连接上补充订阅
+func (s *SupervisedSubscriber) register(handlers map[string]MessageHandler, name string, handler MessageHandler, pattern bool) error {
+	if name == "" || handler == nil {
+		return errors.New("channel and handler are required")
+	}
+
+	s.mu.Lock()
+	_, exists := handlers[name]
+	handlers[name] = handler
+	pubsub := s.pubsub
+	s.mu.Unlock()
+
+	if exists || pubsub == nil {
+		return nil
+	}
+	// 订阅失败时连接会被判定为断开，重连后按处理函数列表重新订阅
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.HealthCheckInterval)
+	defer cancel()
+	if pattern {
+		return pubsub.PSubscribe(ctx, name)
+	}
+	return pubsub.Subscribe(ctx, name)
+}
+
+// Unhandle 取消订阅频道或模式并移除处理函数
+func (s *SupervisedSubscriber) Unhandle(ctx context.Context, names ...string) error {
+	s.mu.Lock()
+	var channels, patterns []string
+	for _, name := range names {
+		if _, ok := s.channels[name]; ok {
+			delete(s.channels, name)
+			channels = append(channels, name)
+		}
+		if _, ok := s.patterns[name]; ok {
+			delete(s.patterns, name)
+			patterns = append(patterns, name)
+		}
+	}
+	pubsub := s.pubsub
+	s.mu.Unlock()
+
+	if pubsub == nil {
+		return nil
+	}
+	if len(channels) > 0 {
+		if err := pubsub.Unsubscribe(ctx, channels...); err != nil {
+			return err
+		}
+	}
+	if len(patterns) > 0 {
+		return pubsub.PUnsubscribe(ctx, patterns...)
+	}
+	return nil
+}
+
+// Run 连接并持续分发消息，连接断开时自动重连，直到ctx结束；返回前等待处理中的消息完成
+func (s *SupervisedSubscriber) Run(ctx context.Context) error {
+	s.mu.Lock()
+	if s.running {
+		s.mu.Unlock()
+		return errors.New("subscriber is already running")
+	}
+	if len(s.channels) == 0 && len(s.patterns) == 0 {
+		s.mu.Unlock()
+		return errors.New("no channel or pattern to subscribe")
+	}
+	s.running = true
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.running = false
+		s.mu.Unlock()
+	}()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		s.dispatch(ctx)
+	}()
+	defer wg.Wait()
+
+	delay := s.config.ReconnectDelay
+	connected := false
+	for {
+		pubsub, err := s.subscribe(ctx)
+		if err == nil {
+			if connected {
+				atomic.AddInt64(&s.reconnects, 1)
+				s.logger.Infof("redis subscriber resubscribed")
+			}
+			connected = true
+			delay = s.config.ReconnectDelay
+			err = s.receive(ctx, pubsub)
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		s.logger.Warnf("redis subscriber disconnected, reconnecting in %v: %v", delay, err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		delay *= 2
+		if delay > s.config.MaxReconnectDelay {
+			delay = s.config.MaxReconnectDelay
+		}
+	}
+}
+
+// subscribe 建立新连接并订阅所有已注册的频道和模式
+func (s *SupervisedSubscriber) subscribe(ctx context.Context) (*redis.PubSub, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	channels := make([]string, 0, len(s.channels))
+	for channel := range s.channels {
+		channels = append(channels, channel)
+	}
+	patterns := make([]string, 0, len(s.patterns))
+	for pattern := range s.patterns {
+		patterns = append(patterns, pattern)
+	}
+
+	pubsub := s.client.Subscribe(ctx)
+	if len(channels) > 0 {
+		if err := pubsub.Subscribe(ctx, channels...); err != nil {
+			pubsub.Close()
+			return nil, err
+		}
+	}
+	if len(patterns) > 0 {
+		if err := pubsub.PSubscribe(ctx, patterns...); err != nil {
+			pubsub.Close()
+			return nil, err
+		}
+	}
+	s.pubsub = pubsub
+	return pubsub, nil
+}
+
+// receive 在连接上接收消息直到出错或ctx结束，空闲时发送PING检测连接
+func (s *SupervisedSubscriber) receive(ctx context.Context, pubsub *redis.PubSub) error {
+	defer func() {
+		s.mu.Lock()
+		if s.pubsub == pubsub {
+			s.pubsub = nil
+		}
+		s.mu.Unlock()
+		pubsub.Close()
+	}()
+
+	// ctx结束时关闭连接，使阻塞的读取立即返回
+	stop := context.AfterFunc(ctx, func() {
+		pubsub.Close()
+	})
+	defer stop()
+
+	interval := s.config.HealthCheckInterval
+	lastSeen := time.Now()
+	for {
+		msg, err := pubsub.ReceiveTimeout(ctx, interval)
+		if err != nil {
+			var netErr net.Error
+			if !errors.As(err, &netErr) || !netErr.Timeout() {
+				return err
+			}
+			if time.Since(lastSeen) >= 2*interval {
+				return fmt.Errorf("no response from server in %v", time.Since(lastSeen))
+			}
+			if err := pubsub.Ping(ctx); err != nil {
+				return err
+			}
+			continue
+		}
+		lastSeen = time.Now()
+
+		if message, ok := msg.(*redis.Message); ok {
+			atomic.AddInt64(&s.received, 1)
+			select {
+			case s.queue <- message:
+			default:
+				// 不阻塞接收，避免服务端输出缓冲区溢出断开连接
+				atomic.AddInt64(&s.dropped, 1)
+			}
+		}
+	}
+}
+
+// dispatch 从缓冲区取出消息并在执行器中调用处理函数，并发数受 Concurrency 限制
+func (s *SupervisedSubscriber) dispatch(ctx context.Context) {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	slots := make(chan struct{}, s.config.Concurrency)
+	for {
+		var msg *redis.Message
+		select {
+		case <-ctx.Done():
+			return
+		case msg = <-s.queue:
+		}
+
+		handler := s.handler(msg)
+		if handler == nil {
+			atomic.AddInt64(&s.dropped, 1)
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case slots <- struct{}{}:
+		}
+		wg.Add(1)
+		s.executor.Go(func() {
+			completed := false
+			defer func() {
+				if !completed {
+					atomic.AddInt64(&s.panics, 1)
+				}
+				<-slots
+				wg.Done()
+			}()
+			s.handle(ctx, handler, msg)
+			completed = true
+		})
+	}
+}
+
+// handler 返回消息对应的处理函数，模式订阅的消息按模式查找
+func (s *SupervisedSubscriber) handler(msg *redis.Message) MessageHandler {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if msg.Pattern != "" {
+		return s.patterns[msg.Pattern]
+	}
+	return s.channels[msg.Channel]
+}
+
+// handle 调用处理函数并记录统计
+func (s *SupervisedSubscriber) handle(ctx context.Context, handler MessageHandler, msg *redis.Message) {
+	start := time.Now()
+	err := handler(ctx, msg)
+	elapsed := time.Since(start)
+
+	if s.config.SlowThreshold > 0 && elapsed > s.config.SlowThreshold {
+		atomic.AddInt64(&s.slow, 1)
+		s.logger.Warnf("slow redis message handler on channel %s: %v", msg.Channel, elapsed)
+	}
+	if err != nil {
+		atomic.AddInt64(&s.failed, 1)
+		s.logger.Errorf("redis message handler failed on channel %s: %v", msg.Channel, err)
+		return
+	}
+	atomic.AddInt64(&s.handled, 1)
+}
+
+// Stats 返回统计信息
+func (s *SupervisedSubscriber) Stats() SubscriberStats {
+	return SubscriberStats{
+		Received:     atomic.LoadInt64(&s.received),
+		Handled:      atomic.LoadInt64(&s.handled),
+		Failed:       atomic.LoadInt64(&s.failed),
+		DecodeErrors: atomic.LoadInt64(&s.decodeErrors),
+		Panics:       atomic.LoadInt64(&s.panics),
+		Dropped:      atomic.LoadInt64(&s.dropped),
+		Slow:         atomic.LoadInt64(&s.slow),
+		Reconnects:   atomic.LoadInt64(&s.reconnects),
+		Pending:      len(s.queue),
+	}
+}
@@ -0,0 +1,213 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type orderEvent struct {
+	ID     int    `json:"id"`
+	Status string `json:"status"`
+}
+
+// startSubscriber 在后台运行订阅者，返回停止函数
+func startSubscriber(t *testing.T, s *SupervisedSubscriber) func() {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- s.Run(ctx)
+	}()
+	if !waitFor(t, func() bool {
+		if !s.connected() {
+			return false
+		}
+		// 等待服务端完成订阅
+		numPat, _ := s.client.PubSubNumPat(context.Background()).Result()
+		return numPat > 0 || len(s.patterns) == 0
+	}) {
+		t.Fatal("Subscriber did not connect")
+	}
+	return func() {
+		cancel()
+		if err := <-done; !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected Run to return context.Canceled, got %v", err)
+		}
+	}
+}
+
+// connected 返回是否已建立连接，仅用于测试
+func (s *SupervisedSubscriber) connected() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.pubsub != nil
+}
+
+func TestSupervisedSubscriber(t *testing.T) {
+	client := newTestClient(t)
+	defer client.Close()
+
+	ctx := context.Background()
+	sub := client.NewSupervisedSubscriber(nil)
+	if err := sub.Run(ctx); err == nil {
+		t.Error("Expected Run without handlers to fail")
+	}
+
+	var mu sync.Mutex
+	var orders []orderEvent
+	var patternChannels []string
+	err := HandleTyped(sub, "test:sub:orders", func(ctx context.Context, msg *TypedMessage[orderEvent]) error {
+		mu.Lock()
+		orders = append(orders, msg.Payload)
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("HandleTyped failed: %v", err)
+	}
+	sub.HandlePattern("test:sub:events:*", func(ctx context.Context, msg *redis.Message) error {
+		mu.Lock()
+		patternChannels = append(patternChannels, msg.Channel)
+		mu.Unlock()
+		return nil
+	})
+	stop := startSubscriber(t, sub)
+	defer stop()
+
+	publisher := client.NewPublisher()
+	publisher.Publish(ctx, "test:sub:orders", `{"id":1,"status":"paid"}`)
+	publisher.Publish(ctx, "test:sub:orders", `not json`)
+	publisher.Publish(ctx, "test:sub:events:a", "x")
+
+	if !waitFor(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(orders) == 1 && len(patternChannels) == 1 && sub.Stats().DecodeErrors == 1
+	}) {
+		t.Fatalf("Messages not handled, stats %+v", sub.Stats())
+	}
+	if orders[0] != (orderEvent{ID: 1, Status: "paid"}) || patternChannels[0] != "test:sub:events:a" {
+		t.Errorf("Unexpected messages %v %v", orders, patternChannels)
+	}
+
+	// 运行中注册的频道立即生效
+	var late int32
+	sub.Handle("test:sub:late", func(ctx context.Context, msg *redis.Message) error {
+		atomic.AddInt32(&late, 1)
+		return nil
+	})
+	if !waitFor(t, func() bool {
+		counts, _ := client.GetClient().PubSubNumSub(ctx, "test:sub:late").Result()
+		return counts["test:sub:late"] == 1
+	}) {
+		t.Fatal("Late channel not subscribed")
+	}
+	publisher.Publish(ctx, "test:sub:late", "x")
+	if !waitFor(t, func() bool { return atomic.LoadInt32(&late) == 1 }) {
+		t.Error("Handler registered while running did not receive message")
+	}
+
+	stats := sub.Stats()
+	if stats.Received != 4 || stats.Handled != 3 || stats.Failed != 1 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+}
+
+func TestSupervisedSubscriberResubscribe(t *testing.T) {
+	client := newTestClient(t)
+	defer client.Close()
+
+	ctx := context.Background()
+	config := DefaultSubscriberConfig()
+	config.ReconnectDelay = 10 * time.Millisecond
+	sub := client.NewSupervisedSubscriber(config)
+
+	var received int32
+	sub.Handle("test:sub:reconnect", func(ctx context.Context, msg *redis.Message) error {
+		atomic.AddInt32(&received, 1)
+		return nil
+	})
+	stop := startSubscriber(t, sub)
+	defer stop()
+
+	// 模拟连接断开
+	sub.mu.Lock()
+	sub.pubsub.Close()
+	sub.mu.Unlock()
+
+	if !waitFor(t, func() bool { return sub.Stats().Reconnects == 1 && sub.connected() }) {
+		t.Fatalf("Subscriber did not reconnect, stats %+v", sub.Stats())
+	}
+	client.NewPublisher().Publish(ctx, "test:sub:reconnect", "x")
+	if !waitFor(t, func() bool { return atomic.LoadInt32(&received) == 1 }) {
+		t.Error("Message not received after resubscribe")
+	}
+}
+
+func TestSupervisedSubscriberConcurrency(t *testing.T) {
+	client := newTestClient(t)
+	defer client.Close()
+
+	ctx := context.Background()
+	config := DefaultSubscriberConfig()
+	config.Concurrency = 2
+	config.BufferSize = 3
+	config.SlowThreshold = 20 * time.Millisecond
+	sub := client.NewSupervisedSubscriber(config)
+
+	release := make(chan struct{})
+	var active, maxActive int32
+	sub.Handle("test:sub:slow", func(ctx context.Context, msg *redis.Message) error {
+		n := atomic.AddInt32(&active, 1)
+		defer atomic.AddInt32(&active, -1)
+		for {
+			m := atomic.LoadInt32(&maxActive)
+			if n <= m || atomic.CompareAndSwapInt32(&maxActive, m, n) {
+				break
+			}
+		}
+		<-release
+		if msg.Payload == "panic" {
+			panic("handler panic")
+		}
+		return nil
+	})
+	stop := startSubscriber(t, sub)
+	defer stop()
+
+	// 2条处理中，1条等待空闲槽位，3条在缓冲区，其余被丢弃
+	publisher := client.NewPublisher()
+	publisher.Publish(ctx, "test:sub:slow", "panic")
+	for i := 0; i < 9; i++ {
+		publisher.Publish(ctx, "test:sub:slow", "x")
+	}
+	if !waitFor(t, func() bool { return sub.Stats().Received == 10 }) {
+		t.Fatalf("Messages not received, stats %+v", sub.Stats())
+	}
+	stats := sub.Stats()
+	if stats.Dropped < 4 || stats.Pending > 3 {
+		t.Errorf("Expected dropped messages with a full buffer, got %+v", stats)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	close(release)
+	if !waitFor(t, func() bool {
+		s := sub.Stats()
+		return s.Handled+s.Panics+s.Dropped == 10
+	}) {
+		t.Fatalf("Messages not processed, stats %+v", sub.Stats())
+	}
+	stats = sub.Stats()
+	if stats.Panics != 1 || stats.Slow < 1 {
+		t.Errorf("Expected 1 panic and slow handlers, got %+v", stats)
+	}
+	if atomic.LoadInt32(&maxActive) > 2 {
+		t.Errorf("Expected at most 2 concurrent handlers, got %d", maxActive)
+	}
+}
@@ -9,13 +9,14 @@ import (
 
 // Transaction 事务操作
 type Transaction struct {
-	client *redis.Client
+	client redis.UniversalClient
 	tx     redis.Pipeliner
+	prefix keyPrefix
 }
 
 // NewTransaction 创建事务实例
 func (c *Client) NewTransaction() *Transaction {
-	return &Transaction{client: c.client}
+	return &Transaction{client: c.client, prefix: c.prefix}
 }
 
 // Watch 监视键
@@ -24,18 +25,18 @@ func (t *Transaction) Watch(ctx context.Context, keys ...string) error {
 	return t.client.Watch(ctx, func(tx *redis.Tx) error {
 		// 这里可以添加事务逻辑
 		return nil
-	}, keys...)
+	}, t.prefix.keys(keys)...)
 }
 
 // Exec 执行事务
 func (t *Transaction) Exec(ctx context.Context, fn func(*redis.Tx) error, keys []string) error {
-	return t.client.Watch(ctx, fn, keys...)
+	return t.client.Watch(ctx, fn, t.prefix.keys(keys)...)
 }
 
 // ExecWithRetry 带重试的事务执行
 func (t *Transaction) ExecWithRetry(ctx context.Context, fn func(*redis.Tx) error, keys []string, maxRetries int) error {
 	for i := 0; i < maxRetries; i++ {
-		err := t.client.Watch(ctx, fn, keys...)
+		err := t.client.Watch(ctx, fn, t.prefix.keys(keys)...)
 		if err == nil {
 			return nil
 		}
@@ -71,118 +72,118 @@ func (t *Transaction) Discard() {
 // String 操作
 func (t *Transaction) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.StatusCmd {
 	if t.tx == nil {
-		return t.client.Set(ctx, key, value, expiration)
+		return t.client.Set(ctx, t.prefix.key(key), value, expiration)
 	}
-	return t.tx.Set(ctx, key, value, expiration)
+	return t.tx.Set(ctx, t.prefix.key(key), value, expiration)
 }
 
 func (t *Transaction) Get(ctx context.Context, key string) *redis.StringCmd {
 	if t.tx == nil {
-		return t.client.Get(ctx, key)
+		return t.client.Get(ctx, t.prefix.key(key))
 	}
-	return t.tx.Get(ctx, key)
+	return t.tx.Get(ctx, t.prefix.key(key))
 }
 
 func (t *Transaction) Del(ctx context.Context, keys ...string) *redis.IntCmd {
 	if t.tx == nil {
-		return t.client.Del(ctx, keys...)
+		return t.client.Del(ctx, t.prefix.keys(keys)...)
 	}
-	return t.tx.Del(ctx, keys...)
+	return t.tx.Del(ctx, t.prefix.keys(keys)...)
 }
 
 func (t *Transaction) Incr(ctx context.Context, key string) *redis.IntCmd {
 	if t.tx == nil {
-		return t.client.Incr(ctx, key)
+		return t.client.Incr(ctx, t.prefix.key(key))
 	}
-	return t.tx.Incr(ctx, key)
+	return t.tx.Incr(ctx, t.prefix.key(key))
 }
 
 func (t *Transaction) IncrBy(ctx context.Context, key string, value int64) *redis.IntCmd {
 	if t.tx == nil {
-		return t.client.IncrBy(ctx, key, value)
+		return t.client.IncrBy(ctx, t.prefix.key(key), value)
 	}
-	return t.tx.IncrBy(ctx, key, value)
+	return t.tx.IncrBy(ctx, t.prefix.key(key), value)
 }
 
 // Hash 操作
 func (t *Transaction) HSet(ctx context.Context, key string, values ...interface{}) *redis.IntCmd {
 	if t.tx == nil {
-		return t.client.HSet(ctx, key, values...)
+		return t.client.HSet(ctx, t.prefix.key(key), values...)
 	}
-	return t.tx.HSet(ctx, key, values...)
+	return t.tx.HSet(ctx, t.prefix.key(key), values...)
 }
 
 func (t *Transaction) HGet(ctx context.Context, key, field string) *redis.StringCmd {
 	if t.tx == nil {
-		return t.client.HGet(ctx, key, field)
+		return t.client.HGet(ctx, t.prefix.key(key), field)
 	}
-	return t.tx.HGet(ctx, key, field)
+	return t.tx.HGet(ctx, t.prefix.key(key), field)
 }
 
 func (t *Transaction) HDel(ctx context.Context, key string, fields ...string) *redis.IntCmd {
 	if t.tx == nil {
-		return t.client.HDel(ctx, key, fields...)
+		return t.client.HDel(ctx, t.prefix.key(key), fields...)
 	}
-	return t.tx.HDel(ctx, key, fields...)
+	return t.tx.HDel(ctx, t.prefix.key(key), fields...)
 }
 
 // List 操作
 func (t *Transaction) LPush(ctx context.Context, key string, values ...interface{}) *redis.IntCmd {
 	if t.tx == nil {
-		return t.client.LPush(ctx, key, values...)
+		return t.client.LPush(ctx, t.prefix.key(key), values...)
 	}
-	return t.tx.LPush(ctx, key, values...)
+	return t.tx.LPush(ctx, t.prefix.key(key), values...)
 }
 
 func (t *Transaction) RPush(ctx context.Context, key string, values ...interface{}) *redis.IntCmd {
 	if t.tx == nil {
-		return t.client.RPush(ctx, key, values...)
+		return t.client.RPush(ctx, t.prefix.key(key), values...)
 	}
-	return t.tx.RPush(ctx, key, values...)
+	return t.tx.RPush(ctx, t.prefix.key(key), values...)
 }
 
 func (t *Transaction) LPop(ctx context.Context, key string) *redis.StringCmd {
 	if t.tx == nil {
-		return t.client.LPop(ctx, key)
+		return t.client.LPop(ctx, t.prefix.key(key))
 	}
-	return t.tx.LPop(ctx, key)
+	return t.tx.LPop(ctx, t.prefix.key(key))
 }
 
 func (t *Transaction) RPop(ctx context.Context, key string) *redis.StringCmd {
 	if t.tx == nil {
-		return t.client.RPop(ctx, key)
+		return t.client.RPop(ctx, t.prefix.key(key))
 	}
-	return t.tx.RPop(ctx, key)
+	return t.tx.RPop(ctx, t.prefix.key(key))
 }
 
 // Set 操作
 func (t *Transaction) SAdd(ctx context.Context, key string, members ...interface{}) *redis.IntCmd {
 	if t.tx == nil {
-		return t.client.SAdd(ctx, key, members...)
+		return t.client.SAdd(ctx, t.prefix.key(key), members...)
 	}
-	return t.tx.SAdd(ctx, key, members...)
+	return t.tx.SAdd(ctx, t.prefix.key(key), members...)
 }
 
 func (t *Transaction) SRem(ctx context.Context, key string, members ...interface{}) *redis.IntCmd {
 	if t.tx == nil {
-		return t.client.SRem(ctx, key, members...)
+		return t.client.SRem(ctx, t.prefix.key(key), members...)
 	}
-	return t.tx.SRem(ctx, key, members...)
+	return t.tx.SRem(ctx, t.prefix.key(key), members...)
 }
 
 // ZSet 操作
 func (t *Transaction) ZAdd(ctx context.Context, key string, members ...redis.Z) *redis.IntCmd {
 	if t.tx == nil {
-		return t.client.ZAdd(ctx, key, members...)
+		return t.client.ZAdd(ctx, t.prefix.key(key), members...)
 	}
-	return t.tx.ZAdd(ctx, key, members...)
+	return t.tx.ZAdd(ctx, t.prefix.key(key), members...)
 }
 
 func (t *Transaction) ZRem(ctx context.Context, key string, members ...interface{}) *redis.IntCmd {
 	if t.tx == nil {
-		return t.client.ZRem(ctx, key, members...)
+		return t.client.ZRem(ctx, t.prefix.key(key), members...)
 	}
-	return t.tx.ZRem(ctx, key, members...)
+	return t.tx.ZRem(ctx, t.prefix.key(key), members...)
 }
 
 // 通用操作
@@ -209,7 +210,7 @@ func DefaultTransactionOptions() *TransactionOptions {
 
 // WithTransaction 执行事务
 func (c *Client) WithTransaction(ctx context.Context, fn func(*redis.Tx) error, keys ...string) error {
-	return c.client.Watch(ctx, fn, keys...)
+	return c.client.Watch(ctx, fn, c.prefix.keys(keys)...)
 }
 
 // WithTransactionOptions 带选项的事务执行
@@ -222,7 +223,7 @@ func (c *Client) WithTransactionOptions(ctx context.Context, fn func(*redis.Tx)
 	defer cancel()
 
 	for i := 0; i < opts.MaxRetries; i++ {
-		err := c.client.Watch(ctx, fn, keys...)
+		err := c.client.Watch(ctx, fn, c.prefix.keys(keys)...)
 		if err == nil {
 			return nil
 		}
@@ -0,0 +1,206 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/daxiong0327/tool-kit/singleflight"
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	// ErrCacheMiss 缓存中不存在该键
+	ErrCacheMiss = errors.New("redis: cache miss")
+	// ErrNotFound 数据源中不存在该数据，加载函数返回它时会被负缓存
+	ErrNotFound = errors.New("redis: not found")
+)
+
+// 缓存值的首字节标记，用于区分正常值和负缓存
+const (
+	cacheMarkerNotFound byte = 0
+	cacheMarkerValue    byte = 1
+)
+
+// TypedCacheConfig 类型化缓存配置
+type TypedCacheConfig struct {
+	Codec       Codec         `json:"-" yaml:"-"`                       // 编解码器，默认JSON
+	Prefix      string        `json:"prefix" yaml:"prefix"`             // 键前缀
+	NegativeTTL time.Duration `json:"negative_ttl" yaml:"negative_ttl"` // 数据不存在时的缓存时间，0表示不缓存
+	Jitter      float64       `json:"jitter" yaml:"jitter"`             // TTL随机抖动比例，0.1表示±10%
+}
+
+// DefaultTypedCacheConfig 默认类型化缓存配置
+func DefaultTypedCacheConfig() *TypedCacheConfig {
+	return &TypedCacheConfig{
+		Codec:       JSONCodec,
+		Prefix:      "cache:",
+		NegativeTTL: time.Minute,
+		Jitter:      0.1,
+	}
+}
+
+// TypedCache 类型化的旁路缓存
+type TypedCache[T any] struct {
+	client redis.UniversalClient
+	config *TypedCacheConfig
+	group  *singleflight.Group
+}
+
+// NewTypedCache 创建类型化缓存，config为nil时使用默认配置
+func NewTypedCache[T any](client *Client, config *TypedCacheConfig) *TypedCache[T] {
+	if config == nil {
+		config = DefaultTypedCacheConfig()
+	}
+	cfg := *config
+	if cfg.Codec == nil {
+		cfg.Codec = JSONCodec
+	}
+	if cfg.Jitter < 0 {
+		cfg.Jitter = 0
+	}
+	if cfg.Jitter > 1 {
+		cfg.Jitter = 1
+	}
+	cfg.Prefix = client.prefix.key(cfg.Prefix)
+	return &TypedCache[T]{client: client.client, config: &cfg, group: singleflight.NewGroup()}
+}
+
+// Get 获取缓存值，不存在时返回 ErrCacheMiss，命中负缓存时返回 ErrNotFound
+func (c *TypedCache[T]) Get(ctx context.Context, key string) (T, error) {
+	data, err := c.client.Get(ctx, c.config.Prefix+key).Bytes()
+	return c.decode(key, data, err)
+}
+
+// getWithTTL 与 Get 相同，同时返回键在Redis中的剩余时间，不过期的键返回负数
+func (c *TypedCache[T]) getWithTTL(ctx context.Context, key string) (T, time.Duration, error) {
+	var get *redis.StringCmd
+	var pttl *redis.DurationCmd
+	_, err := c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		get = pipe.Get(ctx, c.config.Prefix+key)
+		pttl = pipe.PTTL(ctx, c.config.Prefix+key)
+		return nil
+	})
+	if err != nil && err != redis.Nil {
+		var value T
+		return value, 0, err
+	}
+	data, err := get.Bytes()
+	value, err := c.decode(key, data, err)
+	return value, pttl.Val(), err
+}
+
+// decode 解析GET的结果
+func (c *TypedCache[T]) decode(key string, data []byte, err error) (T, error) {
+	var value T
+	if err == redis.Nil {
+		return value, ErrCacheMiss
+	}
+	if err != nil {
+		return value, err
+	}
+	if len(data) == 0 {
+		return value, fmt.Errorf("invalid cache entry for key %s", key)
+	}
+	if data[0] == cacheMarkerNotFound {
+		return value, ErrNotFound
+	}
+	if err := c.config.Codec.Unmarshal(data[1:], &value); err != nil {
+		return value, fmt.Errorf("failed to decode cache entry for key %s: %w", key, err)
+	}
+	return value, nil
+}
+
+// Set 设置缓存值，ttl为0表示不过期，ttl会加入随机抖动
+func (c *TypedCache[T]) Set(ctx context.Context, key string, value T, ttl time.Duration) error {
+	data, err := c.config.Codec.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache entry for key %s: %w", key, err)
+	}
+	entry := make([]byte, 0, len(data)+1)
+	entry = append(entry, cacheMarkerValue)
+	entry = append(entry, data...)
+	return c.client.Set(ctx, c.config.Prefix+key, entry, c.jitter(ttl)).Err()
+}
+
+// SetNotFound 写入负缓存，在NegativeTTL内 Get 返回 ErrNotFound
+func (c *TypedCache[T]) SetNotFound(ctx context.Context, key string) error {
+	if c.config.NegativeTTL <= 0 {
+		return nil
+	}
+	return c.client.Set(ctx, c.config.Prefix+key, []byte{cacheMarkerNotFound}, c.jitter(c.config.NegativeTTL)).Err()
+}
+
+// Delete 删除缓存
+func (c *TypedCache[T]) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = c.config.Prefix + key
+	}
+	return c.client.Del(ctx, prefixed...).Err()
+}
+
+// GetOrLoad 获取缓存值，未命中时调用loader加载并写入缓存。
+// 同一进程内相同键的并发加载只执行一次；loader返回 ErrNotFound 时写入负缓存；
+// Redis不可用时直接使用loader的结果
+func (c *TypedCache[T]) GetOrLoad(ctx context.Context, key string, ttl time.Duration, loader func(ctx context.Context) (T, error)) (T, error) {
+	value, err := c.Get(ctx, key)
+	if err == nil || errors.Is(err, ErrNotFound) {
+		return value, err
+	}
+
+	ch := c.group.DoChan(key, func() (interface{}, error) {
+		return c.load(ctx, key, ttl, loader)
+	})
+	select {
+	case result := <-ch:
+		if result.Err != nil {
+			var zero T
+			return zero, result.Err
+		}
+		return result.Val.(T), nil
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+// load 调用loader并写入缓存
+func (c *TypedCache[T]) load(ctx context.Context, key string, ttl time.Duration, loader func(ctx context.Context) (T, error)) (T, error) {
+	// 合并的加载不应因发起者取消而失败，但保留其截止时间
+	loadCtx := context.WithoutCancel(ctx)
+	if deadline, ok := ctx.Deadline(); ok {
+		var cancel context.CancelFunc
+		loadCtx, cancel = context.WithDeadline(loadCtx, deadline)
+		defer cancel()
+	}
+
+	value, err := loader(loadCtx)
+	if errors.Is(err, ErrNotFound) {
+		c.SetNotFound(loadCtx, key)
+		return value, err
+	}
+	if err != nil {
+		return value, err
+	}
+	// 写入失败不影响本次结果，下次请求会重新加载
+	c.Set(loadCtx, key, value, ttl)
+	return value, nil
+}
+
+// jitter 为ttl加入随机抖动，避免大量键同时过期
+func (c *TypedCache[T]) jitter(ttl time.Duration) time.Duration {
+	if ttl <= 0 || c.config.Jitter == 0 {
+		return ttl
+	}
+	delta := time.Duration(float64(ttl) * c.config.Jitter * (rand.Float64()*2 - 1))
+	if ttl+delta < time.Millisecond {
+		return time.Millisecond
+	}
+	return ttl + delta
+}
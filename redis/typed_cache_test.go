@@ -0,0 +1,145 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type cachedUser struct {
+	ID    int
+	Name  string
+	Roles []string
+}
+
+func TestTypedCacheCodecs(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+	user := cachedUser{ID: 1, Name: "alice", Roles: []string{"admin"}}
+
+	for name, codec := range map[string]Codec{"json": JSONCodec, "msgpack": MsgpackCodec, "gob": GobCodec} {
+		t.Run(name, func(t *testing.T) {
+			cache := NewTypedCache[cachedUser](client, &TypedCacheConfig{Codec: codec, Prefix: "test:typed:" + name + ":"})
+			defer cache.Delete(ctx, "user:1")
+
+			if err := cache.Set(ctx, "user:1", user, time.Minute); err != nil {
+				t.Fatalf("Set failed: %v", err)
+			}
+			got, err := cache.Get(ctx, "user:1")
+			if err != nil {
+				t.Fatalf("Get failed: %v", err)
+			}
+			if got.ID != user.ID || got.Name != user.Name || len(got.Roles) != 1 || got.Roles[0] != "admin" {
+				t.Errorf("Expected %+v, got %+v", user, got)
+			}
+
+			cache.Delete(ctx, "user:1")
+			if _, err := cache.Get(ctx, "user:1"); err != ErrCacheMiss {
+				t.Errorf("Expected ErrCacheMiss, got %v", err)
+			}
+		})
+	}
+}
+
+func TestTypedCacheGetOrLoad(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+	cache := NewTypedCache[cachedUser](client, &TypedCacheConfig{Prefix: "test:typed:load:"})
+	cache.Delete(ctx, "user:2")
+	defer cache.Delete(ctx, "user:2")
+
+	// 并发未命中只加载一次
+	var loads int32
+	loader := func(ctx context.Context) (cachedUser, error) {
+		atomic.AddInt32(&loads, 1)
+		time.Sleep(50 * time.Millisecond)
+		return cachedUser{ID: 2, Name: "bob"}, nil
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			user, err := cache.GetOrLoad(ctx, "user:2", time.Minute, loader)
+			if err != nil || user.Name != "bob" {
+				t.Errorf("GetOrLoad returned %+v, %v", user, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if loads != 1 {
+		t.Errorf("Expected 1 load, got %d", loads)
+	}
+
+	// 后续请求命中缓存
+	if _, err := cache.GetOrLoad(ctx, "user:2", time.Minute, loader); err != nil {
+		t.Fatalf("GetOrLoad failed: %v", err)
+	}
+	if loads != 1 {
+		t.Errorf("Expected cache hit, got %d loads", loads)
+	}
+
+	// 加载错误不写入缓存
+	loadErr := errors.New("database down")
+	_, err := cache.GetOrLoad(ctx, "user:error", time.Minute, func(ctx context.Context) (cachedUser, error) {
+		return cachedUser{}, loadErr
+	})
+	if err != loadErr {
+		t.Errorf("Expected loader error, got %v", err)
+	}
+	if _, err := cache.Get(ctx, "user:error"); err != ErrCacheMiss {
+		t.Errorf("Expected error not to be cached, got %v", err)
+	}
+}
+
+func TestTypedCacheNegative(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+	cache := NewTypedCache[cachedUser](client, &TypedCacheConfig{Prefix: "test:typed:negative:", NegativeTTL: 200 * time.Millisecond})
+	cache.Delete(ctx, "missing")
+
+	var loads int32
+	loader := func(ctx context.Context) (cachedUser, error) {
+		atomic.AddInt32(&loads, 1)
+		return cachedUser{}, ErrNotFound
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := cache.GetOrLoad(ctx, "missing", time.Minute, loader); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("Expected ErrNotFound, got %v", err)
+		}
+	}
+	if loads != 1 {
+		t.Errorf("Expected not-found to be cached, got %d loads", loads)
+	}
+
+	// 负缓存过期后重新加载
+	time.Sleep(300 * time.Millisecond)
+	cache.GetOrLoad(ctx, "missing", time.Minute, loader)
+	if loads != 2 {
+		t.Errorf("Expected reload after negative TTL, got %d loads", loads)
+	}
+}
+
+func TestTypedCacheJitter(t *testing.T) {
+	client := newTestClient(t)
+	cache := NewTypedCache[int](client, &TypedCacheConfig{Jitter: 0.2})
+
+	ttl := 10 * time.Second
+	seen := make(map[time.Duration]bool)
+	for i := 0; i < 100; i++ {
+		got := cache.jitter(ttl)
+		if got < 8*time.Second || got > 12*time.Second {
+			t.Fatalf("Jittered TTL %v out of range", got)
+		}
+		seen[got] = true
+	}
+	if len(seen) < 2 {
+		t.Error("Expected jitter to vary TTL")
+	}
+	if cache.jitter(0) != 0 {
+		t.Error("Expected zero TTL to stay unchanged")
+	}
+}